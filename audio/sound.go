@@ -48,7 +48,8 @@ type SoundSettings struct {
 	sound      rl.Sound
 }
 
-// SoundManager manages all game audio
+// SoundManager manages all game audio. A nil *SoundManager is valid and
+// stays silent, which is how headless simulations run without an audio device.
 type SoundManager struct {
 	sounds     map[string]SoundSettings
 	music      map[string]rl.Music
@@ -91,12 +92,14 @@ func (sm *SoundManager) loadSounds() {
 	sm.LoadSound("biwa", "assets/audio/sfx/biwa.mp3", 0.7)
 	sm.LoadSound("key", "assets/audio/sfx/key.mp3", 0.7)
 	sm.LoadSound("step", "assets/audio/sfx/step.wav", 0.7)
+	sm.LoadSound("bow", "assets/audio/sfx/bow.wav", 0.5)
+	sm.LoadSound("bomb", "assets/audio/sfx/bomb.wav", 0.8)
 
 	// Music tracks
 	sm.LoadMusic("title_theme", "assets/audio/music/loopable.mp3")
 	sm.LoadMusic("dungeon_theme", "assets/audio/music/gameplay.mp3")
 	sm.LoadMusic("outro", "assets/audio/music/loopable.mp3")
-	// sm.LoadMusic("boss_theme", "assets/audio/music/bg.mp3")
+	sm.LoadMusic("boss_theme", "assets/audio/music/daddou.mp3")
 }
 
 // LoadSound loads a single sound effect
@@ -177,6 +180,10 @@ func (sm *SoundManager) processSoundRequests() {
 
 // RequestSound sends a sound request through the channel
 func (sm *SoundManager) RequestSound(name string, volume, pitch float32) {
+	if sm == nil {
+		return
+	}
+
 	println("requested sound + ", name)
 	sm.soundChan <- SoundRequest{
 		Name:   name,
@@ -188,6 +195,10 @@ func (sm *SoundManager) RequestSound(name string, volume, pitch float32) {
 
 // RequestMusic sends a music request through the channel
 func (sm *SoundManager) RequestMusic(name string, stopCurrent bool) {
+	if sm == nil {
+		return
+	}
+
 	sm.soundChan <- SoundRequest{
 		Name:      name,
 		Type:      MUSIC,
@@ -199,6 +210,10 @@ func (sm *SoundManager) RequestMusic(name string, stopCurrent bool) {
 
 // SetVolume sets the volume for a specific sound type
 func (sm *SoundManager) SetVolume(sType SoundType, volume float32) {
+	if sm == nil {
+		return
+	}
+
 	sm.mutex.Lock()
 	sm.volumes[sType] = volume
 	sm.mutex.Unlock()
@@ -213,6 +228,10 @@ func (sm *SoundManager) SetVolume(sType SoundType, volume float32) {
 
 // SetMasterVolume sets the master volume
 func (sm *SoundManager) SetMasterVolume(volume float32) {
+	if sm == nil {
+		return
+	}
+
 	sm.mutex.Lock()
 	sm.masterVol = volume
 	sm.mutex.Unlock()
@@ -227,6 +246,10 @@ func (sm *SoundManager) SetMasterVolume(volume float32) {
 
 // Unload cleans up all audio resources
 func (sm *SoundManager) Unload() {
+	if sm == nil {
+		return
+	}
+
 	sm.isRunning = false
 	time.Sleep(time.Millisecond * 100) // Give time for goroutine to finish
 
@@ -254,6 +277,10 @@ func (sm *SoundManager) Unload() {
 }
 
 func (sm *SoundManager) GetCurrentMusic() string {
+	if sm == nil {
+		return ""
+	}
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 	return sm.currentBGM
@@ -0,0 +1,70 @@
+package combat
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	NUMBER_LIFETIME = 0.8 // Seconds a damage number floats before fading out
+	NUMBER_RISE     = 20  // Pixels a damage number rises over its lifetime
+)
+
+// Number is the damage of a hit floating up over its target.
+type Number struct {
+	Position rl.Vector2
+	Amount   int
+	Crit     bool
+	OnPlayer bool // The player got hurt, drawn in another color
+	age      float32
+}
+
+// Numbers are the damage numbers on screen.
+type Numbers struct {
+	list []*Number
+}
+
+// Add shows a hit of amount over pos.
+func (n *Numbers) Add(pos rl.Vector2, amount int, crit, onPlayer bool) {
+	n.list = append(n.list, &Number{Position: pos, Amount: amount, Crit: crit, OnPlayer: onPlayer})
+}
+
+// Clear removes every number, when the layout changes under them.
+func (n *Numbers) Clear() {
+	n.list = nil
+}
+
+// Update ages the numbers, dropping those that faded out.
+func (n *Numbers) Update(deltaTime float32) {
+	live := n.list[:0]
+	for _, number := range n.list {
+		if number.age += deltaTime; number.age < NUMBER_LIFETIME {
+			live = append(live, number)
+		}
+	}
+	n.list = live
+}
+
+// Render draws the numbers rising and fading, critical hits bigger.
+func (n *Numbers) Render() {
+	for _, number := range n.list {
+		progress := number.age / NUMBER_LIFETIME
+		size := int32(8)
+		color := rl.White
+		text := fmt.Sprint(number.Amount)
+		switch {
+		case number.OnPlayer:
+			color = rl.Red
+		case number.Crit:
+			size = 12
+			color = rl.Gold
+			text += "!"
+		}
+
+		x := int32(number.Position.X) - rl.MeasureText(text, size)/2
+		y := int32(number.Position.Y - NUMBER_RISE*progress)
+		rl.DrawText(text, x+1, y+1, size, rl.ColorAlpha(rl.Black, 1-progress))
+		rl.DrawText(text, x, y, size, rl.ColorAlpha(color, 1-progress))
+	}
+}
@@ -0,0 +1,100 @@
+// Package combat holds the rules of fighting shared by the player and the
+// enemies: how much a hit deals, how long attackers wait and targets are
+// spared, and how far a hit pushes. None of it draws anything.
+package combat
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	PLAYER_INVULNERABILITY = 0.8 // Seconds the player can't be hurt again after a hit
+	ENEMY_INVULNERABILITY  = 0.1 // Seconds an enemy can't be hurt again, so one blow lands once
+	DEFAULT_CRIT           = 2.0 // Damage multiplier of critical hits when none is set
+	PUSH_STEP              = 2.0 // Pixels a push moves at a time, well under a tile
+)
+
+// Timer counts seconds down to zero. Attack cooldowns and invulnerability
+// windows are timers.
+type Timer float32
+
+// Running reports whether the timer hasn't run out yet.
+func (t Timer) Running() bool {
+	return t > 0
+}
+
+// Start sets the timer to run for seconds.
+func (t *Timer) Start(seconds float32) {
+	*t = Timer(seconds)
+}
+
+// Update counts the timer down by deltaTime, stopping at zero.
+func (t *Timer) Update(deltaTime float32) {
+	if *t -= Timer(deltaTime); *t < 0 {
+		*t = 0
+	}
+}
+
+// Damage returns what a hit of base damage deals and whether it's a
+// critical hit, roll being a random number in [0, 1) drawn by the caller.
+// Hits crit when roll is under chance and deal base times multiplier,
+// rounded, never less than base.
+func Damage(base int, chance, multiplier, roll float32) (int, bool) {
+	if roll >= chance {
+		return base, false
+	}
+	if multiplier <= 0 {
+		multiplier = DEFAULT_CRIT
+	}
+	crit := int(math.Round(float64(float32(base) * multiplier)))
+	if crit < base {
+		crit = base
+	}
+	return crit, true
+}
+
+// Hurt takes amount off health, health never going below zero. Nothing is
+// taken while the target is invulnerable, otherwise invulnerable starts
+// running for window seconds. It reports whether the hit landed.
+func Hurt(health *int, amount int, invulnerable *Timer, window float32) bool {
+	if invulnerable.Running() || amount <= 0 {
+		return false
+	}
+	*health -= amount
+	if *health < 0 {
+		*health = 0
+	}
+	invulnerable.Start(window)
+	return true
+}
+
+// Push moves pos up to distance pixels straight away from from, a step at
+// a time so it can't skip over a wall. When fits refuses a step, pos slides
+// along whichever axis still fits, and the push stops where neither does.
+func Push(pos, from rl.Vector2, distance float32, fits func(rl.Vector2) bool) rl.Vector2 {
+	dx, dy := float64(pos.X-from.X), float64(pos.Y-from.Y)
+	length := math.Hypot(dx, dy)
+	if length == 0 || distance <= 0 {
+		return pos
+	}
+	dx, dy = dx/length, dy/length
+
+	for moved := float32(0); moved < distance; moved += PUSH_STEP {
+		step := float32(math.Min(PUSH_STEP, float64(distance-moved)))
+		x, y := pos.X+float32(dx)*step, pos.Y+float32(dy)*step
+
+		switch {
+		case fits(rl.NewVector2(x, y)):
+			pos = rl.NewVector2(x, y)
+		case fits(rl.NewVector2(x, pos.Y)):
+			pos.X = x
+		case fits(rl.NewVector2(pos.X, y)):
+			pos.Y = y
+		default:
+			return pos
+		}
+	}
+	return pos
+}
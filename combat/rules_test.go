@@ -0,0 +1,142 @@
+package combat
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestDamage(t *testing.T) {
+	tests := []struct {
+		name                     string
+		base                     int
+		chance, multiplier, roll float32
+		want                     int
+		crit                     bool
+	}{
+		{"roll over the chance", 3, 0.25, 2, 0.5, 3, false},
+		{"roll on the chance", 3, 0.25, 2, 0.25, 3, false},
+		{"roll under the chance", 3, 0.25, 2, 0.1, 6, true},
+		{"no chance never crits", 3, 0, 2, 0, 3, false},
+		{"unset multiplier", 3, 1, 0, 0.5, 6, true},
+		{"multiplier rounds", 3, 1, 1.5, 0.5, 5, true},
+		{"multiplier never lowers the hit", 3, 1, 0.5, 0.5, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, crit := Damage(tt.base, tt.chance, tt.multiplier, tt.roll)
+			if got != tt.want || crit != tt.crit {
+				t.Errorf("Damage(%d, %v, %v, %v) = %d, %t, want %d, %t",
+					tt.base, tt.chance, tt.multiplier, tt.roll, got, crit, tt.want, tt.crit)
+			}
+		})
+	}
+}
+
+func TestHurt(t *testing.T) {
+	tests := []struct {
+		name         string
+		health       int
+		amount       int
+		invulnerable Timer
+		wantHealth   int
+		landed       bool
+	}{
+		{"hit lands", 5, 2, 0, 3, true},
+		{"health stops at zero", 1, 3, 0, 0, true},
+		{"invulnerable target", 5, 2, 0.3, 5, false},
+		{"nothing to take", 5, 0, 0, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health, invulnerable := tt.health, tt.invulnerable
+			landed := Hurt(&health, tt.amount, &invulnerable, PLAYER_INVULNERABILITY)
+			if landed != tt.landed || health != tt.wantHealth {
+				t.Fatalf("landed %t with %d health, want %t with %d", landed, health, tt.landed, tt.wantHealth)
+			}
+			if landed && invulnerable != PLAYER_INVULNERABILITY {
+				t.Errorf("invulnerable for %v after the hit, want %v", invulnerable, PLAYER_INVULNERABILITY)
+			}
+			if !landed && invulnerable != tt.invulnerable {
+				t.Errorf("a hit that didn't land changed invulnerability to %v", invulnerable)
+			}
+		})
+	}
+}
+
+func TestInvulnerabilityWindow(t *testing.T) {
+	health, invulnerable := 10, Timer(0)
+	Hurt(&health, 1, &invulnerable, ENEMY_INVULNERABILITY)
+
+	// Hits during the window are ignored, the first one after it lands
+	invulnerable.Update(ENEMY_INVULNERABILITY / 2)
+	if Hurt(&health, 1, &invulnerable, ENEMY_INVULNERABILITY) {
+		t.Error("hit landed during the invulnerability window")
+	}
+	invulnerable.Update(ENEMY_INVULNERABILITY / 2)
+	if !Hurt(&health, 1, &invulnerable, ENEMY_INVULNERABILITY) {
+		t.Error("hit didn't land once the window ran out")
+	}
+	if health != 8 {
+		t.Errorf("health = %d, want 8", health)
+	}
+}
+
+func TestTimer(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   float32
+		elapsed []float32
+		want    Timer
+	}{
+		{"still running", 1, []float32{0.25, 0.25}, 0.5},
+		{"runs out exactly", 0.5, []float32{0.25, 0.25}, 0},
+		{"stops at zero", 0.5, []float32{2}, 0},
+		{"never started", 0, []float32{0.1}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timer Timer
+			timer.Start(tt.start)
+			for _, dt := range tt.elapsed {
+				timer.Update(dt)
+			}
+			if timer != tt.want || timer.Running() != (tt.want > 0) {
+				t.Errorf("timer = %v (running %t), want %v", timer, timer.Running(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPush(t *testing.T) {
+	// A wall fills everything right of x = 10
+	wall := func(pos rl.Vector2) bool { return pos.X <= 10 }
+
+	tests := []struct {
+		name      string
+		pos, from rl.Vector2
+		distance  float32
+		fits      func(rl.Vector2) bool
+		want      rl.Vector2
+	}{
+		{"open floor", rl.NewVector2(0, 0), rl.NewVector2(-1, 0), 7, func(rl.Vector2) bool { return true }, rl.NewVector2(7, 0)},
+		{"stops against a wall", rl.NewVector2(0, 0), rl.NewVector2(-1, 0), 20, wall, rl.NewVector2(10, 0)},
+		{"already against a wall", rl.NewVector2(10, 0), rl.NewVector2(0, 0), 20, wall, rl.NewVector2(10, 0)},
+		{"slides along a wall", rl.NewVector2(10, 0), rl.NewVector2(7, -4), 10, wall, rl.NewVector2(10, 8)},
+		{"boxed in", rl.NewVector2(0, 0), rl.NewVector2(-1, -1), 10, func(rl.Vector2) bool { return false }, rl.NewVector2(0, 0)},
+		{"pushed from its own position", rl.NewVector2(3, 3), rl.NewVector2(3, 3), 10, wall, rl.NewVector2(3, 3)},
+		{"no distance", rl.NewVector2(0, 0), rl.NewVector2(-1, 0), 0, wall, rl.NewVector2(0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Push(tt.pos, tt.from, tt.distance, tt.fits)
+			if rl.Vector2Distance(got, tt.want) > 1e-3 {
+				t.Errorf("Push(%v, %v, %v) = %v, want %v", tt.pos, tt.from, tt.distance, got, tt.want)
+			}
+		})
+	}
+}
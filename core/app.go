@@ -0,0 +1,136 @@
+package core
+
+import (
+	"crydes/audio"
+	"crydes/core/screens"
+	"crydes/helpers"
+	"crydes/input"
+	"crydes/replay"
+	"crydes/save"
+	"crydes/world"
+	"fmt"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// App drives the screen stack once per frame. Every screen is built by a
+// factory registered here, so adding one never touches the frame loop.
+type App struct {
+	screens      *screens.ScreenManager
+	soundManager *audio.SoundManager
+	input        *input.Input // Polled once per frame, before the screens update
+
+	width, height int
+
+	game *Game // The run currently on the stack, saved from the pause menu
+
+	config world.Config // How the dungeons of every run are laid out
+
+	// Seeding
+	seed     int64      // Seed of the first run
+	runSeeds *rand.Rand // Derives the seed of every run started after it
+	runs     int
+
+	previousTime float64
+}
+
+// NewApp registers every screen and opens the title screen. The first run
+// started from it is generated from seed, and every run is laid out as the
+// config says.
+func NewApp(soundManager *audio.SoundManager, in *input.Input, width, height int, seed int64, config world.Config) *App {
+	rl.SetTargetFPS(60)
+
+	a := &App{
+		screens:      screens.NewScreenManager(),
+		soundManager: soundManager,
+		input:        in,
+		width:        width,
+		height:       height,
+		config:       config,
+		seed:         seed,
+		runSeeds:     rand.New(rand.NewSource(seed)),
+		previousTime: rl.GetTime(),
+	}
+
+	a.screens.Register(screens.TITLE, func() screens.Screen {
+		return screens.NewTitleScreen(soundManager)
+	})
+	a.screens.Register(screens.GAME, func() screens.Screen {
+		a.game = NewGame(soundManager, in, a.width, a.height, a.nextRunSeed(), a.config)
+		return a.game
+	})
+	a.screens.Register(screens.CONTINUE, func() screens.Screen {
+		g, err := LoadGame(soundManager, in, a.width, a.height, save.DEFAULT_PATH, a.config)
+		if err != nil {
+			// Back to the title, which says why instead of starting over
+			ts := screens.NewTitleScreen(soundManager)
+			ts.ShowSaveError(err)
+			return ts
+		}
+		a.game = g
+		return a.game
+	})
+	a.screens.Register(screens.PAUSE, func() screens.Screen {
+		ps := screens.NewPauseScreen(soundManager, in)
+		ps.SetOnSave(func() {
+			if err := a.game.saveRun(save.DEFAULT_PATH); err != nil {
+				helpers.DEBUG("Save failed", err)
+			}
+		})
+		return ps
+	})
+	a.screens.Register(screens.PERKS, func() screens.Screen {
+		return screens.NewPerkScreen(soundManager, in, a.game.sim.Player, a.game.ChoosePerk)
+	})
+	a.screens.Register(screens.GAME_OVER, func() screens.Screen {
+		s := a.game.sim
+		summary := fmt.Sprintf("Floor %d, level %d, %d enemies killed", s.Depth(), s.Player.Level, s.Enemies.KilledCount)
+		return screens.NewGameOverScreen(soundManager, summary)
+	})
+	a.screens.Register(screens.VICTORY, func() screens.Screen {
+		return screens.NewVictoryScreen(soundManager)
+	})
+	a.screens.Register(screens.OUTRO, func() screens.Screen {
+		return screens.NewOutroScreen(soundManager)
+	})
+
+	a.screens.Push(screens.NewTitleScreen(soundManager))
+	return a
+}
+
+// WatchReplay opens the viewer on the recorded run r in place of the title
+// screen.
+func (a *App) WatchReplay(r *replay.Replay) {
+	a.game = WatchReplay(a.soundManager, a.input, a.width, a.height, r)
+	a.screens.Replace(a.game)
+}
+
+// nextRunSeed returns the seed for a new run, the first one uses the seed
+// the app was started with.
+func (a *App) nextRunSeed() int64 {
+	a.runs++
+	if a.runs == 1 {
+		return a.seed
+	}
+	return a.runSeeds.Int63()
+}
+
+// Frame updates and draws the screen stack once.
+func (a *App) Frame() {
+	deltaTime := float32(rl.GetTime() - a.previousTime)
+	a.previousTime = rl.GetTime()
+
+	a.input.Poll()
+	a.screens.Update(deltaTime)
+
+	rl.BeginDrawing()
+	rl.ClearBackground(helpers.VOID_COLOR) // rgb(88, 68, 34)
+	a.screens.Render()
+	rl.EndDrawing()
+}
+
+// ShouldQuit reports whether a screen asked to close the game.
+func (a *App) ShouldQuit() bool {
+	return a.screens.ShouldQuit()
+}
@@ -3,12 +3,15 @@ package core
 import (
 	"crydes/audio"
 	"crydes/core/screens"
+	"crydes/defs"
 	"crydes/effects"
-	"crydes/enemies"
+	"crydes/events"
 	"crydes/helpers"
-	"crydes/player"
+	"crydes/input"
+	"crydes/replay"
+	"crydes/save"
+	"crydes/sim"
 	"crydes/world"
-	"time"
 
 	"fmt"
 
@@ -27,6 +30,10 @@ const (
 
 //! END DEVELOPMENT
 
+// MAX_FRAME_TIME is the longest frame simulated, a stall of the window
+// doesn't fast-forward the run.
+const MAX_FRAME_TIME = 0.25
+
 type ShiftText struct {
 	message        string
 	startTime      float32
@@ -35,13 +42,11 @@ type ShiftText struct {
 }
 
 type Game struct {
-	player    *player.Player
-	world     *world.World
+	sim       *sim.Simulation // Gameplay state, rendered by the game
 	lightning *effects.RetroLightingEffect
 
 	soundManager *audio.SoundManager // Reference to the sound manager
-
-	enemies *enemies.EnemiesManager
+	input        *input.Input
 
 	camera        rl.Camera2D
 	width, height int
@@ -50,229 +55,203 @@ type Game struct {
 	flags int
 	//! END DEVELOPMENT
 
-	pauseScreen   *screens.PauseScreen
-	titleScreen   *screens.TitleScreen
-	outroScreen   *screens.OutroScreen
-	victoryScreen *screens.VictoryScreen
-	isPaused      bool
-	showTitle     bool
-	ShowVictory   bool
-	showOutro     bool
-
-	minimap             *minimap.Minimap
-	collectiblesManager *world.CollectibleManager
-
-	// Dungeon shifting
-	shiftTimer       float32
-	isShifting       bool
-	shiftDelay       float32
-	shiftText        string
-	shiftTextTimer   float32
-	fadeAlpha        float32
-	shiftSoundPlayed bool
+	minimap *minimap.Minimap
 
+	// Dungeon shifting, scheduled by the simulation
+	shiftText      string
 	shiftTexts     []ShiftText
 	currentTextIdx int
+	responseTimer  float32 // Seconds until the player answers the dungeon
 
-	keyCount int
-}
+	// Fixed timestep
+	accumulator float32         // Frame time not simulated yet
+	tickState   input.State     // Actions of the last simulated tick
+	latched     input.ActionSet // Presses seen on frames that ran no tick
 
-// NewGame initializes a new game instance
-func NewGame(soundManager *audio.SoundManager, width, height int) *Game {
+	recording *replay.Replay // Every tick of the run, written when it ends
+	viewer    *ReplayViewer  // Set when watching a replay instead of playing
+}
 
-	w := world.NewWorld()
-	collectibleManager := world.NewCollectibleManager()
+// NewGame initializes a new game instance. The first dungeon is generated
+// from seed and every later shift derives its seed from it, all of them
+// laid out as the config says.
+func NewGame(soundManager *audio.SoundManager, in *input.Input, width, height int, seed int64, config world.Config) *Game {
+	g := &Game{
+		soundManager: soundManager,
+		input:        in,
+		width:        width,
+		height:       height,
+		flags:        RENDER_LIGHTING,
+		shiftText:    "The dungeon shifts beneath your feet...",
+	}
 
-	x, y := w.PlayerSpawn()
-	p := player.NewPlayer(x, y, w.Map, soundManager, collectibleManager.GetEffectsChan())
-	collectibleManager.SetPlayerPosition(&p.Position)
+	g.newRun(seed, config)
 
-	em := enemies.NewEnemiesManager(x, y, w.Map, p.AttackChan, w.Map.GetRoomsRects(), soundManager)
-	em.SpawnEnemies()
+	g.Init()
+	return g
+}
 
-	rle := effects.NewRetroLightingEffect(
-		int32(helpers.MAP_WIDTH*helpers.TILE_SIZE), int32(helpers.MAP_HEIGHT*helpers.TILE_SIZE), 50, 2, p,
-	)
+// LoadGame resumes the run saved at path, laying out the dungeons it
+// generates from then on as the config says.
+func LoadGame(soundManager *audio.SoundManager, in *input.Input, width, height int, path string, config world.Config) (*Game, error) {
+	g := &Game{
+		soundManager: soundManager,
+		input:        in,
+		width:        width,
+		height:       height,
+		flags:        RENDER_LIGHTING,
+		shiftText:    "The dungeon shifts beneath your feet...",
+	}
 
-	collectibleManager.ScatterCollectibles(w.Map.GetRoomsRects(), w.Map)
-	collectibleManager.AddItem(1, world.HealthPotion, x+20, y+20)
-	collectibleManager.AddItem(2, world.SpeedPotion, x+30, y+30)
-	collectibleManager.AddItem(3, world.Poison, x-30, y+30)
-
-	rle.SetUpPropsLightning(w.PropsManager.GetProps())
-
-	mm := minimap.NewMinimap(w.Map)
-
-	return &Game{
-		player:              p,
-		world:               w,
-		soundManager:        soundManager,
-		enemies:             em,
-		width:               width,
-		height:              height,
-		lightning:           rle,
-		flags:               RENDER_LIGHTING,
-		pauseScreen:         screens.NewPauseScreen(soundManager),
-		titleScreen:         screens.NewTitleScreen(soundManager),
-		outroScreen:         screens.NewOutroScreen(soundManager),
-		victoryScreen:       screens.NewVictoryScreen(soundManager),
-		isPaused:            false,
-		ShowVictory:         false,
-		showTitle:           true,
-		showOutro:           false,
-		minimap:             mm,
-		collectiblesManager: collectibleManager,
-		shiftTimer:          0,
-		isShifting:          false,
-		// shiftDelay:          float32(4 + rand.Intn(41)), // Random value between 40 and 80 seconds
-		shiftDelay:     helpers.GetShiftDelay(), // Random value between 40 and 80 seconds
-		shiftText:      "The dungeon shifts beneath your feet...",
-		shiftTextTimer: 0,
-		fadeAlpha:      0,
-		keyCount:       0,
+	if err := g.loadRun(path, config); err != nil {
+		return nil, err
 	}
 
+	g.Init()
+	return g, nil
 }
 
-func (g *Game) Run() {
-	rl.SetTargetFPS(60)
-	previousTime := rl.GetTime()
+// newRun starts a fresh run from seed and attaches the renderers to it.
+func (g *Game) newRun(seed int64, config world.Config) {
+	g.sim = sim.New(seed, config, g.soundManager, false)
+	g.recording = replay.New(seed, config)
+	g.attachRenderers()
+}
 
-	// Initialize the camera with correct offset for centering
-	g.camera = rl.Camera2D{
-		Offset:   rl.Vector2{X: float32(g.width) / 2, Y: float32(g.height) / 2},
-		Target:   rl.Vector2{X: float32(g.player.Position.X), Y: float32(g.player.Position.Y)},
-		Rotation: 0.0,
-		Zoom:     4.5,
+// WatchReplay builds a game that plays the recorded run r instead of
+// reading the player's input.
+func WatchReplay(soundManager *audio.SoundManager, in *input.Input, width, height int, r *replay.Replay) *Game {
+	g := &Game{
+		soundManager: soundManager,
+		input:        in,
+		width:        width,
+		height:       height,
+		flags:        RENDER_LIGHTING,
+		shiftText:    "The dungeon shifts beneath your feet...",
+		viewer:       NewReplayViewer(r),
 	}
 
-	for !rl.WindowShouldClose() {
-		deltaTime := float32(rl.GetTime() - previousTime)
-		previousTime = rl.GetTime()
+	g.sim = r.NewSimulation(soundManager, false)
+	g.attachRenderers()
 
-		// Update logic
-		if !g.isPaused && !g.showTitle && !g.showOutro && !g.ShowVictory {
-			g.Update(deltaTime)
-			g.checkGameEnd() // Check for game end conditions
-		} else if g.showTitle {
-			if g.titleScreen.Update(deltaTime) {
-				g.showTitle = false
-			}
-		} else if g.isPaused {
-			if g.pauseScreen.Update(deltaTime) {
-				g.isPaused = false
-			}
-		} else if g.ShowVictory {
-			if g.victoryScreen.Update(deltaTime) {
-				g.showOutro = true
-			}
-		} else if g.showOutro {
-			if g.outroScreen.Update(deltaTime) {
-				g.showOutro = false
-				g.showTitle = true // Return to title screen
-
-				w := world.NewWorld()
-				collectibleManager := world.NewCollectibleManager()
-
-				x, y := w.PlayerSpawn()
-				p := player.NewPlayer(x, y, w.Map, g.soundManager, collectibleManager.GetEffectsChan())
-				collectibleManager.SetPlayerPosition(&p.Position)
-
-				em := enemies.NewEnemiesManager(x, y, w.Map, p.AttackChan, w.Map.GetRoomsRects(), g.soundManager)
-				em.SpawnEnemies()
-
-				rle := effects.NewRetroLightingEffect(
-					int32(helpers.MAP_WIDTH*helpers.TILE_SIZE),
-					int32(helpers.MAP_HEIGHT*helpers.TILE_SIZE),
-					50, 2, p,
-				)
-
-				collectibleManager.ScatterCollectibles(w.Map.GetRoomsRects(), w.Map)
-				rle.SetUpPropsLightning(w.PropsManager.GetProps())
-
-				mm := minimap.NewMinimap(w.Map)
-
-				// Update game state
-				g.world = w
-				g.player = p
-				g.enemies = em
-				g.lightning = rle
-				g.minimap = mm
-				g.collectiblesManager = collectibleManager
-				g.shiftTimer = 0
-				g.isShifting = false
-				g.shiftDelay = helpers.GetShiftDelay()
-				g.fadeAlpha = 0
-
-				g.soundManager.RequestMusic("title_theme", true)
-			}
+	g.Init()
+	return g
+}
+
+// attachRenderers builds the lighting and minimap for the current dungeon
+// and keeps them in sync with the simulation's events.
+func (g *Game) attachRenderers() {
+	g.lightning = effects.NewRetroLightingEffect(
+		int32(g.sim.World.Map.Width()*helpers.TILE_SIZE),
+		int32(g.sim.World.Map.Height()*helpers.TILE_SIZE),
+		50, 2, g.sim.Player,
+	)
+	g.lightning.SetUpPropsLightning(g.sim.World.PropsManager.GetProps())
+	g.minimap = minimap.NewMinimap(g.sim.World.Map)
+
+	events.Subscribe(g.sim.Events, func(events.ShiftStarted) {
+		g.soundManager.RequestSound("biwa", 1.0, 1.0)
+		g.shiftTexts = []ShiftText{
+			getRandomShiftText(),
+			getRandomShiftText(),
+			getRandomShiftText(),
 		}
+		g.currentTextIdx = 0
+		g.responseTimer = 2 // Wait for dungeon's taunt to finish
+	})
+	events.Subscribe(g.sim.Events, func(events.DungeonShifted) {
+		g.fitLighting()
+		g.lightning.SetUpPropsLightning(g.sim.World.PropsManager.GetProps())
+		g.lightning.SetMode("static") // Reset to default lighting mode
+		g.minimap.SetDirty()
+	})
+	events.Subscribe(g.sim.Events, func(e events.FloorChanged) {
+		g.fitLighting() // Deeper floors are larger
+		g.lightning.SetUpPropsLightning(g.sim.World.PropsManager.GetProps())
+		g.lightning.SetMode("static") // The new floor isn't about to shift
+		g.minimap.SetDirty()
+		g.sim.Player.ShowMessage(fmt.Sprintf("Floor %d", e.Depth))
+	})
+	events.Subscribe(g.sim.Events, func(e events.BossEncountered) {
+		g.fitLighting() // The arena has its own size
+		g.lightning.SetUpPropsLightning(g.sim.World.PropsManager.GetProps())
+		g.lightning.SetMode("static") // The arena never shifts
+		g.minimap.SetDirty()
+		g.soundManager.RequestMusic(defs.Get().Bosses[e.Name].Music, true)
+	})
+}
 
-		helpers.LogOnce(1, "HELLOOOO")
+// fitLighting sizes the light mask for the current layout.
+func (g *Game) fitLighting() {
+	g.lightning.Resize(
+		int32(g.sim.World.Map.Width()*helpers.TILE_SIZE),
+		int32(g.sim.World.Map.Height()*helpers.TILE_SIZE),
+	)
+}
 
-		// Update camera target to follow the player
-		g.camera.Target = rl.Vector2{X: float32(g.player.Position.X), Y: float32(g.player.Position.Y)}
+// saveRun writes the current run to path, and its replay so far.
+func (g *Game) saveRun(path string) error {
+	if g.viewer != nil {
+		return nil // Watching a replay must not overwrite the player's save
+	}
 
-		// Ensure the camera offset stays centered even if window size changes
-		g.camera.Offset = rl.Vector2{X: float32(rl.GetScreenWidth()) / 2, Y: float32(rl.GetScreenHeight()) / 2}
+	g.writeReplay()
+	return save.Write(path, &save.File{Sim: g.sim.Snapshot()})
+}
 
-		rl.BeginDrawing()
-		rl.ClearBackground(helpers.VOID_COLOR) // rgb(88, 68, 34)
+// writeReplay saves the run recorded so far, so it can be watched again.
+func (g *Game) writeReplay() {
+	g.recording.Finish(g.sim)
+	if err := replay.Write(replay.DEFAULT_PATH, g.recording); err != nil {
+		helpers.DEBUG("Replay failed", err)
+	}
+}
 
-		// Render game world if not in title screen
-		if !g.showTitle {
-			rl.BeginMode2D(g.camera)
-			g.Render()
-			rl.EndMode2D()
-		}
+// loadRun resumes the run saved at path.
+func (g *Game) loadRun(path string, config world.Config) error {
+	f, err := save.Read(path)
+	if err != nil {
+		return err
+	}
 
-		// Render overlay screens
-		if g.showTitle {
-			g.titleScreen.Render()
-		} else if g.isPaused {
-			g.pauseScreen.Render()
-		} else if g.ShowVictory {
-			g.victoryScreen.Render()
-			rl.EndDrawing()
-			continue
-		} else if g.showOutro {
-			g.outroScreen.Render()
-
-			rl.EndDrawing()
-			continue
-		}
+	g.sim = sim.Restore(f.Sim, config, g.soundManager, false)
+	g.recording = replay.FromState(f.Sim, config)
+	g.attachRenderers()
 
-		if g.flags&RENDER_DEBUG != 0 {
-			rl.DrawText("Cryptic Descent", 10, 10, 20, rl.Gray)
-			fpsText := fmt.Sprintf("FPS: %d", rl.GetFPS())
-			rl.DrawText(fpsText, 10, 35, 20, rl.Gray)
-			rl.DrawText(fmt.Sprintf("CAM ZOOM : %.3f", g.camera.Zoom), 10, 60, 20, rl.Gray)
-			rl.DrawText(fmt.Sprintf("DECAY FACTOR : %.3f", helpers.DECAY_FACTOR), 10, 85, 20, rl.Gray)
-			rl.DrawText(fmt.Sprintf("LIGHT RADIUS : %.3f", helpers.LIGHT_RADIUS), 10, 110, 20, rl.Gray)
-
-			// Debug information
-			rl.DrawText(fmt.Sprintf("Shift Timer: %.1f / %.1f", g.shiftTimer, g.shiftDelay), 10, 135, 20, rl.Gray)
-			rl.DrawText(fmt.Sprintf("Is Shifting: %v", g.isShifting), 10, 160, 20, rl.Gray)
-			rl.DrawText(fmt.Sprintf("Fade Alpha: %.2f", g.fadeAlpha), 10, 185, 20, rl.Gray)
-			// if g.isShifting {
-			// rl.DrawText(fmt.Sprintf("Text Progress: %d/%d", g.shiftTextIndex, len(g.shiftText)), 10, 210, 20, rl.Gray)
-			// }
-		}
-		rl.EndDrawing()
+	return nil
+}
+
+// Init centers the camera on the player.
+func (g *Game) Init() {
+	g.camera = rl.Camera2D{
+		Offset:   rl.Vector2{X: float32(g.width) / 2, Y: float32(g.height) / 2},
+		Target:   rl.Vector2{X: float32(g.sim.Player.Position.X), Y: float32(g.sim.Player.Position.Y)},
+		Rotation: 0.0,
+		Zoom:     4.5,
 	}
 }
 
-var lastLightSwitch time.Time
-var lastLightningSwitch time.Time
+func (g *Game) Type() screens.ScreenType {
+	return screens.GAME
+}
+
+func (g *Game) IsOverlay() bool {
+	return false
+}
+
+func (g *Game) Unload() {
+	// Cleanup if needed
+}
 
 func (g *Game) GetLastRoomPos() (int, int) {
-	lastRoom := (*g.world.Map.GetRooms())[len(*g.world.Map.GetRooms())-1]
+	lastRoom := (*g.sim.World.Map.GetRooms())[len(*g.sim.World.Map.GetRooms())-1]
 	return int(lastRoom.X + lastRoom.Height/2), int(lastRoom.Y + lastRoom.Width/2)
 }
 
-func (g *Game) Update(deltaTime float32) {
+// Update advances the run and reports which screen should follow it.
+func (g *Game) Update(deltaTime float32) screens.Transition {
 
-	// g.world.Update(deltaTime)
+	// g.sim.World.Update(deltaTime)
 
 	scrollY := rl.GetMouseWheelMove()
 
@@ -280,110 +259,61 @@ func (g *Game) Update(deltaTime float32) {
 		g.camera.Zoom += float32(scrollY) * 0.2
 	}
 
-	if g.player.GameHasEnded() {
+	if g.sim.Player.GameHasEnded() {
 		// Game over
-		return
-	}
-
-	// ! FOR DEVELOPMENT
-	if rl.IsKeyDown(rl.KeyR) {
-		x, y := g.world.SwitchMap()
-		g.player.Position = rl.NewVector2(x, y)
-
-		// Reset enemies
-		g.enemies.Rooms = g.world.Map.GetRoomsRects()
-		g.enemies.ResetEnemies()
-
-		// Reset collectibles
-		g.collectiblesManager.ScatterCollectibles(g.world.Map.GetRoomsRects(), g.world.Map)
-
-		// Reset lighting
-		// g.lightning.SetUpPropsLightning(g.world.PropsManager.GetProps())
-
-		// Reset minimap
-		g.minimap.SetDirty()
+		return g.checkGameEnd()
 	}
 
-	if rl.IsKeyDown(rl.KeyK) {
+	// ! FOR DEVELOPMENT, release builds never report these actions
+	if g.input.IsDown(input.DEBUG_DECAY_UP) {
 		helpers.DECAY_FACTOR += 0.25
 	}
 
-	if rl.IsKeyDown(rl.KeyJ) {
+	if g.input.IsDown(input.DEBUG_DECAY_DOWN) {
 		helpers.DECAY_FACTOR -= 0.25
 	}
 
-	if rl.IsKeyDown(rl.KeyI) {
+	if g.input.IsDown(input.DEBUG_LIGHT_UP) {
 		helpers.LIGHT_RADIUS += 0.5
 	}
 
-	if rl.IsKeyDown(rl.KeyU) {
+	if g.input.IsDown(input.DEBUG_LIGHT_DOWN) {
 		helpers.LIGHT_RADIUS -= 0.5
 	}
 
-	if rl.IsKeyDown(rl.KeyL) {
-		if time.Since(lastLightSwitch) > time.Second {
-			g.flags ^= RENDER_LIGHTING
-			lastLightSwitch = time.Now()
-		}
+	if g.input.IsPressed(input.DEBUG_TOGGLE_LIGHTING) {
+		g.flags ^= RENDER_LIGHTING
 	}
-	if rl.IsKeyDown(rl.KeyO) {
-		if time.Since(lastLightSwitch) > time.Second {
-			g.flags ^= RENDER_DEBUG
-			lastLightSwitch = time.Now()
-		}
+	if g.input.IsPressed(input.DEBUG_TOGGLE_OVERLAY) {
+		g.flags ^= RENDER_DEBUG
 	}
 
 	if g.flags&RENDER_LIGHTING != 0 {
 		g.lightning.Update()
 	}
 
-	if rl.IsKeyDown(rl.KeyP) {
-		if time.Since(lastLightningSwitch) > time.Second {
-			g.lightning.NextLightningMode()
-			lastLightningSwitch = time.Now()
-		}
+	if g.input.IsPressed(input.DEBUG_NEXT_LIGHTING) {
+		g.lightning.NextLightningMode()
 	}
 
 	//! END DEVELOPMENT
 
 	// Handle pause toggle
-	if rl.IsKeyPressed(rl.KeyEscape) {
-		g.isPaused = !g.isPaused
-	}
-
-	g.world.PropsManager.Update(deltaTime)
-	g.player.Update(deltaTime)
-	g.enemies.Update(deltaTime, g.player)
-	g.collectiblesManager.Update(deltaTime)
-
-	// PATH FINDING
-	// helpers.DEBUG("PLAYER POS", g.player.Position)
-	// g.world.Pathfinder.Update(
-	// 	int(g.player.Position.X/helpers.TILE_SIZE),
-	// 	int(g.player.Position.Y/helpers.TILE_SIZE),
-	// 	int(g.world.Map.GetRoomsRects()[len(g.world.Map.GetRoomsRects())-1].X),
-	// 	int(g.world.Map.GetRoomsRects()[len(g.world.Map.GetRoomsRects())-1].Y),
-	// )
-
-	// g.teleportTimer.Update(deltaTime)
-	// g.transition.Update()
-
-	// if g.teleportTimer.lastTickTime == 0 {
-	// 	g.teleportTimer.TriggerTick(g.soundManager)
-	// }
+	if g.input.IsPressed(input.PAUSE) {
+		return screens.PushScreen(screens.PAUSE)
+	}
 
-	// if g.teleportTimer.TeleportTriggered() {
-	// 	g.soundManager.PlayBiwaSound()
-	// 	g.transition.Start()
+	if g.viewer != nil {
+		g.viewer.Update(g, deltaTime)
+	} else {
+		g.advance(deltaTime)
+	}
 
-	// 	if !g.transition.active {
-	// 		g.TeleportPlayer()
-	// 		g.teleportTimer.Reset(15, 30) // Reset for next teleport
-	// 	}
-	// }
+	// Ensure the camera offset stays centered even if window size changes
+	g.camera.Offset = rl.Vector2{X: float32(rl.GetScreenWidth()) / 2, Y: float32(rl.GetScreenHeight()) / 2}
 
 	// Toggle map view with T key
-	if rl.IsKeyPressed(rl.KeyT) {
+	if g.input.IsPressed(input.TOGGLE_MAP) {
 		g.minimap.ToggleView()
 	}
 
@@ -391,132 +321,167 @@ func (g *Game) Update(deltaTime float32) {
 		g.minimap.SetDestination(destX, destY)
 	}
 
-	if g.keyCount < g.player.KeysCollected {
-		g.shiftTimer = g.shiftDelay - 2
-		g.keyCount = g.player.KeysCollected
+	if g.responseTimer > 0 {
+		g.responseTimer -= deltaTime
+		if g.responseTimer <= 0 {
+			g.respondToShift()
+		}
 	}
 
-	g.minimap.Update(g.player.GetPosition())
-	// println(g.lightning.Count(), len(*g.world.PropsManager.GetProps()))
+	g.minimap.Update(g.sim.Player.GetPosition())
+	g.updateShiftEffects()
 
-	// Update dungeon shift timer
-	if !g.isShifting {
-		g.shiftTimer += deltaTime
-		if g.shiftTimer >= g.shiftDelay-5 { // Start effect 5 seconds before shift
-			g.lightning.SetMode("heartbeat") // Set to HandleGlitchLighting (or any other mode you prefer)
-		}
-		if g.shiftTimer >= g.shiftDelay {
-			g.isShifting = true
-			g.fadeAlpha = 0
+	if g.awaitsPerk() {
+		return screens.PushScreen(screens.PERKS)
+	}
+
+	return g.checkGameEnd()
+}
+
+// awaitsPerk reports whether the player leveled up and the perk picker
+// should open. Replays play the recorded picks instead.
+func (g *Game) awaitsPerk() bool {
+	if g.viewer != nil || !g.sim.Player.ChoosingPerk() {
+		return false
+	}
+	for a := input.PICK_PERK_1; a <= input.PICK_PERK_4; a++ {
+		if g.latched.Has(a) {
+			return false // Picked, waiting for the next tick
 		}
-	} else {
+	}
+	return true
+}
 
-		// Handle the shift transition
-		const fadeSpeed = 1.0
-		const textDuration = 2.0 // Show text for 2 seconds
-
-		if g.shiftTextTimer < textDuration {
-			if !g.shiftSoundPlayed {
-				g.soundManager.RequestSound("biwa", 1.0, 1.0)
-				g.shiftSoundPlayed = true
-				// Initialize the shift texts when starting the sequence
-				g.shiftTexts = []ShiftText{
-					getRandomShiftText(),
-					getRandomShiftText(),
-					getRandomShiftText(),
-				}
-				g.currentTextIdx = 0
-				g.shiftTexts[0].startTime = g.shiftTextTimer
-				g.triggerPlayerShiftResponse()
-			}
+// ChoosePerk picks the perk at index i of the offers on the next tick,
+// through an action so replays pick it too.
+func (g *Game) ChoosePerk(i int) {
+	g.latched.Add(input.PICK_PERK_1 + input.Action(i))
+}
 
-			// Update the current text timing
-			if g.currentTextIdx < len(g.shiftTexts) {
-				currentText := &g.shiftTexts[g.currentTextIdx]
-				elapsedTime := g.shiftTextTimer - currentText.startTime
+// advance simulates as many fixed ticks as the frame time covers, every
+// one of them with the actions of this frame.
+func (g *Game) advance(deltaTime float32) {
+	g.accumulator += deltaTime
+	if g.accumulator > MAX_FRAME_TIME {
+		g.accumulator = MAX_FRAME_TIME
+	}
 
-				if elapsedTime >= currentText.duration && g.currentTextIdx < len(g.shiftTexts)-1 {
-					g.currentTextIdx++
-					g.shiftTexts[g.currentTextIdx].startTime = g.shiftTextTimer
-				}
-			}
+	// Keep presses until a tick sees them, a frame may be shorter than a tick
+	frame := g.input.State()
+	g.latched |= frame.Pressed
 
-			// First phase: fade to black
-			g.fadeAlpha += fadeSpeed * deltaTime
-			if g.fadeAlpha >= 1.0 {
-				g.fadeAlpha = 1.0
-				g.shiftTextTimer += deltaTime
-			}
-		} else if g.shiftTextTimer >= textDuration && g.shiftTextTimer < textDuration+0.1 {
-			// Perform the actual shift exactly once
-
-			x, y := g.world.SwitchMap()
-			g.player.Position = rl.NewVector2(x, y)
-			g.enemies.Rooms = g.world.Map.GetRoomsRects()
-			g.enemies.ResetEnemies()
-			g.collectiblesManager.ScatterCollectibles(g.world.Map.GetRoomsRects(), g.world.Map)
-			g.lightning.SetUpPropsLightning(g.world.PropsManager.GetProps())
-			g.lightning.SetMode("static") // Reset to default lighting mode
-			g.minimap.SetDirty()
-			g.shiftTextTimer = textDuration + 0.1
-			g.shiftSoundPlayed = false
-		} else {
-			// Final phase: fade back in
-			g.fadeAlpha -= fadeSpeed * deltaTime
-			if g.fadeAlpha <= 0 {
-				// Reset for next shift
-				g.isShifting = false
-				g.shiftTimer = 0
-				g.shiftTextTimer = 0
-				g.fadeAlpha = 0
-				g.shiftDelay = helpers.GetShiftDelay() // Random value between 40 and 80 seconds
-				// g.shiftDelay = float32(1 + rand.Intn(3)) // Random value between 40 and 80 seconds
-			}
+	for g.accumulator >= sim.TICK {
+		g.accumulator -= sim.TICK
+		g.tick(frame.Down | g.latched)
+		g.latched = 0
+	}
+}
+
+// tick steps the simulation once with the actions held and records them.
+func (g *Game) tick(down input.ActionSet) {
+	g.tickState = g.tickState.Next(down)
+	g.recording.Record(down)
+	g.sim.StepInput(g.tickState)
+}
+
+// updateShiftEffects follows the simulation's shift clock with the
+// lighting and the taunt texts.
+func (g *Game) updateShiftEffects() {
+	clock := &g.sim.ShiftClock
+
+	if !clock.Shifting() {
+		if clock.Timer >= clock.Delay-5 { // Start effect 5 seconds before shift
+			g.lightning.SetMode("heartbeat") // Set to HandleGlitchLighting (or any other mode you prefer)
+		}
+		return
+	}
+
+	// Update the current text timing
+	if g.currentTextIdx < len(g.shiftTexts) {
+		currentText := &g.shiftTexts[g.currentTextIdx]
+		elapsedTime := g.tauntTime() - currentText.startTime
+
+		if elapsedTime >= currentText.duration && g.currentTextIdx < len(g.shiftTexts)-1 {
+			g.currentTextIdx++
+			g.shiftTexts[g.currentTextIdx].startTime = g.tauntTime()
 		}
 	}
 }
 
+// alpha returns how far the frame is from the last tick to the next one.
+func (g *Game) alpha() float32 {
+	if g.viewer != nil {
+		return g.viewer.Alpha()
+	}
+	return g.accumulator / sim.TICK
+}
+
+// tauntTime returns how long the dungeon's taunt has been on screen.
+func (g *Game) tauntTime() float32 {
+	switch g.sim.ShiftClock.Phase {
+	case sim.SHIFT_TAUNTING:
+		return g.sim.ShiftClock.PhaseTimer
+	case sim.SHIFT_FADING_IN:
+		return sim.SHIFT_TAUNT_DURATION
+	}
+	return 0
+}
+
 func (g *Game) Render() {
+	// Draw everything between the last two ticks
+	g.sim.Interpolate(g.alpha())
+	defer g.sim.Settle()
+
+	// Update camera target to follow the player
+	g.camera.Target = rl.Vector2{X: float32(g.sim.Player.Position.X), Y: float32(g.sim.Player.Position.Y)}
+
 	rl.BeginMode2D(g.camera)
-	g.world.Render()
-	g.enemies.Render()
-	g.player.Render()
+	g.sim.World.Render()
+	g.sim.Enemies.Render()
+	g.sim.Player.Render()
 	// g.transition.Render()
-	// g.world.Pathfinde<r.Render()
+	// g.sim.World.Pathfinde<r.Render()
 	// //
-	g.collectiblesManager.Render()
-	// g.world.Pathfinder.Render(
-	// 	g.player.GetPlayerRoom(),
+	g.sim.Collectibles.Render()
+	// g.sim.World.Pathfinder.Render(
+	// 	g.sim.Player.GetPlayerRoom(),
 	// )
 
 	if g.flags&RENDER_LIGHTING != 0 {
 		g.lightning.Render()
 	}
+	g.sim.Numbers.Render() // Over the lighting so hits read in the dark
 	rl.EndMode2D()
 
 	// Render minimap after EndMode2D so it stays fixed on screen
-	g.minimap.Render(g.player.Position, helpers.ClaculatePulse(g.shiftDelay, g.shiftTimer))
-	g.player.RenderHearts()
-	g.player.TextBubble.Render(g.player.Position)
+	g.minimap.Render(g.sim.Player.Position, helpers.ClaculatePulse(g.sim.ShiftClock.Delay, g.sim.ShiftClock.Timer))
+	g.sim.Player.RenderHearts()
+	g.sim.Player.RenderHotbar()
+	g.sim.Player.RenderCoins()
+	g.sim.Player.RenderWeapons()
+	g.sim.Enemies.RenderBossBar()
+	g.sim.Player.TextBubble.Render(g.sim.Player.Position)
 	startX := float32(20)
 	startY := float32(rl.GetScreenHeight()) - 100
 
-	rl.DrawText(fmt.Sprintf("Enemies Killed: %d", g.enemies.KilledCount), int32(startX), int32(startY), 20, rl.Gray)
+	rl.DrawText(fmt.Sprintf("Enemies Killed: %d", g.sim.Enemies.KilledCount), int32(startX), int32(startY), 20, rl.Gray)
+	rl.DrawText(fmt.Sprintf("Floor: %d", g.sim.Depth()), int32(startX), int32(startY)+25, 20, rl.Gray)
+	rl.DrawText(fmt.Sprintf("Level: %d (%d/%d XP)", g.sim.Player.Level, g.sim.Player.XP, g.sim.Player.XPToNext()), int32(startX), int32(startY)-25, 20, rl.Gray)
 
 	// Render shift transition effects
-	if g.isShifting {
+	if clock := &g.sim.ShiftClock; clock.Shifting() {
 		currentWidth := int32(rl.GetScreenWidth())
 		currentHeight := int32(rl.GetScreenHeight())
 
 		// Draw darkening overlay with current screen dimensions
 		rl.DrawRectangle(0, 0, currentWidth, currentHeight,
-			rl.ColorAlpha(rl.Black, g.fadeAlpha))
+			rl.ColorAlpha(rl.Black, clock.Fade()))
 
 		// Draw text if faded to black
-		if g.fadeAlpha >= 1.0 {
+		if clock.Phase == sim.SHIFT_TAUNTING {
 			if g.currentTextIdx < len(g.shiftTexts) {
 				currentText := g.shiftTexts[g.currentTextIdx]
-				elapsedTime := g.shiftTextTimer - currentText.startTime
+				elapsedTime := g.tauntTime() - currentText.startTime
 
 				// Calculate fade in alpha
 				textAlpha := float32(1.0)
@@ -536,26 +501,45 @@ func (g *Game) Render() {
 	}
 
 	if g.flags&RENDER_DEBUG != 0 {
+		rl.DrawText("Cryptic Descent", 10, 10, 20, rl.Gray)
+		fpsText := fmt.Sprintf("FPS: %d", rl.GetFPS())
+		rl.DrawText(fpsText, 10, 35, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("CAM ZOOM : %.3f", g.camera.Zoom), 10, 60, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("DECAY FACTOR : %.3f", helpers.DECAY_FACTOR), 10, 85, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("LIGHT RADIUS : %.3f", helpers.LIGHT_RADIUS), 10, 110, 20, rl.Gray)
+
 		// Debug information
-		rl.DrawText(fmt.Sprintf("Shift Timer: %.1f / %.1f", g.shiftTimer, g.shiftDelay), 10, 135, 20, rl.Gray)
-		rl.DrawText(fmt.Sprintf("Is Shifting: %v", g.isShifting), 10, 160, 20, rl.Gray)
-		rl.DrawText(fmt.Sprintf("Fade Alpha: %.2f", g.fadeAlpha), 10, 185, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("Shift Timer: %.1f / %.1f", g.sim.ShiftClock.Timer, g.sim.ShiftClock.Delay), 10, 135, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("Is Shifting: %v", g.sim.ShiftClock.Shifting()), 10, 160, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("Fade Alpha: %.2f", g.sim.ShiftClock.Fade()), 10, 185, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("Seed: %d (run %d)", g.sim.World.Map.Seed(), g.sim.Seed), 10, 210, 20, rl.Gray)
+		rl.DrawText(fmt.Sprintf("Generator: %s", g.sim.World.Map.Generator()), 10, 235, 20, rl.Gray)
+	}
+
+	if g.viewer != nil {
+		g.viewer.Render()
 	}
 }
 
-func (g *Game) checkGameEnd() bool {
+func (g *Game) checkGameEnd() screens.Transition {
 	// For now, just check player's game end condition
 
-	if g.player.State == "victory" {
-		g.ShowVictory = true
-		return true
+	if g.viewer != nil {
+		return screens.Stay() // The viewer stays on the end of the run
 	}
 
-	if g.player.GameHasEnded() {
-		g.showOutro = true
-		return true
+	if g.sim.Player.State == "victory" {
+		save.Delete(save.DEFAULT_PATH) // A finished run can't be continued
+		g.writeReplay()
+		return screens.ReplaceScreen(screens.VICTORY)
 	}
-	return false
+
+	if g.sim.Player.GameHasEnded() {
+		save.Delete(save.DEFAULT_PATH)
+		g.writeReplay()
+		return screens.ReplaceScreen(screens.GAME_OVER)
+	}
+	return screens.Stay()
 }
 
 func getRandomShiftText() ShiftText {
@@ -605,7 +589,8 @@ const (
 	MSG_SHIFT_RESPONSE_10 = "Keep shifting, I'll keep fighting."
 )
 
-func (g *Game) triggerPlayerShiftResponse() {
+// respondToShift has the player answer the dungeon's taunt.
+func (g *Game) respondToShift() {
 	responses := []string{
 		MSG_SHIFT_RESPONSE_1,
 		MSG_SHIFT_RESPONSE_2,
@@ -619,9 +604,5 @@ func (g *Game) triggerPlayerShiftResponse() {
 		MSG_SHIFT_RESPONSE_10,
 	}
 
-	// Show response after a short delay
-	go func() {
-		time.Sleep(2 * time.Second) // Wait for dungeon's taunt to finish
-		g.player.ShowMessage(responses[rand.Intn(len(responses))])
-	}()
+	g.sim.Player.ShowMessage(responses[rand.Intn(len(responses))])
 }
@@ -3,6 +3,7 @@ package minimap
 import (
 	"crydes/helpers"
 	"crydes/world"
+	"fmt"
 
 	"math"
 
@@ -26,6 +27,8 @@ type Minimap struct {
 	hasDestination       bool
 	lastScreenWidth      float32
 	lastScreenHeight     float32
+	mapWidth             int // Tiles of the layout the texture was made for
+	mapHeight            int
 	destinationFadeStart float32
 }
 
@@ -55,32 +58,40 @@ func NewMinimap(mapData *world.Map) *Minimap {
 		Y: (screenHeight - centerSize.Y) / 2,
 	}
 
-	// Calculate scale based on map size and desired minimap size
-	scaleX := centerSize.X / float32(helpers.MAP_WIDTH*helpers.TILE_SIZE)
-	scaleY := centerSize.Y / float32(helpers.MAP_HEIGHT*helpers.TILE_SIZE)
-	scale := min(scaleX, scaleY)
-
-	println("KJSDHKJQHDQJHDS")
-	// Use the same scale for both dimensions to maintain aspect ratio
-	texture := rl.LoadRenderTexture(
-		int32(float32(helpers.MAP_WIDTH*helpers.TILE_SIZE)*scale),  // Match map dimensions
-		int32(float32(helpers.MAP_HEIGHT*helpers.TILE_SIZE)*scale), // Match map dimensions
-	)
-
-	return &Minimap{
-		scale:            scale,
+	m := &Minimap{
 		cornerPos:        cornerPos,
 		cornerSize:       cornerSize,
 		centerPos:        centerPos,
 		centerSize:       centerSize,
 		mapData:          mapData,
-		texture:          texture,
-		isDirty:          true,
 		borderPad:        2,
 		isFullscreen:     false,
 		lastScreenWidth:  screenWidth,
 		lastScreenHeight: screenHeight,
 	}
+	m.fitTexture()
+
+	return m
+}
+
+// fitTexture sizes the texture for the current layout and map view.
+func (m *Minimap) fitTexture() {
+	m.mapWidth, m.mapHeight = m.mapData.Width(), m.mapData.Height()
+
+	// Calculate scale based on map size and desired minimap size
+	scaleX := m.centerSize.X / float32(m.mapWidth*helpers.TILE_SIZE)
+	scaleY := m.centerSize.Y / float32(m.mapHeight*helpers.TILE_SIZE)
+	m.scale = min(scaleX, scaleY)
+
+	if m.texture.ID != 0 {
+		rl.UnloadRenderTexture(m.texture)
+	}
+	// Use the same scale for both dimensions to maintain aspect ratio
+	m.texture = rl.LoadRenderTexture(
+		int32(float32(m.mapWidth*helpers.TILE_SIZE)*m.scale),  // Match map dimensions
+		int32(float32(m.mapHeight*helpers.TILE_SIZE)*m.scale), // Match map dimensions
+	)
+	m.isDirty = true
 }
 
 func (m *Minimap) ToggleView() {
@@ -89,6 +100,9 @@ func (m *Minimap) ToggleView() {
 
 func (m *Minimap) Update(playerPos rl.Vector2) {
 	m.UpdateDimensions()
+	if m.mapWidth != m.mapData.Width() || m.mapHeight != m.mapData.Height() {
+		m.fitTexture() // The layout changed size
+	}
 
 	if m.isDirty {
 		m.RenderToTexture()
@@ -103,9 +117,9 @@ func (m *Minimap) RenderToTexture() {
 	rl.ClearBackground(rl.Black)
 
 	// Draw rooms
-	for x := 0; x < helpers.MAP_WIDTH; x++ {
-		for y := 0; y < helpers.MAP_HEIGHT; y++ {
-			if m.mapData.IsWalkable(x, y) {
+	for x := 0; x < m.mapWidth; x++ {
+		for y := 0; y < m.mapHeight; y++ {
+			if tile := m.mapData.TileAt(x, y); tile != world.TileWall {
 				posX := float32(x) * helpers.TILE_SIZE * m.scale
 				posY := float32(y) * helpers.TILE_SIZE * m.scale
 				size := float32(helpers.TILE_SIZE) * m.scale
@@ -115,7 +129,7 @@ func (m *Minimap) RenderToTexture() {
 					int32(posY),
 					int32(size),
 					int32(size),
-					rl.Gray,
+					tile.Color(),
 				)
 			}
 		}
@@ -126,9 +140,20 @@ func (m *Minimap) RenderToTexture() {
 
 func (m *Minimap) Render(playerPos rl.Vector2, left float32) {
 
+	floor := fmt.Sprintf("Floor %d", m.mapData.Depth())
+
 	if !m.isFullscreen {
 		// Draw corner minimap
 		m.renderAt(m.cornerPos, m.cornerSize, playerPos, 3, left)
+
+		// Draw the floor under it
+		fontSize := int32(20)
+		textWidth := rl.MeasureText(floor, fontSize)
+		rl.DrawText(floor,
+			int32(m.cornerPos.X+m.cornerSize.X)-textWidth,
+			int32(m.cornerPos.Y+m.cornerSize.Y)+int32(m.borderPad)+6,
+			fontSize,
+			rl.Gray)
 	} else {
 		// Draw semi-transparent background
 		rl.DrawRectangle(0, 0, helpers.SCREEN_WIDTH, helpers.SCREEN_HEIGHT,
@@ -137,10 +162,19 @@ func (m *Minimap) Render(playerPos rl.Vector2, left float32) {
 		// Draw centered map
 		m.renderAt(m.centerPos, m.centerSize, playerPos, 6, left)
 
+		// Draw the floor above it
+		fontSize := int32(30)
+		textWidth := rl.MeasureText(floor, fontSize)
+		rl.DrawText(floor,
+			int32(m.centerPos.X+(m.centerSize.X-float32(textWidth))/2),
+			int32(m.centerPos.Y)-fontSize-10,
+			fontSize,
+			rl.White)
+
 		// Draw instructions
 		text := "Press T to close map"
-		fontSize := int32(20)
-		textWidth := rl.MeasureText(text, fontSize)
+		fontSize = int32(20)
+		textWidth = rl.MeasureText(text, fontSize)
 		rl.DrawText(text,
 			int32(m.centerPos.X+(m.centerSize.X-float32(textWidth))/2),
 			int32(m.centerPos.Y+m.centerSize.Y+10),
@@ -179,8 +213,8 @@ func (m *Minimap) renderAt(pos, size rl.Vector2, playerPos rl.Vector2, playerDot
 	)
 
 	// Calculate player position relative to map size
-	relativeX := (playerPos.X / float32(helpers.MAP_WIDTH*helpers.TILE_SIZE)) * size.X
-	relativeY := (playerPos.Y / float32(helpers.MAP_HEIGHT*helpers.TILE_SIZE)) * size.Y
+	relativeX := (playerPos.X / float32(m.mapWidth*helpers.TILE_SIZE)) * size.X
+	relativeY := (playerPos.Y / float32(m.mapHeight*helpers.TILE_SIZE)) * size.Y
 
 	// Draw player dot glow effect
 	glowSize := playerDotSize * 2
@@ -208,8 +242,8 @@ func (m *Minimap) renderAt(pos, size rl.Vector2, playerPos rl.Vector2, playerDot
 	// Draw the destination marker if it exists
 	if m.hasDestination {
 		// Calculate destination position relative to map dimensions
-		destRelativeX := (float32(m.destinationX) * helpers.TILE_SIZE) / float32(helpers.MAP_WIDTH*helpers.TILE_SIZE)
-		destRelativeY := (float32(m.destinationY) * helpers.TILE_SIZE) / float32(helpers.MAP_HEIGHT*helpers.TILE_SIZE)
+		destRelativeX := (float32(m.destinationX) * helpers.TILE_SIZE) / float32(m.mapWidth*helpers.TILE_SIZE)
+		destRelativeY := (float32(m.destinationY) * helpers.TILE_SIZE) / float32(m.mapHeight*helpers.TILE_SIZE)
 
 		destMapX := destRelativeX * size.X
 		destMapY := destRelativeY * size.Y
@@ -297,15 +331,6 @@ func (m *Minimap) UpdateDimensions() {
 		Y: (screenHeight - m.centerSize.Y) / 2,
 	}
 
-	// Recalculate scale
-	scaleX := m.centerSize.X / float32(helpers.MAP_WIDTH*helpers.TILE_SIZE)
-	scaleY := m.centerSize.Y / float32(helpers.MAP_HEIGHT*helpers.TILE_SIZE)
-	m.scale = min(scaleX, scaleY)
-
-	// Update texture size
-	m.texture = rl.LoadRenderTexture(
-		int32(float32(helpers.MAP_WIDTH*helpers.TILE_SIZE)*m.scale),
-		int32(float32(helpers.MAP_HEIGHT*helpers.TILE_SIZE)*m.scale),
-	)
-	m.isDirty = true
+	// Recalculate scale and texture size
+	m.fitTexture()
 }
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"crydes/input"
+	"crydes/replay"
+	"crydes/sim"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// REPLAY_SPEEDS are the playback speeds the viewer steps through.
+var REPLAY_SPEEDS = []float32{0.25, 0.5, 1, 2, 4, 8}
+
+// ReplayViewer feeds a recorded run to the game in place of the player,
+// it can be paused, sped up and stepped one tick at a time.
+type ReplayViewer struct {
+	replay *replay.Replay
+	next   int         // Index of the next tick to play
+	state  input.State // Actions of the last played tick
+
+	paused      bool
+	speed       int     // Index in REPLAY_SPEEDS
+	accumulator float32 // Playback time not played yet
+}
+
+func NewReplayViewer(r *replay.Replay) *ReplayViewer {
+	return &ReplayViewer{
+		replay: r,
+		speed:  2, // Real time
+	}
+}
+
+// Update reads the viewer controls and plays the ticks the frame covers.
+func (v *ReplayViewer) Update(g *Game, deltaTime float32) {
+	if g.input.IsPressed(input.REPLAY_PAUSE) {
+		v.paused = !v.paused
+	}
+	if g.input.IsPressed(input.REPLAY_FASTER) && v.speed < len(REPLAY_SPEEDS)-1 {
+		v.speed++
+	}
+	if g.input.IsPressed(input.REPLAY_SLOWER) && v.speed > 0 {
+		v.speed--
+	}
+
+	if v.paused {
+		if g.input.IsPressed(input.REPLAY_STEP) {
+			v.step(g.sim)
+		}
+		return
+	}
+
+	speed := REPLAY_SPEEDS[v.speed]
+	v.accumulator += deltaTime * speed
+	if v.accumulator > MAX_FRAME_TIME*speed {
+		v.accumulator = MAX_FRAME_TIME * speed
+	}
+
+	for v.accumulator >= sim.TICK && !v.Done() {
+		v.accumulator -= sim.TICK
+		v.step(g.sim)
+	}
+
+	if v.Done() {
+		v.paused = true
+	}
+}
+
+// step plays the next recorded tick.
+func (v *ReplayViewer) step(s *sim.Simulation) {
+	if v.Done() {
+		return
+	}
+	v.state = v.replay.Step(s, v.next, v.state)
+	v.next++
+}
+
+// Alpha returns how far playback is from the last tick to the next one, a
+// paused replay shows the last tick as it is.
+func (v *ReplayViewer) Alpha() float32 {
+	if v.paused {
+		return 1
+	}
+	return v.accumulator / sim.TICK
+}
+
+// Done reports whether every recorded tick was played.
+func (v *ReplayViewer) Done() bool {
+	return v.next >= len(v.replay.Ticks)
+}
+
+// Render draws the playback status at the top of the screen.
+func (v *ReplayViewer) Render() {
+	status := fmt.Sprintf("REPLAY  tick %d/%d  x%g", v.next, len(v.replay.Ticks), REPLAY_SPEEDS[v.speed])
+	if v.Done() {
+		status += "  END"
+	} else if v.paused {
+		status += "  PAUSED"
+	}
+
+	fontSize := int32(20)
+	width := rl.MeasureText(status, fontSize)
+	rl.DrawText(status, int32(rl.GetScreenWidth())/2-width/2, 10, fontSize, rl.White)
+}
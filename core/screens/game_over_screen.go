@@ -0,0 +1,92 @@
+package screens
+
+import (
+	"crydes/audio"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// GameOverScreen is shown when the player dies, it offers a new run or the
+// way back to the title.
+type GameOverScreen struct {
+	buttons      []*Button
+	soundManager *audio.SoundManager
+	next         Transition
+	summary      string // How far the run got
+	fadeAlpha    float32
+}
+
+func NewGameOverScreen(soundManager *audio.SoundManager, summary string) *GameOverScreen {
+	gs := &GameOverScreen{
+		soundManager: soundManager,
+		summary:      summary,
+	}
+	gs.Init()
+	return gs
+}
+
+func (gs *GameOverScreen) Type() ScreenType {
+	return GAME_OVER
+}
+
+func (gs *GameOverScreen) Init() {
+	screenWidth := float32(rl.GetScreenWidth())
+	screenHeight := float32(rl.GetScreenHeight())
+	buttonWidth := float32(200)
+	buttonHeight := float32(50)
+	startX := (screenWidth - buttonWidth) / 2
+	startY := screenHeight/2 + buttonHeight
+
+	gs.buttons = []*Button{
+		NewButton(startX, startY, buttonWidth, buttonHeight, "Try Again", func() {
+			gs.soundManager.RequestSound("menu_select", 1.0, 1.0)
+			gs.next = ReplaceScreen(GAME)
+		}),
+		NewButton(startX, startY+buttonHeight+20, buttonWidth, buttonHeight, "Back to Title", func() {
+			gs.soundManager.RequestSound("menu_select", 1.0, 1.0)
+			gs.next = ReplaceScreen(TITLE)
+		}),
+	}
+}
+
+func (gs *GameOverScreen) Update(deltaTime float32) Transition {
+	if gs.fadeAlpha < 1.0 {
+		gs.fadeAlpha += deltaTime * 2.0
+		if gs.fadeAlpha > 1.0 {
+			gs.fadeAlpha = 1.0
+		}
+	}
+
+	for _, button := range gs.buttons {
+		button.Update()
+	}
+	return gs.next
+}
+
+func (gs *GameOverScreen) IsOverlay() bool {
+	return false
+}
+
+func (gs *GameOverScreen) Render() {
+	rl.ClearBackground(rl.Black)
+
+	screenWidth := int32(rl.GetScreenWidth())
+	screenHeight := int32(rl.GetScreenHeight())
+
+	titleText := "You Died"
+	fontSize := int32(80)
+	textWidth := rl.MeasureText(titleText, fontSize)
+	rl.DrawText(titleText, (screenWidth-textWidth)/2, screenHeight/2-2*fontSize, fontSize, rl.ColorAlpha(rl.Maroon, gs.fadeAlpha))
+
+	subFontSize := int32(30)
+	subTextWidth := rl.MeasureText(gs.summary, subFontSize)
+	rl.DrawText(gs.summary, (screenWidth-subTextWidth)/2, screenHeight/2-fontSize/2, subFontSize, rl.ColorAlpha(rl.Gray, gs.fadeAlpha))
+
+	for _, button := range gs.buttons {
+		button.Render()
+	}
+}
+
+func (gs *GameOverScreen) Unload() {
+	// Clean up any resources if needed
+}
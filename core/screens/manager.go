@@ -0,0 +1,152 @@
+package screens
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// FADE_DURATION is how long each half of a fade between screens takes
+const FADE_DURATION = float32(0.3)
+
+// ScreenFactory builds a fresh screen when a transition targets its type
+type ScreenFactory func() Screen
+
+// ScreenManager owns the stack of screens. Only the top screen is updated,
+// overlays are rendered on top of the screens below them, and replacing a
+// screen fades through black.
+type ScreenManager struct {
+	stack     []Screen
+	factories map[ScreenType]ScreenFactory
+
+	pending   *Transition // Applied once the fade out completes
+	fadeAlpha float32
+	fadingIn  bool
+	quit      bool
+}
+
+func NewScreenManager() *ScreenManager {
+	return &ScreenManager{
+		stack:     []Screen{},
+		factories: make(map[ScreenType]ScreenFactory),
+	}
+}
+
+// Register makes a screen type reachable from transitions
+func (m *ScreenManager) Register(t ScreenType, factory ScreenFactory) {
+	m.factories[t] = factory
+}
+
+// Push puts a screen on top of the stack
+func (m *ScreenManager) Push(s Screen) {
+	m.stack = append(m.stack, s)
+}
+
+// Pop removes the top screen and unloads it
+func (m *ScreenManager) Pop() {
+	if len(m.stack) == 0 {
+		return
+	}
+	top := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	top.Unload()
+}
+
+// Replace swaps the whole stack for a single screen
+func (m *ScreenManager) Replace(s Screen) {
+	for len(m.stack) > 0 {
+		m.Pop()
+	}
+	m.Push(s)
+}
+
+// Top returns the screen currently receiving updates
+func (m *ScreenManager) Top() Screen {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// ShouldQuit reports whether a screen asked the game to close
+func (m *ScreenManager) ShouldQuit() bool {
+	return m.quit
+}
+
+// Update advances the fade if one is running, otherwise the top screen
+func (m *ScreenManager) Update(deltaTime float32) {
+	if m.pending != nil {
+		m.fadeAlpha += deltaTime / FADE_DURATION
+		if m.fadeAlpha >= 1 {
+			m.fadeAlpha = 1
+			m.apply(*m.pending)
+			m.pending = nil
+			m.fadingIn = true
+		}
+		return
+	}
+
+	if m.fadingIn {
+		m.fadeAlpha -= deltaTime / FADE_DURATION
+		if m.fadeAlpha <= 0 {
+			m.fadeAlpha = 0
+			m.fadingIn = false
+		}
+	}
+
+	top := m.Top()
+	if top == nil {
+		return
+	}
+
+	transition := top.Update(deltaTime)
+	switch transition.Op {
+	case NONE:
+	case REPLACE:
+		// Replacing changes scene, so fade through black
+		m.pending = &transition
+	default:
+		m.apply(transition)
+	}
+}
+
+// apply performs a transition on the stack immediately
+func (m *ScreenManager) apply(transition Transition) {
+	switch transition.Op {
+	case PUSH:
+		if s := m.build(transition.Target); s != nil {
+			m.Push(s)
+		}
+	case POP:
+		m.Pop()
+	case REPLACE:
+		if s := m.build(transition.Target); s != nil {
+			m.Replace(s)
+		}
+	case QUIT:
+		m.quit = true
+	}
+}
+
+func (m *ScreenManager) build(t ScreenType) Screen {
+	factory, exists := m.factories[t]
+	if !exists {
+		rl.TraceLog(rl.LogWarning, "No screen registered for type %d", t)
+		return nil
+	}
+	return factory()
+}
+
+// Render draws the top screen and, when it is an overlay, every screen
+// below it down to the first opaque one.
+func (m *ScreenManager) Render() {
+	first := len(m.stack) - 1
+	for first > 0 && m.stack[first].IsOverlay() {
+		first--
+	}
+
+	for i := first; i >= 0 && i < len(m.stack); i++ {
+		m.stack[i].Render()
+	}
+
+	if m.fadeAlpha > 0 {
+		rl.DrawRectangle(0, 0, int32(rl.GetScreenWidth()), int32(rl.GetScreenHeight()),
+			rl.ColorAlpha(rl.Black, m.fadeAlpha))
+	}
+}
@@ -9,7 +9,7 @@ import (
 type OutroScreen struct {
 	buttons      []*Button
 	soundManager *audio.SoundManager
-	nextScreen   ScreenType
+	next         Transition
 	startTime    float32
 	fadeAlpha    float32
 	credits      []CreditEntry
@@ -24,7 +24,6 @@ type CreditEntry struct {
 func NewOutroScreen(soundManager *audio.SoundManager) *OutroScreen {
 	os := &OutroScreen{
 		soundManager: soundManager,
-		nextScreen:   OUTRO,
 		startTime:    0,
 		fadeAlpha:    0,
 		scrollY:      float32(rl.GetScreenHeight())/2 + 100,
@@ -71,14 +70,14 @@ func (os *OutroScreen) Init() {
 	os.buttons = []*Button{
 		NewButton(startX, startY, buttonWidth, buttonHeight, "Back to Title", func() {
 			os.soundManager.RequestSound("menu_select", 1.0, 1.0)
-			os.nextScreen = TITLE
+			os.next = ReplaceScreen(TITLE)
 		}),
 	}
 
 	// Start outro music
 }
 
-func (os *OutroScreen) Update(deltaTime float32) bool {
+func (os *OutroScreen) Update(deltaTime float32) Transition {
 	// Check if outro music is playing
 	if os.soundManager.GetCurrentMusic() != "outro" {
 		os.soundManager.RequestMusic("outro", true)
@@ -111,7 +110,11 @@ func (os *OutroScreen) Update(deltaTime float32) bool {
 	}
 
 	// Return to title screen if requested
-	return os.nextScreen == TITLE
+	return os.next
+}
+
+func (os *OutroScreen) IsOverlay() bool {
+	return false
 }
 
 func (os *OutroScreen) Render() {
@@ -2,6 +2,7 @@ package screens
 
 import (
 	"crydes/audio"
+	"crydes/input"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -9,13 +10,15 @@ import (
 type PauseScreen struct {
 	buttons      []*Button
 	soundManager *audio.SoundManager
-	nextScreen   ScreenType
+	input        *input.Input
+	next         Transition
+	onSave       func() // Called by "Save & Quit" before closing
 }
 
-func NewPauseScreen(soundManager *audio.SoundManager) *PauseScreen {
+func NewPauseScreen(soundManager *audio.SoundManager, in *input.Input) *PauseScreen {
 	ps := &PauseScreen{
 		soundManager: soundManager,
-		nextScreen:   PAUSE,
+		input:        in,
 	}
 	ps.Init()
 	return ps
@@ -36,30 +39,43 @@ func (ps *PauseScreen) Init() {
 	ps.buttons = []*Button{
 		NewButton(startX, startY, buttonWidth, buttonHeight, "Resume", func() {
 			ps.soundManager.RequestSound("menu_select", 1.0, 1.0)
-			ps.nextScreen = GAME
+			ps.next = PopScreen()
 		}),
-		NewButton(startX, startY+buttonHeight+20, buttonWidth, buttonHeight, "Quit", func() {
+		NewButton(startX, startY+buttonHeight+20, buttonWidth, buttonHeight, "Save & Quit", func() {
 			ps.soundManager.RequestSound("menu_select", 1.0, 1.0)
-			rl.CloseWindow()
+			if ps.onSave != nil {
+				ps.onSave()
+			}
+			ps.next = QuitGame()
+		}),
+		NewButton(startX, startY+(buttonHeight+20)*2, buttonWidth, buttonHeight, "Quit", func() {
+			ps.soundManager.RequestSound("menu_select", 1.0, 1.0)
+			ps.next = QuitGame()
 		}),
 	}
 }
 
-func (ps *PauseScreen) Update(deltaTime float32) bool {
+// SetOnSave sets what "Save & Quit" does before closing the game.
+func (ps *PauseScreen) SetOnSave(onSave func()) {
+	ps.onSave = onSave
+}
+
+func (ps *PauseScreen) Update(deltaTime float32) Transition {
 	for _, button := range ps.buttons {
 		button.Update()
 	}
 
 	// Handle pause toggle
-	if rl.IsKeyPressed(rl.KeyEscape) {
-		return true
+	if ps.input.IsPressed(input.PAUSE) {
+		return PopScreen()
 	}
 
-	defer func() {
-		ps.nextScreen = PAUSE
-	}()
+	return ps.next
+}
 
-	return ps.nextScreen == GAME
+// IsOverlay keeps the paused game visible under the menu
+func (ps *PauseScreen) IsOverlay() bool {
+	return true
 }
 
 func (ps *PauseScreen) Render() {
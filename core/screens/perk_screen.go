@@ -0,0 +1,109 @@
+package screens
+
+import (
+	"crydes/audio"
+	"crydes/input"
+	"crydes/player"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PerkScreen offers the player the perks they can pick after a level up,
+// the game stays paused under it until they pick one.
+type PerkScreen struct {
+	buttons      []*Button
+	soundManager *audio.SoundManager
+	input        *input.Input
+	player       *player.Player
+	offers       []*player.Perk
+	next         Transition
+	onPick       func(i int) // Hands the pick over to the game
+}
+
+func NewPerkScreen(soundManager *audio.SoundManager, in *input.Input, p *player.Player, onPick func(i int)) *PerkScreen {
+	ps := &PerkScreen{
+		soundManager: soundManager,
+		input:        in,
+		player:       p,
+		onPick:       onPick,
+	}
+	ps.Init()
+	return ps
+}
+
+func (ps *PerkScreen) Type() ScreenType {
+	return PERKS
+}
+
+func (ps *PerkScreen) Init() {
+	ps.offers = ps.player.PerkOffers()
+
+	screenWidth := float32(rl.GetScreenWidth())
+	screenHeight := float32(rl.GetScreenHeight())
+	buttonWidth := float32(300)
+	buttonHeight := float32(50)
+	spacing := buttonHeight + 40
+	startX := (screenWidth - buttonWidth) / 2
+	startY := screenHeight/2 - spacing*float32(len(ps.offers))/2
+
+	ps.buttons = nil
+	for i, perk := range ps.offers {
+		i := i
+		ps.buttons = append(ps.buttons, NewButton(startX, startY+spacing*float32(i), buttonWidth, buttonHeight, perk.Title, func() {
+			ps.pick(i)
+		}))
+	}
+}
+
+// pick hands the perk at index i of the offers to the game and closes the
+// screen.
+func (ps *PerkScreen) pick(i int) {
+	ps.soundManager.RequestSound("menu_select", 1.0, 1.0)
+	ps.onPick(i)
+	ps.next = PopScreen()
+}
+
+func (ps *PerkScreen) Update(deltaTime float32) Transition {
+	for _, button := range ps.buttons {
+		button.Update()
+	}
+
+	// The hotbar keys pick the perks in order
+	for i, slot := range []input.Action{input.USE_SLOT_1, input.USE_SLOT_2, input.USE_SLOT_3, input.USE_SLOT_4} {
+		if i < len(ps.offers) && ps.input.IsPressed(slot) && ps.next.Op == NONE {
+			ps.pick(i)
+		}
+	}
+
+	return ps.next
+}
+
+// IsOverlay keeps the game visible under the picker
+func (ps *PerkScreen) IsOverlay() bool {
+	return true
+}
+
+func (ps *PerkScreen) Render() {
+	rl.DrawRectangle(0, 0, int32(rl.GetScreenWidth()), int32(rl.GetScreenHeight()),
+		rl.ColorAlpha(rl.Black, 0.7))
+
+	title := fmt.Sprintf("LEVEL %d", ps.player.Level)
+	fontSize := int32(60)
+	textWidth := rl.MeasureText(title, fontSize)
+	rl.DrawText(title, int32(float32(rl.GetScreenWidth()-int(textWidth))/2), 100, fontSize, rl.Gold)
+
+	for i, button := range ps.buttons {
+		button.Render()
+
+		perk := ps.offers[i]
+		text := fmt.Sprintf("%d. %s (%d/%d)", i+1, perk.Description, ps.player.Perks[perk.Name], perk.MaxRank)
+		width := rl.MeasureText(text, 20)
+		x := button.Bounds.X + (button.Bounds.Width-float32(width))/2
+		rl.DrawText(text, int32(x), int32(button.Bounds.Y+button.Bounds.Height)+6, 20, rl.RayWhite)
+	}
+}
+
+func (ps *PerkScreen) Unload() {
+	// Cleanup if needed
+}
@@ -8,7 +8,9 @@ type ScreenType int
 const (
 	TITLE ScreenType = iota
 	GAME
+	CONTINUE // The game, resumed from the save slot
 	PAUSE
+	PERKS // Picking a perk after a level up
 	GAME_OVER
 	VICTORY
 	OUTRO
@@ -16,13 +18,47 @@ const (
 
 // Screen interface defines methods that all screens must implement
 type Screen interface {
-	Update(deltaTime float32) ScreenType // Returns the next screen to transition to
+	Update(deltaTime float32) Transition // Returns what the manager should do next
 	Render()
 	Init()
 	Type() ScreenType
 	Unload()
+	IsOverlay() bool // Overlays are drawn on top of the screen below them
 }
 
+// TransitionOp is the stack operation a screen asks for
+type TransitionOp int
+
+const (
+	NONE TransitionOp = iota
+	PUSH
+	POP
+	REPLACE
+	QUIT
+)
+
+// Transition tells the ScreenManager how to change the stack. Target is
+// looked up in the registered factories for PUSH and REPLACE.
+type Transition struct {
+	Op     TransitionOp
+	Target ScreenType
+}
+
+// Stay keeps the current screen
+func Stay() Transition { return Transition{Op: NONE} }
+
+// PushScreen opens target on top of the current screen
+func PushScreen(target ScreenType) Transition { return Transition{Op: PUSH, Target: target} }
+
+// PopScreen closes the current screen, returning to the one below
+func PopScreen() Transition { return Transition{Op: POP} }
+
+// ReplaceScreen swaps the current screen for target
+func ReplaceScreen(target ScreenType) Transition { return Transition{Op: REPLACE, Target: target} }
+
+// QuitGame asks the game to close
+func QuitGame() Transition { return Transition{Op: QUIT} }
+
 // Button represents a clickable UI element
 type Button struct {
 	Bounds    rl.Rectangle
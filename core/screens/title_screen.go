@@ -4,6 +4,7 @@ import (
 	"crydes/audio"
 	"crydes/helpers"
 	"crydes/player"
+	"crydes/save"
 	"crydes/world"
 	"math/rand"
 
@@ -13,8 +14,9 @@ import (
 type TitleScreen struct {
 	buttons      []*Button
 	soundManager *audio.SoundManager
-	nextScreen   ScreenType
+	next         Transition
 	muteButton   *Button
+	saveError    string // Why the saved run can't be continued, shown under the buttons
 
 	// Demo scene components
 	demoWorld        *world.World
@@ -34,14 +36,13 @@ type TitleScreen struct {
 func NewTitleScreen(soundManager *audio.SoundManager) *TitleScreen {
 	ts := &TitleScreen{
 		soundManager:     soundManager,
-		nextScreen:       TITLE,
 		demoCollectibles: make([]rl.Vector2, 0),
 		attackTimer:      0,
 		attackInterval:   2.0,
 	}
 
 	// Initialize demo world
-	ts.demoWorld = world.NewWorld()
+	ts.demoWorld = world.NewWorld(helpers.RandomSeed(), world.DEFAULT_CONFIG, false)
 
 	// Initialize pathfinder
 	ts.pathfinder = world.NewPathfinder(ts.demoWorld.Map)
@@ -97,22 +98,38 @@ func (ts *TitleScreen) Init() {
 		},
 	)
 
-	ts.buttons = []*Button{
+	ts.buttons = []*Button{}
+
+	// Offer to resume the saved run first, unless it can't be read
+	if save.Exists(save.DEFAULT_PATH) && ts.saveError == "" {
+		if _, err := save.Read(save.DEFAULT_PATH); err != nil {
+			ts.saveError = err.Error()
+		}
+	}
+	if save.Exists(save.DEFAULT_PATH) && ts.saveError == "" {
+		ts.buttons = append(ts.buttons, NewButton(startX, startY, buttonWidth, buttonHeight, "Continue", func() {
+			ts.soundManager.RequestSound("menu_select", 1.0, 1.0)
+			ts.next = ReplaceScreen(CONTINUE)
+		}))
+		startY += buttonHeight + 20
+	}
+
+	ts.buttons = append(ts.buttons,
 		NewButton(startX, startY, buttonWidth, buttonHeight, "Play", func() {
 			ts.soundManager.RequestSound("menu_select", 1.0, 1.0)
-			ts.nextScreen = GAME
+			ts.next = ReplaceScreen(GAME)
 		}),
 		NewButton(startX, startY+buttonHeight+20, buttonWidth, buttonHeight, "Quit", func() {
 			ts.soundManager.RequestSound("menu_select", 1.0, 1.0)
-			rl.CloseWindow()
+			ts.next = QuitGame()
 		}),
-	}
+	)
 
 	// Start title screen music
 	ts.soundManager.RequestMusic("title_theme", true)
 }
 
-func (ts *TitleScreen) Update(deltaTime float32) bool {
+func (ts *TitleScreen) Update(deltaTime float32) Transition {
 	// Update demo scene
 	ts.updateDemoScene(deltaTime)
 
@@ -122,11 +139,11 @@ func (ts *TitleScreen) Update(deltaTime float32) bool {
 		button.Update()
 	}
 
-	if ts.nextScreen == GAME {
+	if ts.next.Op == REPLACE {
 		ts.soundManager.RequestMusic("dungeon_theme", true)
 	}
 
-	return ts.nextScreen == GAME
+	return ts.next
 }
 
 func (ts *TitleScreen) updateDemoScene(deltaTime float32) {
@@ -139,8 +156,8 @@ func (ts *TitleScreen) updateDemoScene(deltaTime float32) {
 		// Generate random target position
 		targetX, targetY := startX, startY
 		for attempts := 0; attempts < 100; attempts++ {
-			targetX = rand.Intn(helpers.MAP_WIDTH)
-			targetY = rand.Intn(helpers.MAP_HEIGHT)
+			targetX = rand.Intn(ts.demoWorld.Map.Width())
+			targetY = rand.Intn(ts.demoWorld.Map.Height())
 			if ts.demoWorld.Map.IsWalkable(targetX, targetY) {
 				break
 			}
@@ -205,6 +222,10 @@ func (ts *TitleScreen) Type() ScreenType {
 	return TITLE
 }
 
+func (ts *TitleScreen) IsOverlay() bool {
+	return false
+}
+
 func (ts *TitleScreen) Render() {
 	// Draw demo scene
 	rl.BeginMode2D(ts.demoCamera)
@@ -279,6 +300,20 @@ func (ts *TitleScreen) Render() {
 	for _, button := range ts.buttons {
 		button.Render()
 	}
+
+	if ts.saveError != "" && len(ts.buttons) > 0 {
+		text := "Can't continue the saved run: " + ts.saveError
+		width := rl.MeasureText(text, 20)
+		last := ts.buttons[len(ts.buttons)-1].Bounds
+		rl.DrawText(text, int32(float32(rl.GetScreenWidth()-int(width))/2), int32(last.Y+last.Height)+20, 20, rl.Red)
+	}
+}
+
+// ShowSaveError tells the player why the saved run couldn't be loaded and
+// stops offering to continue it.
+func (ts *TitleScreen) ShowSaveError(err error) {
+	ts.saveError = err.Error()
+	ts.Init()
 }
 
 func (ts *TitleScreen) Unload() {
@@ -8,7 +8,6 @@ import (
 
 type VictoryScreen struct {
 	soundManager *audio.SoundManager
-	nextScreen   ScreenType
 	timer        float32
 	fadeAlpha    float32
 }
@@ -16,7 +15,6 @@ type VictoryScreen struct {
 func NewVictoryScreen(soundManager *audio.SoundManager) *VictoryScreen {
 	vs := &VictoryScreen{
 		soundManager: soundManager,
-		nextScreen:   VICTORY,
 		timer:        0,
 		fadeAlpha:    0,
 	}
@@ -33,7 +31,7 @@ func (vs *VictoryScreen) Init() {
 	vs.soundManager.RequestSound("victory", 1.0, 1.0)
 }
 
-func (vs *VictoryScreen) Update(deltaTime float32) bool {
+func (vs *VictoryScreen) Update(deltaTime float32) Transition {
 	vs.timer += deltaTime
 
 	// Fade in effect
@@ -46,9 +44,13 @@ func (vs *VictoryScreen) Update(deltaTime float32) bool {
 
 	// After 5 seconds, transition to outro screen
 	if vs.timer >= 5.0 {
-		return true
+		return ReplaceScreen(OUTRO)
 	}
 
+	return Stay()
+}
+
+func (vs *VictoryScreen) IsOverlay() bool {
 	return false
 }
 
@@ -0,0 +1,388 @@
+package defs
+
+import (
+	"crydes/events"
+	"crydes/helpers"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+)
+
+// DEFAULT_DIR holds one JSON file per definition, under enemies/, items/,
+// props/, wares/, weapons/ and bosses/.
+const DEFAULT_DIR = "data/defs"
+
+// DEFAULT_FRAME_TIME is used by animations that don't set frame_time.
+const DEFAULT_FRAME_TIME = 0.1
+
+// AnimationDef lists the frames of an animation.
+type AnimationDef struct {
+	Frames    []string `json:"frames"`
+	FrameTime float32  `json:"frame_time"` // Seconds per frame
+}
+
+// Load loads the frames of the animation, headless ones load no texture.
+func (a *AnimationDef) Load(id string, headless bool) *helpers.Animation {
+	anim := helpers.LoadAnimation(headless, id, a.Frames...)
+	anim.FrameTime = a.FrameTime
+	return anim
+}
+
+// EnemyDef describes an enemy type: its stats, behaviour and sprites.
+type EnemyDef struct {
+	Name        string `json:"name"`
+	SpawnWeight int    `json:"spawn_weight"` // Relative odds of spawning, 0 never spawns
+
+	Scale  float32 `json:"scale"`
+	Speed  float32 `json:"speed"` // Multiplied by ENEMIES_MOV_SPEED
+	Health int     `json:"health"`
+
+	SightRange float32 `json:"sight_range"` // Distance in pixels at which the player is noticed
+	Erratic    float32 `json:"erratic"`     // 0 walks straight, 1 zigzags up to 90 degrees off course
+	FleeHealth int     `json:"flee_health"` // Runs away once health drops to this, 0 never flees
+
+	Damage          int     `json:"damage"`           // Hearts a hit or projectile takes
+	Reach           float32 `json:"reach"`            // Melee attackers hit from this close
+	AttackRange     float32 `json:"attack_range"`     // Ranged attackers throw from this far, 0 for melee
+	AttackCooldown  float32 `json:"attack_cooldown"`  // Seconds between two attacks
+	ProjectileSpeed float32 `json:"projectile_speed"` // Pixels per second of thrown projectiles
+	StunDuration    float32 `json:"stun_duration"`    // Seconds spent stunned after a hit
+
+	CoinDrop float32    `json:"coin_drop"`        // Odds of dropping a coin when killed
+	XP       int        `json:"xp"`               // Experience the player gains for the kill
+	Effect   *EffectDef `json:"effect,omitempty"` // Status effect its hits inflict
+
+	Animations map[string]*AnimationDef `json:"animations"`
+}
+
+// ENEMY_ANIMATIONS are the animations every enemy must define.
+var ENEMY_ANIMATIONS = []string{"idle_right", "idle_left", "move_right", "move_left", "death_right", "death_left"}
+
+// EffectDef is what collecting an item does to the player, or the status
+// effect a hit inflicts.
+type EffectDef struct {
+	Type     string  `json:"type"`
+	Value    float32 `json:"value"`
+	Duration float32 `json:"duration"` // Seconds, 0 for instant effects
+}
+
+// Status returns the status effect a hit inflicts, none for a nil effect.
+func (e *EffectDef) Status() events.Status {
+	if e == nil {
+		return events.Status{}
+	}
+	return events.Status{Type: e.Type, Value: e.Value, Duration: e.Duration}
+}
+
+// EFFECT_TYPES are the effects the player knows how to apply, besides the
+// status effects.
+var EFFECT_TYPES = []string{"heal", "key", "door_key", "coin", "max_health", "key_hint", "weapon"}
+
+// ItemDef describes a collectible item.
+type ItemDef struct {
+	Name        string        `json:"name"`
+	SpawnWeight int           `json:"spawn_weight"` // Relative odds of being scattered in rooms
+	DepthWeight int           `json:"depth_weight"` // Added to spawn_weight on every floor below the first
+	Stored      bool          `json:"stored"`       // Kept in the hotbar until used instead of applied on pickup
+	Effect      EffectDef     `json:"effect"`
+	Animation   *AnimationDef `json:"animation"`
+}
+
+// PropDef describes a decorative prop and the light it gives off.
+type PropDef struct {
+	Name        string        `json:"name"`
+	Scale       float32       `json:"scale"`
+	LightRadius float32       `json:"light_radius"` // 0 for props that give off no light
+	Size        [2]float32    `json:"size"`         // Width and height in pixels
+	Animation   *AnimationDef `json:"animation"`
+}
+
+// WareDef describes something merchants sell, the item it hands over
+// when bought.
+type WareDef struct {
+	Name        string `json:"name"`
+	Item        string `json:"item"`         // Name of the item definition
+	Price       int    `json:"price"`        // Coins it costs
+	Stock       int    `json:"stock"`        // How many a merchant has on sale
+	SpawnWeight int    `json:"spawn_weight"` // Relative odds of a merchant selling it
+}
+
+// WeaponDef describes a weapon the player can wield. Melee weapons hit in
+// front of the player, projectiles fly straight until they hit an enemy or
+// a wall, thrown weapons land and blow up after their fuse.
+type WeaponDef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"` // One of WEAPON_KINDS
+
+	Damage         int     `json:"damage"`
+	CritChance     float32 `json:"crit_chance"`     // Odds a hit is critical, from 0 to 1
+	CritMultiplier float32 `json:"crit_multiplier"` // Damage of critical hits, 0 for the default
+	Cooldown       float32 `json:"cooldown"`        // Seconds between two attacks
+	Knockback      float32 `json:"knockback"`       // Pixels enemies hit are pushed back
+	Speed          float32 `json:"speed"`           // Pixels per second of projectiles and thrown weapons
+	Range          float32 `json:"range"`           // Pixels a projectile flies or a thrown weapon travels
+	Radius         float32 `json:"radius"`          // Pixels around a thrown weapon hurt when it blows up
+	Fuse           float32 `json:"fuse"`            // Seconds before a thrown weapon blows up
+	Ammo           int     `json:"ammo"`            // Most uses carried, 0 for unlimited
+
+	Sound     string        `json:"sound"`            // Played on attack
+	HitSound  string        `json:"hit_sound"`        // Played when a thrown weapon goes off, optional
+	Icon      string        `json:"icon"`             // Shown on the HUD
+	Animation *AnimationDef `json:"animation"`        // The swing of melee weapons, the shot of the others
+	Effect    *EffectDef    `json:"effect,omitempty"` // Status effect its hits inflict
+}
+
+// WEAPON_KINDS are the kinds of weapons the player knows how to use.
+var WEAPON_KINDS = []string{"melee", "projectile", "thrown"}
+
+// BossDef describes a boss guarding the way out: its stats, the attacks it
+// uses, the phases of the fight and its sprites.
+type BossDef struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`        // Shown over its health bar
+	SpawnWeight int    `json:"spawn_weight"` // Relative odds of guarding the way out
+	Music       string `json:"music"`        // Track played during the fight
+
+	Health int        `json:"health"`
+	Scale  float32    `json:"scale"`
+	Size   [2]float32 `json:"size"`   // Width and height of a frame in pixels
+	Speed  float32    `json:"speed"`  // Pixels per second while walking
+	Damage int        `json:"damage"` // Hearts its touch, charges and slams take
+
+	Windup      float32 `json:"windup"`       // Seconds an attack is telegraphed
+	ChargeSpeed float32 `json:"charge_speed"` // Pixels per second of a charge
+	ChargeTime  float32 `json:"charge_time"`  // Seconds a charge lasts at most
+	SlamRadius  float32 `json:"slam_radius"`  // Pixels around it a slam hurts
+	Summon      string  `json:"summon"`       // Enemy type it summons
+	SummonCount int     `json:"summon_count"` // Enemies summoned at once
+
+	Effect     *EffectDef               `json:"effect,omitempty"` // Status effect its hits inflict
+	Phases     []BossPhaseDef           `json:"phases"`           // In order, the first starting at full health
+	Animations map[string]*AnimationDef `json:"animations"`
+}
+
+// BossPhaseDef is a stage of a boss fight, it starts once the boss's
+// health drops to Health of its maximum.
+type BossPhaseDef struct {
+	Health   float32  `json:"health"`   // Fraction of health the phase starts at, 1 for the first
+	Speed    float32  `json:"speed"`    // Multiplies the boss's speed
+	Cooldown float32  `json:"cooldown"` // Seconds between two attacks
+	Attacks  []string `json:"attacks"`  // Picked from at random, of BOSS_ATTACKS
+}
+
+// BOSS_ATTACKS are the attacks bosses know how to make.
+var BOSS_ATTACKS = []string{"summon", "charge", "slam"}
+
+// Definitions the game refers to by name, every other one is optional
+var (
+	REQUIRED_ITEMS   = []string{"key", "small_key", "red_key", "blue_key", "green_key", "coin"}
+	REQUIRED_PROPS   = []string{"fireplace", "torch", "stairs_down", "stairs_up"}
+	REQUIRED_WEAPONS = []string{"sword"} // Every player starts with it
+)
+
+// Registry holds every loaded definition by name.
+type Registry struct {
+	Enemies map[string]*EnemyDef
+	Items   map[string]*ItemDef
+	Props   map[string]*PropDef
+	Wares   map[string]*WareDef
+	Weapons map[string]*WeaponDef
+	Bosses  map[string]*BossDef
+}
+
+var loaded *Registry
+
+// Load reads every definition under dir and makes it the current registry.
+// All validation errors are returned together.
+func Load(dir string) (*Registry, error) {
+	r := &Registry{
+		Enemies: map[string]*EnemyDef{},
+		Items:   map[string]*ItemDef{},
+		Props:   map[string]*PropDef{},
+		Wares:   map[string]*WareDef{},
+		Weapons: map[string]*WeaponDef{},
+		Bosses:  map[string]*BossDef{},
+	}
+
+	var errs []error
+	errs = append(errs, loadDir(dir, "enemies",
+		func() definition { return &EnemyDef{} },
+		func(d definition) { r.Enemies[d.defName()] = d.(*EnemyDef) })...)
+	errs = append(errs, loadDir(dir, "items",
+		func() definition { return &ItemDef{} },
+		func(d definition) { r.Items[d.defName()] = d.(*ItemDef) })...)
+	errs = append(errs, loadDir(dir, "props",
+		func() definition { return &PropDef{} },
+		func(d definition) { r.Props[d.defName()] = d.(*PropDef) })...)
+	errs = append(errs, loadDir(dir, "wares",
+		func() definition { return &WareDef{} },
+		func(d definition) { r.Wares[d.defName()] = d.(*WareDef) })...)
+	errs = append(errs, loadDir(dir, "weapons",
+		func() definition { return &WeaponDef{} },
+		func(d definition) { r.Weapons[d.defName()] = d.(*WeaponDef) })...)
+	errs = append(errs, loadDir(dir, "bosses",
+		func() definition { return &BossDef{} },
+		func(d definition) { r.Bosses[d.defName()] = d.(*BossDef) })...)
+
+	// The game looks these up by name
+	for _, name := range REQUIRED_ITEMS {
+		if len(errs) > 0 {
+			break // Probably defined, just invalid
+		}
+		if _, exists := r.Items[name]; !exists {
+			errs = append(errs, &ValidationError{File: filepath.Join(dir, "items", name+".json"), Message: "required definition is missing"})
+		}
+	}
+	for _, name := range REQUIRED_PROPS {
+		if len(errs) > 0 {
+			break
+		}
+		if _, exists := r.Props[name]; !exists {
+			errs = append(errs, &ValidationError{File: filepath.Join(dir, "props", name+".json"), Message: "required definition is missing"})
+		}
+	}
+	for _, name := range REQUIRED_WEAPONS {
+		if len(errs) > 0 {
+			break
+		}
+		if _, exists := r.Weapons[name]; !exists {
+			errs = append(errs, &ValidationError{File: filepath.Join(dir, "weapons", name+".json"), Message: "required definition is missing"})
+		}
+	}
+
+	// Weapon pickups hand over the weapon they're named after
+	if len(errs) == 0 {
+		names := make([]string, 0, len(r.Items))
+		for name := range r.Items {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if r.Items[name].Effect.Type == "weapon" && r.Weapons[name] == nil {
+				errs = append(errs, &ValidationError{File: filepath.Join(dir, "items", name+".json"), Field: "name", Message: fmt.Sprintf("no weapon named %q", name)})
+			}
+		}
+	}
+
+	// Bosses summon enemies, which must exist
+	if len(errs) == 0 {
+		names := make([]string, 0, len(r.Bosses))
+		for name := range r.Bosses {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if summon := r.Bosses[name].Summon; summon != "" && r.Enemies[summon] == nil {
+				errs = append(errs, &ValidationError{File: filepath.Join(dir, "bosses", name+".json"), Field: "summon", Message: fmt.Sprintf("unknown enemy %q", summon)})
+			}
+		}
+	}
+
+	// Wares hand over items, which must exist
+	if len(errs) == 0 {
+		names := make([]string, 0, len(r.Wares))
+		for name := range r.Wares {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if item := r.Wares[name].Item; r.Items[item] == nil {
+				errs = append(errs, &ValidationError{File: filepath.Join(dir, "wares", name+".json"), Field: "item", Message: fmt.Sprintf("unknown item %q", item)})
+			}
+		}
+	}
+
+	if len(errs) == 0 && weighted(r.Enemies, func(d *EnemyDef) int { return d.SpawnWeight }, nil) == "" {
+		errs = append(errs, &ValidationError{File: filepath.Join(dir, "enemies"), Field: "spawn_weight", Message: "no enemy can spawn"})
+	}
+	if len(errs) == 0 && weighted(r.Items, func(d *ItemDef) int { return d.SpawnWeight }, nil) == "" {
+		errs = append(errs, &ValidationError{File: filepath.Join(dir, "items"), Field: "spawn_weight", Message: "no item can spawn"})
+	}
+	if len(errs) == 0 && weighted(r.Bosses, func(d *BossDef) int { return d.SpawnWeight }, nil) == "" {
+		errs = append(errs, &ValidationError{File: filepath.Join(dir, "bosses"), Field: "spawn_weight", Message: "no boss can spawn"})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	loaded = r
+	return r, nil
+}
+
+// Get returns the current registry, loading DEFAULT_DIR the first time.
+// It panics on invalid definitions, call Load at startup to report them.
+func Get() *Registry {
+	if loaded == nil {
+		if _, err := Load(DEFAULT_DIR); err != nil {
+			panic(err)
+		}
+	}
+	return loaded
+}
+
+// RandomEnemy picks an enemy type by spawn weight.
+func (r *Registry) RandomEnemy(rng *rand.Rand) *EnemyDef {
+	name := weighted(r.Enemies, func(d *EnemyDef) int { return d.SpawnWeight }, rng)
+	return r.Enemies[name]
+}
+
+// RandomBoss picks a boss by spawn weight.
+func (r *Registry) RandomBoss(rng *rand.Rand) *BossDef {
+	return r.Bosses[weighted(r.Bosses, func(d *BossDef) int { return d.SpawnWeight }, rng)]
+}
+
+// RandomWare picks a ware by spawn weight, nil when none can be sold.
+func (r *Registry) RandomWare(rng *rand.Rand) *WareDef {
+	return r.Wares[weighted(r.Wares, func(d *WareDef) int { return d.SpawnWeight }, rng)]
+}
+
+// RandomItem picks an item type by its spawn weight on the floor at depth.
+func (r *Registry) RandomItem(rng *rand.Rand, depth int) *ItemDef {
+	name := weighted(r.Items, func(d *ItemDef) int { return d.WeightAt(depth) }, rng)
+	if name == "" {
+		// Deep floors may have weighted every item out, fall back to the first floor
+		name = weighted(r.Items, func(d *ItemDef) int { return d.SpawnWeight }, rng)
+	}
+	return r.Items[name]
+}
+
+// WeightAt returns the spawn weight of the item on the floor at depth, 1
+// being the first floor. It never goes below 0.
+func (d *ItemDef) WeightAt(depth int) int {
+	weight := d.SpawnWeight + d.DepthWeight*(depth-1)
+	if weight < 0 {
+		return 0
+	}
+	return weight
+}
+
+// weighted picks a name by weight, or returns "" when every weight is 0.
+// Names are walked in sorted order so the same rng always gives the same
+// pick. A nil rng picks the first name that can be picked.
+func weighted[T any](defs map[string]T, weight func(T) int, rng *rand.Rand) string {
+	names := make([]string, 0, len(defs))
+	total := 0
+	for name, d := range defs {
+		names = append(names, name)
+		total += weight(d)
+	}
+	sort.Strings(names)
+
+	if total == 0 {
+		return ""
+	}
+
+	pick := 0
+	if rng != nil {
+		pick = rng.Intn(total)
+	}
+	for _, name := range names {
+		pick -= weight(defs[name])
+		if pick < 0 {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,333 @@
+package defs
+
+import (
+	"bytes"
+	"crydes/status"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ValidationError points at the file and field of a bad definition.
+type ValidationError struct {
+	File    string
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.File, e.Field, e.Message)
+}
+
+// reporter records a problem with a field of the definition being loaded
+type reporter func(field, format string, args ...interface{})
+
+// definition is implemented by every kind of definition.
+type definition interface {
+	defName() string
+	validate(report reporter)
+}
+
+func (d *EnemyDef) defName() string  { return d.Name }
+func (d *ItemDef) defName() string   { return d.Name }
+func (d *PropDef) defName() string   { return d.Name }
+func (d *WareDef) defName() string   { return d.Name }
+func (d *WeaponDef) defName() string { return d.Name }
+func (d *BossDef) defName() string   { return d.Name }
+
+// loadDir decodes every .json file of dir/kind with newDef and hands the
+// valid ones to add.
+func loadDir(dir, kind string, newDef func() definition, add func(definition)) []error {
+	files, err := filepath.Glob(filepath.Join(dir, kind, "*.json"))
+	if err != nil {
+		return []error{err}
+	}
+	if len(files) == 0 {
+		return []error{&ValidationError{File: filepath.Join(dir, kind), Message: "no definitions found"}}
+	}
+	sort.Strings(files)
+
+	var errs []error
+	seen := map[string]string{}
+	for _, file := range files {
+		report := func(field, format string, args ...interface{}) {
+			errs = append(errs, &ValidationError{File: file, Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			report("", "%v", err)
+			continue
+		}
+
+		def := newDef()
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields() // Catch typos in field names
+		if err := decoder.Decode(def); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				report(typeErr.Field, "expected %s, got %s", typeErr.Type, typeErr.Value)
+			} else if field, unknown := strings.CutPrefix(err.Error(), "json: unknown field "); unknown {
+				report(strings.Trim(field, `"`), "unknown field")
+			} else {
+				report("", "%v", err)
+			}
+			continue
+		}
+
+		name := def.defName()
+		if name == "" {
+			report("name", "is required")
+			continue
+		}
+		if other, exists := seen[name]; exists {
+			report("name", "%q is already defined in %s", name, other)
+			continue
+		}
+		seen[name] = file
+
+		before := len(errs)
+		def.validate(report)
+		if len(errs) == before {
+			add(def)
+		}
+	}
+	return errs
+}
+
+func (d *EnemyDef) validate(report reporter) {
+	validateInflicted(report, d.Effect)
+	positive(report, "scale", d.Scale)
+	positive(report, "speed", d.Speed)
+	positive(report, "sight_range", d.SightRange)
+	positive(report, "stun_duration", d.StunDuration)
+	notNegative(report, "attack_cooldown", d.AttackCooldown)
+	notNegative(report, "attack_range", d.AttackRange)
+	notNegative(report, "spawn_weight", float32(d.SpawnWeight))
+
+	if d.Health <= 0 {
+		report("health", "must be positive, got %d", d.Health)
+	}
+	if d.Damage <= 0 {
+		report("damage", "must be positive, got %d", d.Damage)
+	}
+	if d.XP < 0 {
+		report("xp", "must not be negative, got %d", d.XP)
+	}
+	if d.CoinDrop < 0 || d.CoinDrop > 1 {
+		report("coin_drop", "must be between 0 and 1, got %v", d.CoinDrop)
+	}
+	if d.Erratic < 0 || d.Erratic > 1 {
+		report("erratic", "must be between 0 and 1, got %v", d.Erratic)
+	}
+	if d.FleeHealth < 0 || (d.Health > 0 && d.FleeHealth >= d.Health) {
+		report("flee_health", "must be between 0 and health (%d), got %d", d.Health, d.FleeHealth)
+	}
+	if d.AttackRange > 0 {
+		positive(report, "projectile_speed", d.ProjectileSpeed)
+	} else {
+		positive(report, "reach", d.Reach)
+	}
+
+	for _, name := range ENEMY_ANIMATIONS {
+		anim, exists := d.Animations[name]
+		if !exists {
+			report("animations."+name, "is required")
+			continue
+		}
+		validateAnimation(report, "animations."+name, anim)
+	}
+}
+
+func (d *ItemDef) validate(report reporter) {
+	notNegative(report, "spawn_weight", float32(d.SpawnWeight))
+	notNegative(report, "effect.duration", d.Effect.Duration)
+
+	if status.KINDS[d.Effect.Type] != nil {
+		positive(report, "effect.duration", d.Effect.Duration)
+	} else {
+		known := false
+		for _, t := range EFFECT_TYPES {
+			known = known || t == d.Effect.Type
+		}
+		if !known {
+			report("effect.type", "unknown effect %q, expected one of %v or %v", d.Effect.Type, EFFECT_TYPES, status.ORDER)
+		}
+	}
+
+	validateAnimation(report, "animation", d.Animation)
+}
+
+func (d *WareDef) validate(report reporter) {
+	notNegative(report, "price", float32(d.Price))
+	notNegative(report, "spawn_weight", float32(d.SpawnWeight))
+	if d.Item == "" {
+		report("item", "is required")
+	}
+	if d.Stock <= 0 {
+		report("stock", "must be positive, got %d", d.Stock)
+	}
+}
+
+func (d *WeaponDef) validate(report reporter) {
+	validateInflicted(report, d.Effect)
+	positive(report, "damage", float32(d.Damage))
+	notNegative(report, "crit_multiplier", d.CritMultiplier)
+	notNegative(report, "cooldown", d.Cooldown)
+	notNegative(report, "knockback", d.Knockback)
+	notNegative(report, "ammo", float32(d.Ammo))
+	if d.CritChance < 0 || d.CritChance > 1 {
+		report("crit_chance", "must be between 0 and 1, got %v", d.CritChance)
+	}
+
+	switch d.Kind {
+	case "melee":
+	case "projectile":
+		positive(report, "speed", d.Speed)
+		positive(report, "range", d.Range)
+	case "thrown":
+		positive(report, "speed", d.Speed)
+		notNegative(report, "range", d.Range)
+		positive(report, "radius", d.Radius)
+		notNegative(report, "fuse", d.Fuse)
+	default:
+		report("kind", "unknown kind %q, expected one of %v", d.Kind, WEAPON_KINDS)
+	}
+
+	if d.Sound == "" {
+		report("sound", "is required")
+	}
+	if d.Icon == "" {
+		report("icon", "is required")
+	} else if _, err := os.Stat(d.Icon); err != nil {
+		report("icon", "%s not found", d.Icon)
+	}
+	validateAnimation(report, "animation", d.Animation)
+}
+
+func (d *BossDef) validate(report reporter) {
+	validateInflicted(report, d.Effect)
+	notNegative(report, "spawn_weight", float32(d.SpawnWeight))
+	positive(report, "health", float32(d.Health))
+	positive(report, "scale", d.Scale)
+	positive(report, "size[0]", d.Size[0])
+	positive(report, "size[1]", d.Size[1])
+	positive(report, "speed", d.Speed)
+	positive(report, "damage", float32(d.Damage))
+	notNegative(report, "windup", d.Windup)
+	if d.Title == "" {
+		report("title", "is required")
+	}
+	if d.Music == "" {
+		report("music", "is required")
+	}
+
+	if len(d.Phases) == 0 {
+		report("phases", "needs at least one phase")
+	}
+	uses := map[string]bool{}
+	for i, phase := range d.Phases {
+		field := fmt.Sprintf("phases[%d]", i)
+		switch {
+		case i == 0 && phase.Health != 1:
+			report(field+".health", "the first phase must start at 1, got %v", phase.Health)
+		case i > 0 && (phase.Health <= 0 || phase.Health >= d.Phases[i-1].Health):
+			report(field+".health", "must be between 0 and the health of the phase before, got %v", phase.Health)
+		}
+		positive(report, field+".speed", phase.Speed)
+		notNegative(report, field+".cooldown", phase.Cooldown)
+		if len(phase.Attacks) == 0 {
+			report(field+".attacks", "needs at least one attack")
+		}
+		for j, attack := range phase.Attacks {
+			known := false
+			for _, a := range BOSS_ATTACKS {
+				known = known || a == attack
+			}
+			if !known {
+				report(fmt.Sprintf("%s.attacks[%d]", field, j), "unknown attack %q, expected one of %v", attack, BOSS_ATTACKS)
+			}
+			uses[attack] = true
+		}
+	}
+
+	if uses["charge"] {
+		positive(report, "charge_speed", d.ChargeSpeed)
+		positive(report, "charge_time", d.ChargeTime)
+	}
+	if uses["slam"] {
+		positive(report, "slam_radius", d.SlamRadius)
+	}
+	if uses["summon"] {
+		positive(report, "summon_count", float32(d.SummonCount))
+		if d.Summon == "" {
+			report("summon", "is required by the summon attack")
+		}
+	}
+
+	for _, name := range ENEMY_ANIMATIONS {
+		anim, exists := d.Animations[name]
+		if !exists {
+			report("animations."+name, "is required")
+			continue
+		}
+		validateAnimation(report, "animations."+name, anim)
+	}
+}
+
+// validateInflicted checks the status effect a hit inflicts, if any.
+func validateInflicted(report reporter, effect *EffectDef) {
+	if effect == nil {
+		return
+	}
+	if status.KINDS[effect.Type] == nil {
+		report("effect.type", "unknown status effect %q, expected one of %v", effect.Type, status.ORDER)
+	}
+	positive(report, "effect.duration", effect.Duration)
+}
+
+func (d *PropDef) validate(report reporter) {
+	positive(report, "scale", d.Scale)
+	notNegative(report, "light_radius", d.LightRadius)
+	positive(report, "size[0]", d.Size[0])
+	positive(report, "size[1]", d.Size[1])
+
+	validateAnimation(report, "animation", d.Animation)
+}
+
+func validateAnimation(report reporter, field string, a *AnimationDef) {
+	if a == nil || len(a.Frames) == 0 {
+		report(field+".frames", "needs at least one frame")
+		return
+	}
+
+	notNegative(report, field+".frame_time", a.FrameTime)
+	if a.FrameTime == 0 {
+		a.FrameTime = DEFAULT_FRAME_TIME
+	}
+
+	for i, frame := range a.Frames {
+		if _, err := os.Stat(frame); err != nil {
+			report(fmt.Sprintf("%s.frames[%d]", field, i), "%s not found", frame)
+		}
+	}
+}
+
+func positive(report reporter, field string, v float32) {
+	if v <= 0 {
+		report(field, "must be positive, got %v", v)
+	}
+}
+
+func notNegative(report reporter, field string, v float32) {
+	if v < 0 {
+		report(field, "can't be negative, got %v", v)
+	}
+}
@@ -0,0 +1,91 @@
+package defs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the tests from the root of the repository, where the
+// definitions and their assets are.
+func TestMain(m *testing.M) {
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// copyDefs copies the game's definitions into a temporary directory the
+// test can break.
+func copyDefs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := filepath.WalkDir(DEFAULT_DIR, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(DEFAULT_DIR, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadReportsFileAndField(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string // Definition the bad one is made from
+		to       string // Where the bad definition is written
+		old, new string // Replaced in the definition to break it
+		field    string
+	}{
+		{"unknown field", "enemies/spider.json", "enemies/spider.json", `"erratic"`, `"erratik"`, "erratik"},
+		{"wrong type", "enemies/spider.json", "enemies/spider.json", `"health": 2`, `"health": "two"`, "health"},
+		{"duplicate name", "enemies/spider.json", "enemies/spider_twin.json", "", "", "name"},
+		{"out of range", "enemies/spider.json", "enemies/spider.json", `"erratic": 0.8`, `"erratic": 3`, "erratic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := copyDefs(t)
+			data, err := os.ReadFile(filepath.Join(dir, tt.from))
+			if err != nil {
+				t.Fatal(err)
+			}
+			bad := strings.Replace(string(data), tt.old, tt.new, 1)
+			if bad == string(data) && tt.old != "" {
+				t.Fatalf("%s holds no %s to replace", tt.from, tt.old)
+			}
+			file := filepath.Join(dir, tt.to)
+			if err := os.WriteFile(file, []byte(bad), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Load(dir)
+			if err == nil {
+				t.Fatal("loaded a bad definition")
+			}
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				var invalid *ValidationError
+				if errors.As(e, &invalid) && invalid.File == file && invalid.Field == tt.field {
+					return
+				}
+			}
+			t.Errorf("no error for %s field %q, got:\n%v", file, tt.field, err)
+		})
+	}
+}
@@ -25,6 +25,10 @@ func (ls LightSource) Position() rl.Vector2 {
 }
 
 func (ls LightSource) Radius() float32 {
+	if ls.isPlayer {
+		return ls.player.LightRadius()
+	}
+
 	return ls.radius
 }
 func (ls *LightSource) SetMode(mode string) {
@@ -190,6 +194,17 @@ func (rle *RetroLightingEffect) Render() {
 	rl.EndBlendMode()
 }
 
+// Resize fits the light mask to a map of another size, it does nothing
+// when the size didn't change.
+func (rle *RetroLightingEffect) Resize(width, height int32) {
+	if rle.lightMask.Texture.Width == width && rle.lightMask.Texture.Height == height {
+		return
+	}
+	rl.UnloadRenderTexture(rle.lightMask)
+	rle.lightMask = rl.LoadRenderTexture(width, height)
+	rle.noiseMap = generateNoiseMap(int(width/rle.pixelSize), int(height/rle.pixelSize))
+}
+
 func (rle *RetroLightingEffect) Unload() {
 	rl.UnloadRenderTexture(rle.lightMask)
 }
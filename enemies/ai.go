@@ -0,0 +1,255 @@
+package enemies
+
+import (
+	"crydes/events"
+	"crydes/helpers"
+	"crydes/player"
+	"math"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// AIState is what an enemy is currently doing
+type AIState int
+
+const (
+	IDLE    AIState = iota // Standing still, waiting to patrol
+	PATROL                 // Walking to a random point of its room
+	CHASE                  // Following the flow field to the player
+	ATTACK                 // Hitting or throwing at the player
+	FLEE                   // Running away from the player
+	STUNNED                // Recovering from a hit
+	DEAD                   // Playing its death animation
+)
+
+func (s AIState) String() string {
+	return [...]string{"idle", "patrol", "chase", "attack", "flee", "stunned", "dead"}[s]
+}
+
+func (e *Enemy) setState(s AIState) {
+	if e.State == s {
+		return
+	}
+	e.State = s
+	e.stateTimer = 0
+}
+
+// Updates the enemy's state based on its interactions with the player.
+func (e *Enemy) Update(refreshRate float32, p *player.Player) {
+	e.particles.Update(refreshRate)
+
+	if e.isDead {
+		return
+	}
+
+	// Handle enemy death state and animation
+	if e.ShouldDie() {
+		e.setState(DEAD)
+		e.TriggerDeath()
+		e.UpdateAnimation(refreshRate)
+		return
+	}
+
+	e.stateTimer += refreshRate
+	e.attackTimer.Update(refreshRate)
+
+	switch e.State {
+	case IDLE:
+		e.updateIdle(p)
+	case PATROL:
+		e.updatePatrol(refreshRate, p)
+	case CHASE:
+		e.updateChase(refreshRate, p)
+	case ATTACK:
+		e.updateAttack(p)
+	case FLEE:
+		e.updateFlee(refreshRate, p)
+	case STUNNED:
+		e.SetIdleAnimation()
+		if e.stateTimer >= e.Archetype.StunDuration {
+			e.setState(CHASE)
+		}
+	}
+
+	e.UpdateAnimation(refreshRate)
+}
+
+// notices reports whether the player is close enough to be seen.
+func (e *Enemy) notices(p *player.Player) bool {
+	return helpers.GetDistance(e.Position, p.Position) < e.Archetype.SightRange && !p.Effects.Has("invisibility")
+}
+
+// wounded reports whether the enemy should run for its life.
+func (e *Enemy) wounded() bool {
+	return e.Health <= e.Archetype.FleeHealth
+}
+
+func (e *Enemy) updateIdle(p *player.Player) {
+	e.SetIdleAnimation()
+
+	if e.notices(p) {
+		e.setState(CHASE)
+		return
+	}
+
+	if e.stateTimer >= helpers.ENEMIES_WANDER_PAUSE && e.pickWanderTarget() {
+		e.setState(PATROL)
+	}
+}
+
+// pickWanderTarget chooses a random point of the enemy's room. Enemies
+// outside of any room have nowhere to patrol.
+func (e *Enemy) pickWanderTarget() bool {
+	if e.mp == nil {
+		return false
+	}
+
+	if room := e.mp.CurrentRoomIndex(e.Feet()); room != -1 {
+		e.CurrentRoom = room
+	}
+	rooms := *e.mp.GetRooms()
+	if e.CurrentRoom < 0 || e.CurrentRoom >= len(rooms) {
+		return false
+	}
+
+	e.wanderTarget = rooms[e.CurrentRoom].GetRandomPosInRect(e.mp.Rand())
+	e.wanderTarget.X += helpers.TILE_SIZE / 2
+	e.wanderTarget.Y += helpers.TILE_SIZE / 2
+	return true
+}
+
+func (e *Enemy) updatePatrol(refreshRate float32, p *player.Player) {
+	if e.notices(p) {
+		e.setState(CHASE)
+		return
+	}
+
+	feet := e.Feet()
+	deltaX, deltaY := e.wanderTarget.X-feet.X, e.wanderTarget.Y-feet.Y
+
+	// Give up on targets that were reached or that a wall keeps us from
+	if deltaX*deltaX+deltaY*deltaY < 4 || e.stateTimer > helpers.ENEMIES_WANDER_PAUSE*4 {
+		e.setState(IDLE)
+		return
+	}
+
+	// Stroll at half the chasing speed
+	moveX, moveY := e.CalculateMovement(deltaX, deltaY, refreshRate)
+	e.Move(moveX/2, moveY/2)
+}
+
+// updateChase moves the enemy towards the player along the flow field.
+// Enemies that can't reach the player go back to patrolling their room.
+func (e *Enemy) updateChase(refreshRate float32, p *player.Player) {
+	if e.wounded() {
+		e.setState(FLEE)
+		return
+	}
+
+	var next rl.Vector2
+	reachable := false
+	if e.notices(p) && e.flowField != nil {
+		next, reachable = e.flowField.NextStep(e.Feet())
+	}
+	if !reachable {
+		e.setState(IDLE)
+		return
+	}
+
+	if e.inAttackRange(p) {
+		e.setState(ATTACK)
+		return
+	}
+
+	feet := e.Feet()
+	moveX, moveY := e.CalculateMovement(next.X-feet.X, next.Y-feet.Y, refreshRate)
+	moveX, moveY = e.jitter(refreshRate, moveX, moveY)
+	if e.Effects.Has("confusion") {
+		moveX, moveY = -moveY, moveX // Stumbles sideways instead of closing in
+	}
+	e.Move(moveX, moveY)
+}
+
+// inAttackRange reports whether the enemy can hit the player from where it
+// stands: on contact for melee enemies, in sight for ranged ones.
+func (e *Enemy) inAttackRange(p *player.Player) bool {
+	distance := helpers.GetDistance(e.Position, p.Position)
+	if e.Archetype.AttackRange == 0 {
+		return distance < e.Archetype.Reach
+	}
+
+	return distance < e.Archetype.AttackRange && e.mp != nil &&
+		e.mp.HasLineOfSight(e.Feet(), p.GetPlayerCenterPoint())
+}
+
+func (e *Enemy) updateAttack(p *player.Player) {
+	e.faceTowards(p.Position.X - e.Position.X)
+
+	if !e.attackTimer.Running() {
+		e.attackTimer.Start(e.Archetype.AttackCooldown)
+
+		if e.Archetype.AttackRange == 0 {
+			e.bus.Publish(events.DamageDealt{Target: events.TARGET_PLAYER, Amount: e.Archetype.Damage, Status: e.Archetype.Effect.Status()})
+			e.BounceBack(p.Position.X, p.Position.Y, helpers.ENEMIES_BOUNCE_BACK_DISTANCE)
+			e.setState(CHASE)
+			return
+		}
+
+		if e.throw != nil {
+			from := e.Feet()
+			direction := rl.Vector2Normalize(rl.Vector2Subtract(p.GetPlayerCenterPoint(), from))
+			e.throw(from, rl.Vector2Scale(direction, e.Archetype.ProjectileSpeed), e.Archetype.Damage, e.Archetype.Effect.Status())
+		}
+	}
+
+	if !e.inAttackRange(p) {
+		e.setState(CHASE)
+	}
+}
+
+// updateFlee runs straight away from the player until out of its sight.
+func (e *Enemy) updateFlee(refreshRate float32, p *player.Player) {
+	if !e.notices(p) {
+		e.setState(IDLE)
+		return
+	}
+
+	moveX, moveY := e.CalculateMovement(e.Position.X-p.Position.X, e.Position.Y-p.Position.Y, refreshRate)
+	e.Move(moveX, moveY)
+}
+
+// jitter bends the movement of erratic enemies off course, changing the
+// angle a few times per second so they zigzag instead of shaking.
+func (e *Enemy) jitter(refreshRate float32, moveX, moveY float32) (float32, float32) {
+	if e.Archetype.Erratic == 0 {
+		return moveX, moveY
+	}
+
+	e.jitterTimer -= refreshRate
+	if e.jitterTimer <= 0 {
+		e.jitterTimer = 0.25
+		e.jitterAngle = (e.randFloat()*2 - 1) * e.Archetype.Erratic * math.Pi / 2
+	}
+
+	sin, cos := math.Sincos(float64(e.jitterAngle))
+	return moveX*float32(cos) - moveY*float32(sin), moveX*float32(sin) + moveY*float32(cos)
+}
+
+// faceTowards turns the idle animation towards a horizontal offset.
+func (e *Enemy) faceTowards(deltaX float32) {
+	if deltaX > 0 {
+		e.LastDirection = "right"
+	} else if deltaX < 0 {
+		e.LastDirection = "left"
+	}
+	e.SetIdleAnimation()
+}
+
+// randFloat draws from the map's random source so runs stay reproducible.
+func (e *Enemy) randFloat() float32 {
+	if e.mp != nil {
+		return e.mp.Rand().Float32()
+	}
+	return rand.Float32()
+}
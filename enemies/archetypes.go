@@ -0,0 +1,13 @@
+package enemies
+
+import "crydes/defs"
+
+// GetArchetype returns the definition of an enemy type. Unknown types,
+// from saves made with other definitions, behave like the first enemy that
+// can spawn.
+func GetArchetype(eType string) *defs.EnemyDef {
+	if d, exists := defs.Get().Enemies[eType]; exists {
+		return d
+	}
+	return defs.Get().RandomEnemy(nil)
+}
@@ -0,0 +1,336 @@
+package enemies
+
+import (
+	"crydes/combat"
+	"crydes/defs"
+	"crydes/events"
+	"crydes/helpers"
+	"crydes/player"
+	"fmt"
+	"math"
+
+	ps "crydes/effects/particle"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	SLAM_DURATION  = 0.4 // Seconds the shockwave of a slam lasts
+	SUMMON_SPREAD  = 28  // Pixels from the boss its minions appear at
+	MAX_MINIONS    = 8   // Living minions past which the boss stops summoning
+	BOSS_BAR_WIDTH = 400 // Pixels across the boss health bar
+)
+
+// BossAction is what a boss is currently doing.
+type BossAction int
+
+const (
+	BOSS_WALK   BossAction = iota // Closing in on the player between attacks
+	BOSS_WINDUP                   // Telegraphing the attack it picked
+	BOSS_CHARGE                   // Rushing straight ahead
+	BOSS_SLAM                     // Its shockwave spreading
+	BOSS_DYING                    // Playing its death animation
+)
+
+func (a BossAction) String() string {
+	return [...]string{"walk", "windup", "charge", "slam", "dying"}[a]
+}
+
+// Boss is an enemy guarding the way out. It moves like an enemy but
+// fights in phases, each with its own speed and attacks.
+type Boss struct {
+	*Enemy
+	Def       *defs.BossDef
+	MaxHealth int
+	Phase     int // Index of the current phase in Def.Phases
+	Action    BossAction
+
+	attack      string       // Attack being wound up
+	actionTimer float32      // Seconds spent on the current action
+	cooldown    combat.Timer // Until the next attack
+	chargeDir   rl.Vector2   // Where a charge heads
+	defeated    bool         // BossDefeated was published
+
+	summon func(b *Boss) // Brings in the boss's minions
+}
+
+// newBoss creates a boss from its definition, standing at x, y.
+func (em *EnemiesManager) newBoss(def *defs.BossDef, x, y float32) *Boss {
+	animations := map[string]*helpers.Animation{}
+	for _, anim := range defs.ENEMY_ANIMATIONS {
+		animations[anim] = def.Animations[anim].Load(anim, em.Map.Headless())
+	}
+
+	b := &Boss{
+		Enemy: &Enemy{
+			Type:          def.Name,
+			Position:      rl.NewVector2(x, y),
+			Previous:      rl.NewVector2(x, y),
+			Size:          rl.NewVector2(def.Size[0], def.Size[1]),
+			Scale:         def.Scale,
+			Speed:         def.Speed,
+			Health:        def.Health,
+			Animations:    &animations,
+			CurrentAnim:   animations["idle_left"],
+			LastDirection: "left",
+			mp:            em.Map,
+			flowField:     em.flowField,
+			soundManager:  em.soundManager,
+			particles:     ps.NewParticleSystem(),
+			bus:           em.bus,
+		},
+		Def:       def,
+		MaxHealth: def.Health,
+		summon:    em.summonMinions,
+	}
+	b.cooldown.Start(def.Phases[0].Cooldown)
+	return b
+}
+
+// phase returns the definition of the phase the fight is in.
+func (b *Boss) phase() defs.BossPhaseDef {
+	return b.Def.Phases[b.Phase]
+}
+
+func (b *Boss) setAction(a BossAction) {
+	b.Action = a
+	b.actionTimer = 0
+}
+
+// Center returns the middle of the boss's sprite.
+func (b *Boss) Center() rl.Vector2 {
+	return rl.NewVector2(b.Position.X+b.Size.X*b.Scale/2, b.Position.Y+b.Size.Y*b.Scale/2)
+}
+
+// Update moves the boss through its attacks, hurting the player on
+// contact.
+func (b *Boss) Update(refreshRate float32, p *player.Player) {
+	b.particles.Update(refreshRate)
+	b.updateDamageFlash(refreshRate)
+	if b.isDead {
+		return
+	}
+	b.Effects.Update(b, refreshRate)
+
+	b.actionTimer += refreshRate
+	switch b.Action {
+	case BOSS_WALK:
+		b.cooldown.Update(refreshRate)
+		b.walk(refreshRate, p)
+		if !b.cooldown.Running() {
+			attacks := b.phase().Attacks
+			b.attack = attacks[b.mp.Rand().Intn(len(attacks))]
+			b.setAction(BOSS_WINDUP)
+		}
+	case BOSS_WINDUP:
+		b.faceTowards(p.Position.X - b.Position.X)
+		if b.actionTimer >= b.Def.Windup {
+			b.strike(p)
+		}
+	case BOSS_CHARGE:
+		from := b.Position
+		b.Move(b.chargeDir.X*b.Def.ChargeSpeed*refreshRate, b.chargeDir.Y*b.Def.ChargeSpeed*refreshRate)
+		if b.Position == from || b.actionTimer >= b.Def.ChargeTime {
+			b.recover()
+		}
+	case BOSS_SLAM:
+		if b.actionTimer >= SLAM_DURATION {
+			b.recover()
+		}
+	case BOSS_DYING:
+		b.UpdateAnimation(refreshRate)
+		return
+	}
+
+	if b.Action != BOSS_SLAM && rl.CheckCollisionPointRec(p.GetPlayerCenterPoint(), b.GetBounds()) {
+		b.bus.Publish(events.DamageDealt{Target: events.TARGET_PLAYER, Amount: b.Def.Damage, Status: b.Def.Effect.Status()})
+	}
+	b.UpdateAnimation(refreshRate)
+}
+
+// walk closes in on the player along the flow field, at the speed of the
+// current phase.
+func (b *Boss) walk(refreshRate float32, p *player.Player) {
+	feet := b.Feet()
+	next, reachable := b.flowField.NextStep(feet)
+	if !reachable {
+		next = p.GetPlayerCenterPoint()
+	}
+
+	delta := rl.Vector2Subtract(next, feet)
+	if rl.Vector2Length(delta) < 1 {
+		b.SetIdleAnimation()
+		return
+	}
+	speed := b.Def.Speed * b.phase().Speed * b.Effects.Multiplier("speed")
+	step := rl.Vector2Scale(rl.Vector2Normalize(delta), speed*refreshRate)
+	b.Move(step.X, step.Y)
+
+	if delta.X > 0 {
+		b.CurrentAnim, b.LastDirection = (*b.Animations)["move_right"], "right"
+	} else {
+		b.CurrentAnim, b.LastDirection = (*b.Animations)["move_left"], "left"
+	}
+}
+
+// strike makes the attack the boss wound up.
+func (b *Boss) strike(p *player.Player) {
+	switch b.attack {
+	case "summon":
+		b.summon(b)
+		b.soundManager.RequestSound("biwa", 0.6, 1.5)
+		b.recover()
+	case "charge":
+		b.chargeDir = rl.Vector2Normalize(rl.Vector2Subtract(p.GetPlayerCenterPoint(), b.Center()))
+		b.soundManager.RequestSound("sword_swing", 1.0, 0.6)
+		b.setAction(BOSS_CHARGE)
+	case "slam":
+		if helpers.Distance(b.Center(), p.GetPlayerCenterPoint()) <= b.Def.SlamRadius {
+			b.bus.Publish(events.DamageDealt{Target: events.TARGET_PLAYER, Amount: b.Def.Damage, Status: b.Def.Effect.Status()})
+		}
+		b.particles.EmitParticles(b.Feet(), 30, rl.Brown, "death")
+		b.soundManager.RequestSound("bomb", 1.0, 0.7)
+		b.setAction(BOSS_SLAM)
+	}
+}
+
+// recover goes back to walking until the next attack.
+func (b *Boss) recover() {
+	b.cooldown.Start(b.phase().Cooldown)
+	b.setAction(BOSS_WALK)
+}
+
+// TakeDamage hurts the boss when the hit overlaps it. Bosses stand their
+// ground, hits never push them back.
+func (b *Boss) TakeDamage(hit events.DamageDealt) {
+	if b.Action == BOSS_DYING || !helpers.CheckCollisionRecs(hit.Area, b.GetBounds()) {
+		return
+	}
+	if !combat.Hurt(&b.Health, hit.Amount, &b.invulnerable, combat.ENEMY_INVULNERABILITY) {
+		return
+	}
+	b.soundManager.RequestSound("sword_hit", 1.0, 0.8)
+	b.flash(hit.Amount, hit.Crit)
+	b.particles.EmitParticles(b.Center(), 10, rl.Red, "hit")
+	b.wounded()
+	if !b.ShouldDie() {
+		b.inflict(hit.Status)
+	}
+}
+
+// Hurt takes amount health from the boss for an effect running on it.
+func (b *Boss) Hurt(amount int) {
+	if b.Action == BOSS_DYING || amount <= 0 {
+		return
+	}
+	b.Health -= amount
+	b.flash(amount, false)
+	b.wounded()
+}
+
+// Heal gives the boss amount health back, up to its maximum.
+func (b *Boss) Heal(amount int) {
+	if b.Action != BOSS_DYING {
+		b.Health = helpers.Min(b.Health+amount, b.MaxHealth)
+	}
+}
+
+// inflict starts the status effect a hit carries on the boss, if any.
+func (b *Boss) inflict(s events.Status) {
+	if s.Type != "" {
+		b.Effects.Apply(b, s.Type, s.Value, s.Duration)
+	}
+}
+
+// wounded moves the fight on once the boss lost health: to a later phase
+// once it drops far enough, or to its death.
+func (b *Boss) wounded() {
+	if b.ShouldDie() {
+		b.die()
+		return
+	}
+
+	for b.Phase+1 < len(b.Def.Phases) && float32(b.Health) <= b.Def.Phases[b.Phase+1].Health*float32(b.MaxHealth) {
+		b.Phase++
+		b.soundManager.RequestSound("biwa", 1.0, 0.8)
+	}
+}
+
+// die plays the boss's death and lets everyone know the fight is won.
+func (b *Boss) die() {
+	b.setAction(BOSS_DYING)
+	b.IsTakingDamage = false
+	if b.LastDirection != "right" {
+		b.CurrentAnim = (*b.Animations)["death_right"]
+	} else {
+		b.CurrentAnim = (*b.Animations)["death_left"]
+	}
+	b.particles.EmitParticles(b.Center(), 40, rl.Gray, "death")
+
+	if !b.defeated {
+		b.defeated = true
+		b.bus.Publish(events.BossDefeated{Name: b.Def.Name})
+	}
+}
+
+// Defeated reports whether the boss's health ran out.
+func (b *Boss) Defeated() bool {
+	return b.defeated
+}
+
+// Render draws the boss, flashing while it winds up an attack and with the
+// reach of its slams.
+func (b *Boss) Render() {
+	if b.isDead {
+		b.particles.Draw()
+		return
+	}
+
+	color := b.tint()
+	if !b.IsTakingDamage && b.Action == BOSS_WINDUP && int(b.actionTimer*10)%2 == 0 {
+		color = rl.Orange
+	}
+
+	switch b.Action {
+	case BOSS_WINDUP:
+		if b.attack == "slam" {
+			c := b.Center()
+			rl.DrawCircleLines(int32(c.X), int32(c.Y), b.Def.SlamRadius, rl.ColorAlpha(rl.Red, b.actionTimer/b.Def.Windup))
+		}
+	case BOSS_SLAM:
+		progress := b.actionTimer / SLAM_DURATION
+		rl.DrawCircleV(b.Center(), b.Def.SlamRadius*progress, rl.ColorAlpha(rl.Brown, 0.5*(1-progress)))
+	}
+
+	rl.DrawTextureEx(b.CurrentAnim.Frames[b.CurrentAnim.CurrentFrame], b.Position, 0, b.Scale, color)
+	b.particles.Draw()
+}
+
+// RenderBossBar draws the health of the boss across the top of the
+// screen, marking where its later phases start.
+func (em *EnemiesManager) RenderBossBar() {
+	b := em.Boss
+	if b == nil || b.isDead {
+		return
+	}
+
+	const height = float32(14)
+	x := float32(rl.GetScreenWidth()-BOSS_BAR_WIDTH) / 2
+	y := float32(40)
+
+	title := b.Def.Title
+	if len(b.Def.Phases) > 1 {
+		title = fmt.Sprintf("%s - %d/%d", title, b.Phase+1, len(b.Def.Phases))
+	}
+	rl.DrawText(title, int32(x), int32(y)-22, 20, rl.RayWhite)
+
+	bar := rl.NewRectangle(x, y, BOSS_BAR_WIDTH, height)
+	rl.DrawRectangleRec(bar, rl.NewColor(0, 0, 0, 150))
+	fill := float32(math.Max(0, float64(b.Health))) / float32(b.MaxHealth)
+	rl.DrawRectangleRec(rl.NewRectangle(x, y, BOSS_BAR_WIDTH*fill, height), rl.Maroon)
+	for _, phase := range b.Def.Phases[1:] {
+		markX := x + BOSS_BAR_WIDTH*phase.Health
+		rl.DrawLineEx(rl.NewVector2(markX, y), rl.NewVector2(markX, y+height), 2, rl.Gold)
+	}
+	rl.DrawRectangleLinesEx(bar, 2, rl.ColorAlpha(rl.White, 0.5))
+}
@@ -1,12 +1,14 @@
 package enemies
 
 import (
+	"math"
 	"math/rand"
-	"sync"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 
 	"crydes/audio"
+	"crydes/defs"
+	"crydes/events"
 	"crydes/helpers"
 	"crydes/player"
 	"crydes/world"
@@ -16,25 +18,30 @@ type EnemiesManager struct {
 	Enemies    []*Enemy
 	Animations map[string]*map[string]*helpers.Animation
 
-	Map   *world.Map
-	Rooms []helpers.Rectangle
+	Map       *world.Map
+	Rooms     []helpers.Rectangle
+	flowField *world.FlowField // Leads every enemy to the player
 
-	inComingDamage chan rl.Rectangle
-	soundManager   *audio.SoundManager
-	KilledCount    int
+	Projectiles []*Projectile
+	Boss        *Boss // Guards the arena, nil anywhere else
 
-	mutex sync.RWMutex
+	bus          *events.Bus
+	soundManager *audio.SoundManager
+	KilledCount  int
+	nextID       int // Given to the next enemy created, so no two share an ID
 }
 
-func NewEnemiesManager(pX, pY float32, mp *world.Map, playerAttackChan chan rl.Rectangle, rooms []helpers.Rectangle, soundManager *audio.SoundManager) *EnemiesManager {
+// NewEnemiesManager creates the enemies of a map, they take the player's
+// attacks from the bus and publish their own hits and deaths on it.
+func NewEnemiesManager(pX, pY float32, mp *world.Map, bus *events.Bus, rooms []helpers.Rectangle, soundManager *audio.SoundManager) *EnemiesManager {
 	manager := &EnemiesManager{
-		Enemies:        []*Enemy{},
-		Animations:     map[string]*map[string]*helpers.Animation{},
-		Map:            mp,
-		inComingDamage: playerAttackChan,
-		Rooms:          rooms,
-		soundManager:   soundManager,
-		mutex:          sync.RWMutex{},
+		Enemies:      []*Enemy{},
+		Animations:   map[string]*map[string]*helpers.Animation{},
+		Map:          mp,
+		flowField:    world.NewFlowField(mp),
+		bus:          bus,
+		Rooms:        rooms,
+		soundManager: soundManager,
 	}
 
 	manager.LoadAnimations()
@@ -46,7 +53,8 @@ func NewEnemiesManager(pX, pY float32, mp *world.Map, playerAttackChan chan rl.R
 	// 	// NewEnemy(1, pX-20, pY+20, 0.5, rl.NewVector2(16, 16), 200, manager.Animations["skeleton"], 3),
 	// )
 
-	go manager.ListenForDamage()
+	events.Subscribe(bus, manager.onDamage)
+	events.Subscribe(bus, func(events.EnemyKilled) { manager.KilledCount++ })
 
 	return manager
 }
@@ -54,12 +62,22 @@ func NewEnemiesManager(pX, pY float32, mp *world.Map, playerAttackChan chan rl.R
 // var lastSpawnTime time.Time
 
 func (em *EnemiesManager) Update(refreshRate float32, p *player.Player) {
+	em.flowField.Update(refreshRate, p.Position)
+	em.updateProjectiles(refreshRate, p)
+	em.hitShots(p)
+	if em.Boss != nil {
+		em.Boss.Update(refreshRate, p)
+	}
+
 	for _, e := range em.Enemies {
 
 		if e.isDead {
 			continue
 		}
 
+		e.updateDamageFlash(refreshRate)
+		e.Effects.Update(e, refreshRate)
+
 		if helpers.Distance(p.Position, e.Position) <= 200 {
 			e.Update(refreshRate, p)
 		}
@@ -83,7 +101,10 @@ func (em *EnemiesManager) Update(refreshRate float32, p *player.Player) {
 
 func (em *EnemiesManager) ResetEnemies() {
 	em.Enemies = []*Enemy{}
+	em.Projectiles = nil
+	em.Boss = nil
 	em.KilledCount = 0
+	em.flowField.Invalidate() // The map was regenerated
 	em.SpawnEnemies()
 }
 
@@ -96,6 +117,7 @@ func (em *EnemiesManager) SpawnEnemies() {
 
 // Move the existing room spawning logic to this method
 func (em *EnemiesManager) spawnEnemiesInRooms() {
+	rng := em.Map.Rand()
 	for i, room := range em.Rooms {
 		if i == 0 {
 			continue
@@ -106,32 +128,13 @@ func (em *EnemiesManager) spawnEnemiesInRooms() {
 			continue
 		}
 
-		numEnemies := calculateEnemiesForRoom(actualRoom.Size)
+		numEnemies := calculateEnemiesForRoom(actualRoom.Size, em.Map.Depth(), rng)
 
 		for j := 0; j < numEnemies; j++ {
-			ePos := room.GetRandomPosInRect()
-			eType := helpers.GetRandomEnemyType()
-			scale, speed, health := getEnemyAttributes(actualRoom.Size)
-
-			enemy := NewEnemy(
-				j,
-				ePos.X,
-				ePos.Y,
-				scale,
-				rl.NewVector2(16, 16),
-				speed,
-				em.Animations[eType],
-				health,
-				i,
-				em.soundManager,
-				func() {
-					em.mutex.Lock()
-					em.KilledCount++
-					em.mutex.Unlock()
-				},
-			)
-
-			em.Enemies = append(em.Enemies, enemy)
+			ePos := room.GetRandomPosInRect(rng)
+			a := defs.Get().RandomEnemy(rng)
+
+			em.Enemies = append(em.Enemies, em.newEnemy(a.Name, ePos.X, ePos.Y, a.Scale, a.Speed, a.Health, i))
 		}
 	}
 }
@@ -139,62 +142,160 @@ func (em *EnemiesManager) spawnEnemiesInRooms() {
 // Add new method for corridor spawning
 func (em *EnemiesManager) spawnEnemiesInCorridors() {
 	corridorTiles := em.Map.GetCorridorTiles()
+	rng := em.Map.Rand()
 
 	// Spawn an enemy every N tiles in corridors (adjust as needed)
 	spawnFrequency := 20 // Adjust this value to control density
 
 	for i := 0; i < len(corridorTiles); i += spawnFrequency {
-		if rand.Float32() < 0.5 { // 30% chance to spawn at each valid location
+		if rng.Float32() < 0.5 { // 30% chance to spawn at each valid location
 			pos := corridorTiles[i]
-			eType := helpers.GetRandomEnemyType()
-
-			// Corridor enemies are slightly weaker
-			enemy := NewEnemy(
-				i,
-				pos.X,
-				pos.Y,
-				0.6, // Smaller scale
-				rl.NewVector2(16, 16),
-				150, // Slower speed
-				em.Animations[eType],
-				2,  // Less health
-				-1, // No specific room
-				em.soundManager,
-				func() {
-					em.mutex.Lock()
-					em.KilledCount++
-					em.mutex.Unlock()
-				},
-			)
-
-			em.Enemies = append(em.Enemies, enemy)
+			a := defs.Get().RandomEnemy(rng)
+
+			// Corridor enemies belong to no specific room
+			em.Enemies = append(em.Enemies, em.newEnemy(a.Name, pos.X, pos.Y, a.Scale, a.Speed, a.Health, -1))
 		}
 	}
 }
 
-func calculateEnemiesForRoom(size world.RoomSize) int {
-	switch size {
-	case world.SmallRoom:
-		return 1 + rand.Intn(2) // 1-2 enemies
-	case world.MediumRoom:
-		return 2 + rand.Intn(6) // 2-4 enemies
-	case world.LargeRoom:
-		return 4 + rand.Intn(10) // 4-7 enemies
-	default:
-		return 2
+// newEnemy creates an enemy of the given type, with an ID of its own, that
+// counts towards the kill count when it dies.
+func (em *EnemiesManager) newEnemy(eType string, x, y, scale, speed float32, health, room int) *Enemy {
+	e := NewEnemy(
+		em.nextID,
+		eType,
+		x,
+		y,
+		scale,
+		rl.NewVector2(16, 16),
+		speed,
+		em.Animations[eType],
+		health,
+		room,
+		em.soundManager,
+		em.bus,
+	)
+	e.mp = em.Map
+	e.flowField = em.flowField
+	e.throw = em.throw
+	em.nextID++
+	return e
+}
+
+// SpawnBoss clears the arena of everything but the boss, which waits at
+// the arena's boss spawn.
+func (em *EnemiesManager) SpawnBoss(def *defs.BossDef) *Boss {
+	em.Enemies = []*Enemy{}
+	em.Projectiles = nil
+	em.flowField.Invalidate() // The arena was just generated
+
+	x, y := em.Map.BossSpawn()
+	em.Boss = em.newBoss(def, x-def.Size[0]*def.Scale/2, y)
+	return em.Boss
+}
+
+// summonMinions brings in the boss's minions around it, on floor they fit
+// on, as long as there aren't too many of them alive already.
+func (em *EnemiesManager) summonMinions(b *Boss) {
+	living := 0
+	for _, e := range em.Enemies {
+		if !e.isDead && !e.ShouldDie() {
+			living++
+		}
+	}
+
+	a := GetArchetype(b.Def.Summon)
+	center := b.Center()
+	for i := 0; i < b.Def.SummonCount && living < MAX_MINIONS; i++ {
+		angle := 2 * math.Pi * (float64(i) + float64(em.Map.Rand().Float32())) / float64(b.Def.SummonCount)
+		sin, cos := math.Sincos(angle)
+		x := center.X + float32(cos)*SUMMON_SPREAD - 8*a.Scale
+		y := center.Y + float32(sin)*SUMMON_SPREAD - 8*a.Scale
+
+		e := em.newEnemy(a.Name, x, y, a.Scale, a.Speed, a.Health, -1)
+		if !e.fits(x, y) {
+			continue
+		}
+		e.setState(CHASE) // Summoned to fight, not to patrol
+		em.Enemies = append(em.Enemies, e)
+		living++
+	}
+}
+
+// KillMinions ends every enemy left in the arena once the boss falls.
+func (em *EnemiesManager) KillMinions() {
+	for _, e := range em.Enemies {
+		if e.isDead || e.ShouldDie() {
+			continue
+		}
+		e.Health = 0
+		e.setState(DEAD)
+		e.TriggerDeath()
+	}
+	em.Projectiles = nil
+}
+
+// throw launches an enemy projectile.
+func (em *EnemiesManager) throw(from, velocity rl.Vector2, damage int, inflicts events.Status) {
+	em.Projectiles = append(em.Projectiles, &Projectile{
+		Position: from,
+		Velocity: velocity,
+		Damage:   damage,
+		Status:   inflicts,
+	})
+	em.soundManager.RequestSound("sword_swing", 0.5, 1.5)
+}
+
+func (em *EnemiesManager) updateProjectiles(refreshRate float32, p *player.Player) {
+	alive := em.Projectiles[:0]
+	for _, pr := range em.Projectiles {
+		if !pr.Update(refreshRate, em.Map, p, em.bus) {
+			alive = append(alive, pr)
+		}
+	}
+	em.Projectiles = alive
+}
+
+// hitShots stops the player's projectiles at the first enemy they fly
+// into, hurting it.
+func (em *EnemiesManager) hitShots(p *player.Player) {
+	for _, s := range p.Shots {
+		if s.Spent || s.Weapon.Kind != "projectile" {
+			continue
+		}
+		for _, e := range em.targets() {
+			if e.isDead || e.ShouldDie() || !helpers.CheckCollisionRecs(s.Bounds(), e.GetBounds()) {
+				continue
+			}
+			em.bus.Publish(events.DamageDealt{Target: events.TARGET_ENEMIES, Area: s.Hit(), Amount: s.Damage, Crit: s.Crit, Knockback: s.Weapon.Knockback, Status: s.Weapon.Effect.Status()})
+			break
+		}
+	}
+}
+
+// targets returns every enemy the player's shots can hit, the boss
+// included.
+func (em *EnemiesManager) targets() []*Enemy {
+	if em.Boss == nil {
+		return em.Enemies
 	}
+	return append(em.Enemies[:len(em.Enemies):len(em.Enemies)], em.Boss.Enemy)
 }
 
-func getEnemyAttributes(size world.RoomSize) (scale, speed float32, health int) {
+// calculateEnemiesForRoom picks how many enemies a room holds, every floor
+// below the first adds one more to medium and large rooms and one more to
+// small rooms every other floor.
+func calculateEnemiesForRoom(size world.RoomSize, depth int, rng *rand.Rand) int {
+	extra := depth - 1
 	switch size {
 	case world.SmallRoom:
-		return 0.6, 150, 2
+		return 1 + rng.Intn(2) + extra/2 // 1-2 enemies
 	case world.MediumRoom:
-		return 0.8, 200, 3
+		return 2 + rng.Intn(6) + extra // 2-4 enemies
 	case world.LargeRoom:
-		return 1.1, 250, 4
+		return 4 + rng.Intn(10) + extra // 4-7 enemies
 	default:
-		return 0.5, 200, 3
+		return 2 + extra
 	}
 }
 
@@ -206,20 +307,27 @@ func (em *EnemiesManager) Render() {
 	for _, e := range em.Enemies {
 		e.Render()
 	}
+	if em.Boss != nil {
+		em.Boss.Render()
+	}
+	for _, pr := range em.Projectiles {
+		pr.Render()
+	}
 }
 
-func (em *EnemiesManager) PlayerAttack(area rl.Rectangle) {
-	em.soundManager.RequestSound("sword_swing", 1.0, 1.0)
+// PlayerAttack hurts every enemy overlapping the area of the hit.
+func (em *EnemiesManager) PlayerAttack(hit events.DamageDealt) {
 	for _, e := range em.Enemies {
-		e.DamageChan <- area
+		e.TakeDamage(hit)
+	}
+	if em.Boss != nil {
+		em.Boss.TakeDamage(hit)
 	}
 }
 
-func (em *EnemiesManager) ListenForDamage() {
-	for {
-		select {
-		case damageArea := <-em.inComingDamage:
-			em.PlayerAttack(damageArea)
-		}
+// onDamage hands the player's attacks to every enemy.
+func (em *EnemiesManager) onDamage(damage events.DamageDealt) {
+	if damage.Target == events.TARGET_ENEMIES {
+		em.PlayerAttack(damage)
 	}
 }
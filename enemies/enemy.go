@@ -2,11 +2,13 @@ package enemies
 
 import (
 	"crydes/audio"
+	"crydes/combat"
+	"crydes/defs"
+	"crydes/events"
 	"crydes/helpers"
-	"crydes/player"
+	"crydes/status"
+	"crydes/world"
 	"math"
-	"math/rand"
-	"time"
 
 	ps "crydes/effects/particle"
 
@@ -15,7 +17,9 @@ import (
 
 type Enemy struct {
 	ID       int
+	Type     string
 	Position rl.Vector2
+	Previous rl.Vector2 // Position at the start of the last tick, for interpolated rendering
 	Size     rl.Vector2
 	Scale    float32
 	Speed    float32
@@ -25,21 +29,38 @@ type Enemy struct {
 	Animations    *map[string]*helpers.Animation
 	LastDirection string
 
-	DamageChan     chan rl.Rectangle
 	IsTakingDamage bool
+	damageTimer    float32      // Seconds left of the damage flash
+	invulnerable   combat.Timer // Spares the enemy right after a hit
 	isDead         bool
 
-	CurrentRoom  int
+	Effects status.Effects // Burns, slows and the like running on the enemy
+
+	CurrentRoom int
+	mp          *world.Map
+	flowField   *world.FlowField // Shared by every enemy, leads to the player
+
+	Archetype   *defs.EnemyDef
+	State       AIState
+	stateTimer  float32      // Seconds spent in the current state
+	attackTimer combat.Timer // Until the next attack is ready
+	jitterAngle float32      // Current steering offset of erratic enemies
+	jitterTimer float32
+
+	wanderTarget rl.Vector2
+	throw        func(from, velocity rl.Vector2, damage int, inflicts events.Status) // Launches a projectile
+
 	soundManager *audio.SoundManager
 	particles    *ps.ParticleSystem
+	bus          *events.Bus
 
-	onDeath     func()
 	didCallback bool
 }
 
 // Constructor for a new Enemy instance
 func NewEnemy(
 	id int,
+	eType string,
 	x, y float32,
 	scale float32,
 	size rl.Vector2,
@@ -48,27 +69,28 @@ func NewEnemy(
 	health int,
 	CurrentRoom int,
 	sm *audio.SoundManager,
-	onDeath func(),
+	bus *events.Bus,
 ) *Enemy {
-	e := &Enemy{
+	return &Enemy{
+		ID:            id,
+		Type:          eType,
+		Archetype:     GetArchetype(eType),
+		State:         IDLE,
 		Position:      rl.NewVector2(x, y),
+		Previous:      rl.NewVector2(x, y),
 		Speed:         speed,
 		Animations:    animations,
 		Scale:         scale,
 		Size:          size,
 		CurrentAnim:   (*animations)["idle_right"],
 		LastDirection: "right",
-		DamageChan:    make(chan rl.Rectangle, 10),
 		Health:        health,
 		CurrentRoom:   CurrentRoom,
 		soundManager:  sm,
 		particles:     ps.NewParticleSystem(),
-		onDeath:       onDeath,
+		bus:           bus,
 		didCallback:   false,
 	}
-
-	go e.ListenForDamage()
-	return e
 }
 
 // Updates the current animation of the enemy based on a refresh rate.
@@ -91,72 +113,49 @@ func (e *Enemy) Render() {
 		return
 	}
 
-	var drawColor rl.Color
-	if e.IsTakingDamage {
-		drawColor = helpers.DAMAGE_COLOR
-	} else {
-		drawColor = rl.White
-	}
-
 	// Draw the enemy's current animation frame.
-	rl.DrawTextureEx(e.CurrentAnim.Frames[e.CurrentAnim.CurrentFrame], e.Position, 0, e.Scale, drawColor)
+	rl.DrawTextureEx(e.CurrentAnim.Frames[e.CurrentAnim.CurrentFrame], e.Position, 0, e.Scale, e.tint())
 
 	e.particles.Draw()
 }
 
-// Updates the enemy's state based on its interactions with the player.
-func (e *Enemy) Update(refreshRate float32, p *player.Player) {
-	e.particles.Update(refreshRate)
+// Feet returns the point the enemy stands on, used for navigation.
+func (e *Enemy) Feet() rl.Vector2 {
+	return rl.NewVector2(e.Position.X+e.Size.X*e.Scale/2, e.Position.Y+e.Size.Y*e.Scale*3/4)
+}
 
-	if e.isDead {
+// Move shifts the enemy one axis at a time, dropping the part of the move
+// that would put its feet in a wall. Enemies already stuck in a wall may
+// move freely so they can get out.
+func (e *Enemy) Move(dx, dy float32) {
+	if e.mp == nil || !e.fits(e.Position.X, e.Position.Y) {
+		e.Position.X += dx
+		e.Position.Y += dy
 		return
 	}
 
-	// Handle enemy death state and animation
-	if e.ShouldDie() && !e.isDead {
-		e.TriggerDeath()
-		e.UpdateAnimation(refreshRate)
-		return
+	if e.fits(e.Position.X+dx, e.Position.Y) {
+		e.Position.X += dx
+	}
+	if e.fits(e.Position.X, e.Position.Y+dy) {
+		e.Position.Y += dy
 	}
-
-	// Handle movement and interaction with the player
-	e.MoveTowardsPlayer(refreshRate, p)
-	e.UpdateAnimation(refreshRate)
 }
 
-// Moves the enemy towards the player, adjusting its position and animation accordingly.
-func (e *Enemy) MoveTowardsPlayer(refreshRate float32, p *player.Player) {
-	// Calculate distance to the player and adjust position
-	distance := helpers.GetDistance(e.Position, p.Position)
-
-	// if distance < helpers.ENEMIES_PLAYER_RANGE && (p.GetPlayerRoom() == e.CurrentRoom || e.CurrentRoom == -1) {
-	if distance < helpers.ENEMIES_PLAYER_RANGE {
-		deltaX := p.Position.X - e.Position.X
-		deltaY := p.Position.Y - e.Position.Y
-
-		if e.CurrentRoom != -1 {
-			e.CurrentRoom = -1
-		}
-
-		moveX, moveY := e.CalculateMovement(deltaX, deltaY)
-
-		// Update position
-		e.Position.X += moveX
-		e.Position.Y += moveY
+// fits reports whether the enemy's feet, the bottom half of its sprite,
+// would be on walkable tiles at x, y.
+func (e *Enemy) fits(x, y float32) bool {
+	w, h := e.Size.X*e.Scale, e.Size.Y*e.Scale
+	left, right := x+w/4, x+w*3/4
+	top, bottom := y+h/2, y+h-1
 
-		// Check for collision with the player and bounce back if necessary
-		if distance < 7 {
-			p.TakeDamage()
-			e.BounceBack(p.Position.X, p.Position.Y)
-		}
-	} else {
-		// Enemy is idle when out of range
-		e.SetIdleAnimation()
-	}
+	return e.mp.IsWalkableFloat(left, top) && e.mp.IsWalkableFloat(right, top) &&
+		e.mp.IsWalkableFloat(left, bottom) && e.mp.IsWalkableFloat(right, bottom)
 }
 
-// Calculates movement towards the player.
-func (e *Enemy) CalculateMovement(deltaX, deltaY float32) (float32, float32) {
+// Calculates the movement of one update of deltaTime seconds towards
+// deltaX, deltaY.
+func (e *Enemy) CalculateMovement(deltaX, deltaY, deltaTime float32) (float32, float32) {
 	// Normalize the movement vector
 	length := float32(math.Sqrt(float64(deltaX*deltaX + deltaY*deltaY)))
 	if length == 0 {
@@ -168,8 +167,12 @@ func (e *Enemy) CalculateMovement(deltaX, deltaY float32) (float32, float32) {
 	dirY := deltaY / length
 
 	// Apply speed
-	moveX := dirX * helpers.ENEMIES_MOV_SPEED * e.Speed
-	moveY := dirY * helpers.ENEMIES_MOV_SPEED * e.Speed
+	if e.mp != nil {
+		deltaTime *= e.mp.SpeedAt(e.Feet()) // Wading slows enemies down too
+	}
+	deltaTime *= e.Effects.Multiplier("speed")
+	moveX := dirX * helpers.ENEMIES_MOV_SPEED * e.Speed * deltaTime
+	moveY := dirY * helpers.ENEMIES_MOV_SPEED * e.Speed * deltaTime
 
 	// Update animation based on horizontal movement
 	if helpers.ABS(deltaX) > helpers.ENEMIES_DIRECTION_CHANGE_THRESHOLD {
@@ -197,24 +200,30 @@ func (e *Enemy) SetIdleAnimation() {
 	}
 }
 
-// Bounces the enemy back slightly after colliding with the player.
-func (e *Enemy) BounceBack(x, y float32) {
-	angle := math.Atan2(float64(e.Position.Y-y), float64(e.Position.X-x))
+// Bounces the enemy back about distance pixels away from (x, y), stopping
+// against walls.
+func (e *Enemy) BounceBack(x, y, distance float32) {
+	// Add some randomness to the bounce back force
+	force := distance * (1 + e.randFloat()*0.3)
 
-	// Increase bounce back force and add some randomness
-	force := helpers.ENEMIES_BOUNCE_BACK_DISTANCE * (1 + rand.Float32()*0.3)
-
-	newX := e.Position.X + float32(math.Cos(angle))*force
-	newY := e.Position.Y + float32(math.Sin(angle))*force
-
-	e.Position = rl.NewVector2(newX, newY)
+	if e.mp == nil || !e.fits(e.Position.X, e.Position.Y) {
+		e.Position = combat.Push(e.Position, rl.NewVector2(x, y), force, func(rl.Vector2) bool { return true })
+		return
+	}
+	e.Position = combat.Push(e.Position, rl.NewVector2(x, y), force, func(pos rl.Vector2) bool {
+		return e.fits(pos.X, pos.Y)
+	})
 }
 
 // Triggers the death animation for the enemy.
 func (e *Enemy) TriggerDeath() {
 	if !e.didCallback {
 		e.didCallback = true
-		e.onDeath()
+		killed := events.EnemyKilled{ID: e.ID, Type: e.Type, Position: e.Feet()}
+		if e.Archetype != nil {
+			killed.XP = e.Archetype.XP
+		}
+		e.bus.Publish(killed)
 	}
 
 	if e.LastDirection != "right" {
@@ -240,14 +249,16 @@ func (e *Enemy) GetBounds() rl.Rectangle {
 }
 
 // Handles damage taken by the enemy.
-func (e *Enemy) TakeDamage(area rl.Rectangle) {
+func (e *Enemy) TakeDamage(hit events.DamageDealt) {
+	area := hit.Area
 	if e.isDead || !helpers.CheckCollisionRecs(area, e.GetBounds()) {
 		return
 	}
+	if !combat.Hurt(&e.Health, hit.Amount, &e.invulnerable, combat.ENEMY_INVULNERABILITY) {
+		return
+	}
 	e.soundManager.RequestSound("sword_hit", 1.0, 1.0)
-
-	e.Health--
-	e.IsTakingDamage = true
+	e.flash(hit.Amount, hit.Crit)
 
 	// Emit hit particles
 	particlePos := rl.Vector2{
@@ -261,7 +272,8 @@ func (e *Enemy) TakeDamage(area rl.Rectangle) {
 	centerX := area.X + area.Width/2
 	centerY := area.Y + area.Height/2
 
-	e.BounceBack(centerX, centerY)
+	e.BounceBack(centerX, centerY, hit.Knockback)
+	e.setState(STUNNED)
 
 	// Trigger death logic if health falls below zero
 	if e.ShouldDie() {
@@ -271,22 +283,17 @@ func (e *Enemy) TakeDamage(area rl.Rectangle) {
 		e.particles.EmitParticles(particlePos, 20, rl.Gray, "death")
 		return
 	}
-
-	// Reset the damage state after a short duration
-	go func() {
-		<-time.After(helpers.DAMAGE_DURATION)
-		e.IsTakingDamage = false
-	}()
+	e.inflict(hit.Status)
 }
 
-// Listens for damage signals on the DamageChan channel.
-func (e *Enemy) ListenForDamage() {
-	for area := range e.DamageChan {
-		e.TakeDamage(area)
+// updateDamageFlash ends the damage flash once its duration has passed,
+// and the invulnerability that came with the hit.
+func (e *Enemy) updateDamageFlash(refreshRate float32) {
+	e.invulnerable.Update(refreshRate)
+	if e.damageTimer > 0 {
+		e.damageTimer -= refreshRate
+		if e.damageTimer <= 0 {
+			e.IsTakingDamage = false
+		}
 	}
 }
-
-// Destroys the enemy, releasing resources and closing channels.
-func (e *Enemy) Destroy() {
-	close(e.DamageChan)
-}
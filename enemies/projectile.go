@@ -0,0 +1,54 @@
+package enemies
+
+import (
+	"crydes/events"
+	"crydes/player"
+	"crydes/world"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const PROJECTILE_LIFETIME = 2.5 // Seconds before a projectile falls apart
+
+// Projectile is a bone thrown by a ranged enemy.
+type Projectile struct {
+	Position rl.Vector2
+	Velocity rl.Vector2 // Pixels per second
+	Rotation float32
+	Damage   int           // Hearts it takes from the player
+	Status   events.Status // Inflicted on the player it hits
+	life     float32
+}
+
+// Update moves the projectile and reports whether it should be removed,
+// after hitting a wall or the player or flying for too long.
+func (pr *Projectile) Update(deltaTime float32, mp *world.Map, p *player.Player, bus *events.Bus) bool {
+	pr.life += deltaTime
+	pr.Position = rl.Vector2Add(pr.Position, rl.Vector2Scale(pr.Velocity, deltaTime))
+	pr.Rotation += 720 * deltaTime
+
+	if pr.life >= PROJECTILE_LIFETIME || !mp.IsWalkableFloat(pr.Position.X, pr.Position.Y) {
+		return true
+	}
+
+	if rl.Vector2Distance(pr.Position, p.GetPlayerCenterPoint()) < 5 {
+		bus.Publish(events.DamageDealt{Target: events.TARGET_PLAYER, Amount: pr.Damage, Status: pr.Status})
+		return true
+	}
+
+	return false
+}
+
+// Render draws a small spinning bone.
+func (pr *Projectile) Render() {
+	sin, cos := math.Sincos(float64(pr.Rotation) * math.Pi / 180)
+	half := rl.Vector2{X: float32(cos) * 3, Y: float32(sin) * 3}
+
+	a := rl.Vector2Subtract(pr.Position, half)
+	b := rl.Vector2Add(pr.Position, half)
+
+	rl.DrawLineEx(a, b, 1.5, rl.RayWhite)
+	rl.DrawCircleV(a, 1.2, rl.RayWhite)
+	rl.DrawCircleV(b, 1.2, rl.RayWhite)
+}
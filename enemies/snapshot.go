@@ -0,0 +1,108 @@
+package enemies
+
+import (
+	"crydes/defs"
+	"crydes/helpers"
+	"crydes/status"
+)
+
+// EnemyState is the serializable form of a living enemy.
+type EnemyState struct {
+	ID     int     `json:"id"`
+	Type   string  `json:"type"`
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Scale  float32 `json:"scale"`
+	Speed  float32 `json:"speed"`
+	Health int     `json:"health"`
+	Room   int     `json:"room"`
+
+	Effects []status.EffectState `json:"effects,omitempty"`
+}
+
+// Snapshot captures every enemy that is still alive.
+func (em *EnemiesManager) Snapshot() []EnemyState {
+	var states []EnemyState
+	for _, e := range em.Enemies {
+		if e.isDead || e.ShouldDie() {
+			continue
+		}
+		states = append(states, EnemyState{
+			ID:      e.ID,
+			Type:    e.Type,
+			X:       e.Position.X,
+			Y:       e.Position.Y,
+			Scale:   e.Scale,
+			Speed:   e.Speed,
+			Health:  e.Health,
+			Room:    e.CurrentRoom,
+			Effects: e.Effects.Snapshot(),
+		})
+	}
+	return states
+}
+
+// Restore replaces the enemies with saved ones and the kill count. They keep
+// their IDs, enemies created afterwards get new ones. The boss is restored
+// on its own.
+func (em *EnemiesManager) Restore(states []EnemyState, killed int) {
+	em.Enemies = []*Enemy{}
+	em.Boss = nil
+	em.flowField.Invalidate()
+	for _, s := range states {
+		e := em.newEnemy(s.Type, s.X, s.Y, s.Scale, s.Speed, s.Health, s.Room)
+		e.ID = s.ID
+		if s.ID >= em.nextID {
+			em.nextID = s.ID + 1
+		}
+		e.Effects.Restore(s.Effects)
+		em.Enemies = append(em.Enemies, e)
+	}
+
+	em.KilledCount = killed
+}
+
+// BossState is the serializable form of a boss still fighting.
+type BossState struct {
+	Name   string  `json:"name"`
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Health int     `json:"health"`
+	Phase  int     `json:"phase"`
+
+	Effects []status.EffectState `json:"effects,omitempty"`
+}
+
+// SnapshotBoss captures the boss, nil when there is none left to fight.
+func (em *EnemiesManager) SnapshotBoss() *BossState {
+	b := em.Boss
+	if b == nil || b.Defeated() {
+		return nil
+	}
+	return &BossState{
+		Name:    b.Def.Name,
+		X:       b.Position.X,
+		Y:       b.Position.Y,
+		Health:  b.Health,
+		Phase:   b.Phase,
+		Effects: b.Effects.Snapshot(),
+	}
+}
+
+// RestoreBoss replaces the boss with a saved one, bosses whose definition
+// is gone are dropped.
+func (em *EnemiesManager) RestoreBoss(state *BossState) {
+	em.Boss = nil
+	if state == nil {
+		return
+	}
+	def, exists := defs.Get().Bosses[state.Name]
+	if !exists {
+		return
+	}
+
+	em.Boss = em.newBoss(def, state.X, state.Y)
+	em.Boss.Health = state.Health
+	em.Boss.Phase = helpers.Min(state.Phase, len(def.Phases)-1)
+	em.Boss.Effects.Restore(state.Effects)
+}
@@ -0,0 +1,66 @@
+package enemies
+
+import (
+	"crydes/events"
+	"crydes/helpers"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Heal gives the enemy amount health back, up to what its type starts
+// with.
+func (e *Enemy) Heal(amount int) {
+	if e.isDead || e.ShouldDie() {
+		return
+	}
+	e.Health = helpers.Min(e.Health+amount, e.Archetype.Health)
+}
+
+// Hurt takes amount health from the enemy for an effect running on it,
+// killing it when none is left.
+func (e *Enemy) Hurt(amount int) {
+	if e.isDead || e.ShouldDie() || amount <= 0 {
+		return
+	}
+	e.Health -= amount
+	e.flash(amount, false)
+
+	if e.ShouldDie() {
+		e.IsTakingDamage = false
+		e.setState(DEAD)
+		e.TriggerDeath()
+	}
+}
+
+// Say does nothing, enemies keep their thoughts to themselves.
+func (e *Enemy) Say(string) {}
+
+// flash shows the enemy losing amount health.
+func (e *Enemy) flash(amount int, crit bool) {
+	e.IsTakingDamage = true
+	e.damageTimer = float32(helpers.DAMAGE_DURATION.Seconds())
+	e.bus.Publish(events.DamageTaken{
+		Position: rl.NewVector2(e.Position.X+e.Size.X*e.Scale/2, e.Position.Y),
+		Amount:   amount,
+		Crit:     crit,
+	})
+}
+
+// inflict starts the status effect a hit carries, if any.
+func (e *Enemy) inflict(s events.Status) {
+	if s.Type != "" {
+		e.Effects.Apply(e, s.Type, s.Value, s.Duration)
+	}
+}
+
+// tint returns the color the enemy is drawn with: red while hurt, the
+// color of its oldest effect while one runs.
+func (e *Enemy) tint() rl.Color {
+	if e.IsTakingDamage {
+		return helpers.DAMAGE_COLOR
+	}
+	if active := e.Effects.All(); len(active) > 0 {
+		return active[0].Kind.Color
+	}
+	return rl.White
+}
@@ -0,0 +1,58 @@
+package events
+
+import "reflect"
+
+// Bus queues gameplay events and hands them to their subscribers when
+// drained, once per tick on the main loop, so no system changes another's
+// state from the middle of its own update. A nil *Bus drops everything,
+// which suits entities that live outside a simulation (title screen demo).
+type Bus struct {
+	queue    []interface{}
+	handlers map[reflect.Type][]func(interface{})
+}
+
+// MAX_DRAIN_ROUNDS bounds how many times handlers may publish in reaction
+// to events during a single drain, so a feedback loop can't hang a tick.
+const MAX_DRAIN_ROUNDS = 8
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[reflect.Type][]func(interface{})),
+	}
+}
+
+// Subscribe calls handler with every event of type T, in publish order.
+func Subscribe[T any](b *Bus, handler func(T)) {
+	if b == nil {
+		return
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	b.handlers[t] = append(b.handlers[t], func(e interface{}) { handler(e.(T)) })
+}
+
+// Publish queues an event until the next drain.
+func (b *Bus) Publish(event interface{}) {
+	if b == nil {
+		return
+	}
+	b.queue = append(b.queue, event)
+}
+
+// Drain dispatches every queued event. Events published by handlers are
+// dispatched in the same drain, after the ones already queued.
+func (b *Bus) Drain() {
+	if b == nil {
+		return
+	}
+
+	for round := 0; round < MAX_DRAIN_ROUNDS && len(b.queue) > 0; round++ {
+		queue := b.queue
+		b.queue = nil
+
+		for _, event := range queue {
+			for _, handler := range b.handlers[reflect.TypeOf(event)] {
+				handler(event)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package events
+
+import (
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DamageTarget is who a DamageDealt event hurts
+type DamageTarget int
+
+const (
+	TARGET_ENEMIES DamageTarget = iota // Every enemy overlapping Area
+	TARGET_PLAYER
+)
+
+// DamageDealt is an attack landing: the player's weapon hitting Area, or
+// an enemy or projectile hitting the player.
+type DamageDealt struct {
+	Target    DamageTarget
+	Area      rl.Rectangle // Only used against enemies
+	Amount    int
+	Crit      bool    // A critical hit, Amount already includes it
+	Knockback float32 // Pixels enemies are pushed away from the middle of Area
+	Status    Status  // Status effect the hit inflicts on whoever it hurts
+}
+
+// Status is a status effect inflicted by a hit, an empty Type inflicts
+// none.
+type Status struct {
+	Type     string
+	Value    float32
+	Duration float32 // Seconds
+}
+
+// DamageTaken is a hit hurting the player or an enemy, Position being
+// where the damage is shown.
+type DamageTaken struct {
+	Position rl.Vector2
+	Amount   int
+	Crit     bool
+	Player   bool // The player got hurt, not an enemy
+}
+
+// ItemCollected is the player picking up an item.
+type ItemCollected struct {
+	ItemID   int
+	Item     string
+	Effect   string
+	Value    float32
+	Duration time.Duration
+	Stored   bool // Kept in the hotbar when there's room instead of applied
+}
+
+// EnemyKilled is an enemy's health reaching zero, Position being its feet
+// where its drops fall and XP the experience it was worth.
+type EnemyKilled struct {
+	ID       int
+	Type     string
+	Position rl.Vector2
+	XP       int
+}
+
+// KeyCollected is the player picking up a key, Count includes it.
+type KeyCollected struct {
+	Count int
+}
+
+// DungeonShifted is the dungeon being regenerated from Seed.
+type DungeonShifted struct {
+	Seed int64
+}
+
+// ShiftStarted is the dungeon starting to fade out before a shift.
+type ShiftStarted struct{}
+
+// FloorChanged is the player taking the stairs to the floor at Depth.
+type FloorChanged struct {
+	Depth int
+}
+
+// BossEncountered is the player entering the arena of the boss Name.
+type BossEncountered struct {
+	Name string
+}
+
+// BossDefeated is the boss Name dying, the way out is free.
+type BossDefeated struct {
+	Name string
+}
@@ -2,10 +2,12 @@ module crydes
 
 go 1.21.4
 
-require github.com/gen2brain/raylib-go/raylib v0.0.0-20240524074310-a997a44fb95b
+require (
+	github.com/gen2brain/raylib-go/raylib v0.0.0-20240524074310-a997a44fb95b
+	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842
+)
 
 require (
 	github.com/ebitengine/purego v0.7.1 // indirect
-	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
 	golang.org/x/sys v0.20.0 // indirect
 )
@@ -14,9 +14,12 @@ var (
 
 const (
 	FULLSCREEN    = false
-	TILE_SIZE     = 16 // Smaller tile size for a more compact map
-	MAP_WIDTH     = 100
+	TILE_SIZE     = 16  // Smaller tile size for a more compact map
+	MAP_WIDTH     = 100 // Tiles across the top floor unless -size says otherwise
 	MAP_HEIGHT    = 100
+	MIN_MAP_SIZE  = 50 // Smallest side every generator can lay out
+	MAX_MAP_SIZE  = 300
+	MAP_GROWTH    = 10 // Tiles added across and down per floor below the first
 	MAX_DEPTH     = 5  // Number of divisions for the BSP tree
 	MIN_ROOM_SIZE = 5  // Minimum size for a room
 	MAX_ROOM_SIZE = 12 // Maximum size for a room
@@ -24,10 +27,13 @@ const (
 	DAMAGE_DURATION = time.Duration(0.1 * float32(time.Second))
 
 	ENEMIES_PLAYER_RANGE = 200
-	ENEMIES_MOV_SPEED    = 0.001
+	ENEMIES_MOV_SPEED    = 0.06 // Pixels per second per point of Speed
 	// ENEMIES_EPSILON                    = 0.001
 	ENEMIES_BOUNCE_BACK_DISTANCE       = 6
 	ENEMIES_DIRECTION_CHANGE_THRESHOLD = 5.0
+	ENEMIES_CHASE_TILES                = 24   // Farthest walking distance an enemy will chase from
+	ENEMIES_REPATH_INTERVAL            = 0.25 // Minimum seconds between flow field rebuilds
+	ENEMIES_WANDER_PAUSE               = 1.5  // Seconds an enemy idles between wander targets
 
 	//LIGHTNING
 
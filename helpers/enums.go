@@ -1,5 +0,0 @@
-package helpers
-
-var (
-	ENEMY_TYPES = []string{"spider", "spider", "goblin", "skeleton"}
-)
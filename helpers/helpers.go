@@ -3,14 +3,22 @@ package helpers
 import (
 	"fmt"
 	"math/rand"
+	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-func LoadAnimation(id string, filePaths ...string) *Animation {
+// LoadAnimation loads the frames of an animation. Headless animations load
+// no texture but keep their frame count, so they run for as long.
+func LoadAnimation(headless bool, id string, filePaths ...string) *Animation {
 
 	var textures []rl.Texture2D = []rl.Texture2D{}
 	for _, path := range filePaths {
+		if headless {
+			textures = append(textures, rl.Texture2D{})
+			continue
+		}
+
 		texture := rl.LoadTexture(path)
 
 		if texture.ID == 0 {
@@ -30,6 +38,14 @@ func LoadAnimation(id string, filePaths ...string) *Animation {
 	}
 }
 
+// LoadTexture loads a texture, headless callers get an empty one.
+func LoadTexture(headless bool, path string) rl.Texture2D {
+	if headless {
+		return rl.Texture2D{}
+	}
+	return rl.LoadTexture(path)
+}
+
 func GetDistance(a, b rl.Vector2) float32 {
 	return rl.Vector2Distance(a, b)
 }
@@ -62,8 +78,10 @@ func (r1 *Rectangle) Intersects(r2 Rectangle) bool {
 	return r1.X < r2.X+r2.Width && r1.X+r1.Width > r2.X && r1.Y < r2.Y+r2.Height && r1.Y+r1.Height > r2.Y
 }
 
-func (r1 *Rectangle) GetRandomPosInRect() rl.Vector2 {
-	enemyPos := rl.NewVector2(TILE_SIZE*(float32(r1.X)+float32(rl.GetRandomValue(0, int32(r1.Width)))), TILE_SIZE*(float32(r1.Y)+float32(rl.GetRandomValue(0, int32(r1.Height)))))
+// GetRandomPosInRect returns the top left corner of a random tile inside
+// the rectangle.
+func (r1 *Rectangle) GetRandomPosInRect(rng *rand.Rand) rl.Vector2 {
+	enemyPos := rl.NewVector2(TILE_SIZE*(float32(r1.X)+float32(rng.Intn(int(r1.Width)))), TILE_SIZE*(float32(r1.Y)+float32(rng.Intn(int(r1.Height)))))
 	return enemyPos
 }
 
@@ -73,10 +91,6 @@ func (r1 *Rectangle) ContainsPoint(p rl.Vector2) bool {
 	return p.X >= float32(r1.X)*TILE_SIZE && p.X <= (float32(r1.X+r1.Width)*TILE_SIZE) && p.Y >= float32(r1.Y)*TILE_SIZE && p.Y <= float32(r1.Y+r1.Height)*TILE_SIZE
 }
 
-func GetRandomEnemyType() string {
-	return ENEMY_TYPES[int(rl.GetRandomValue(0, int32(len(ENEMY_TYPES)-1)))]
-}
-
 func Clamp(value, min, max float32) float32 {
 	if value < min {
 		return min
@@ -87,9 +101,9 @@ func Clamp(value, min, max float32) float32 {
 	return value
 }
 
-func GetShiftDelay() float32 {
-	return float32(60 + rand.Intn(10))
-	// return float32(3)
+// RandomSeed returns a fresh seed for runs that weren't given one.
+func RandomSeed() int64 {
+	return time.Now().UnixNano()
 }
 
 func ClaculatePulse(shiftDelay, shiftTimer float32) float32 {
@@ -0,0 +1,123 @@
+package input
+
+// Action is something the player can ask the game to do, whatever device
+// it comes from.
+type Action int
+
+const (
+	MOVE_UP Action = iota
+	MOVE_DOWN
+	MOVE_LEFT
+	MOVE_RIGHT
+	ATTACK
+	PAUSE
+	TOGGLE_MAP
+	USE_SLOT_1 // Hotbar slots, in order
+	USE_SLOT_2
+	USE_SLOT_3
+	USE_SLOT_4
+	NEXT_WEAPON
+
+	// Replay viewer
+	REPLAY_PAUSE
+	REPLAY_STEP
+	REPLAY_FASTER
+	REPLAY_SLOWER
+
+	// Development actions, disabled in release builds
+	DEBUG_DIE
+	DEBUG_SHIFT
+	DEBUG_DECAY_UP
+	DEBUG_DECAY_DOWN
+	DEBUG_LIGHT_UP
+	DEBUG_LIGHT_DOWN
+	DEBUG_TOGGLE_LIGHTING
+	DEBUG_TOGGLE_OVERLAY
+	DEBUG_NEXT_LIGHTING
+
+	// Added after the rest so the bits of older actions don't move in
+	// recorded replays
+	PICK_PERK_1 // Perks offered on level up, in order, picked on the perk screen
+	PICK_PERK_2
+	PICK_PERK_3
+	PICK_PERK_4
+
+	ACTION_COUNT
+)
+
+// ACTION_NAMES are the names actions go by in the bindings file.
+var ACTION_NAMES = [ACTION_COUNT]string{
+	MOVE_UP:               "move_up",
+	MOVE_DOWN:             "move_down",
+	MOVE_LEFT:             "move_left",
+	MOVE_RIGHT:            "move_right",
+	ATTACK:                "attack",
+	PAUSE:                 "pause",
+	TOGGLE_MAP:            "toggle_map",
+	USE_SLOT_1:            "use_slot_1",
+	USE_SLOT_2:            "use_slot_2",
+	USE_SLOT_3:            "use_slot_3",
+	USE_SLOT_4:            "use_slot_4",
+	NEXT_WEAPON:           "next_weapon",
+	REPLAY_PAUSE:          "replay_pause",
+	REPLAY_STEP:           "replay_step",
+	REPLAY_FASTER:         "replay_faster",
+	REPLAY_SLOWER:         "replay_slower",
+	DEBUG_DIE:             "debug_die",
+	DEBUG_SHIFT:           "debug_shift",
+	DEBUG_DECAY_UP:        "debug_decay_up",
+	DEBUG_DECAY_DOWN:      "debug_decay_down",
+	DEBUG_LIGHT_UP:        "debug_light_up",
+	DEBUG_LIGHT_DOWN:      "debug_light_down",
+	DEBUG_TOGGLE_LIGHTING: "debug_toggle_lighting",
+	DEBUG_TOGGLE_OVERLAY:  "debug_toggle_overlay",
+	DEBUG_NEXT_LIGHTING:   "debug_next_lighting",
+	PICK_PERK_1:           "pick_perk_1",
+	PICK_PERK_2:           "pick_perk_2",
+	PICK_PERK_3:           "pick_perk_3",
+	PICK_PERK_4:           "pick_perk_4",
+}
+
+func (a Action) String() string {
+	if a < 0 || a >= ACTION_COUNT {
+		return "unknown"
+	}
+	return ACTION_NAMES[a]
+}
+
+// IsDebug reports whether the action is a development shortcut.
+func (a Action) IsDebug() bool {
+	return a >= DEBUG_DIE && a <= DEBUG_NEXT_LIGHTING
+}
+
+// ActionByName looks an action up by its bindings file name.
+func ActionByName(name string) (Action, bool) {
+	for a, n := range ACTION_NAMES {
+		if n == name {
+			return Action(a), true
+		}
+	}
+	return 0, false
+}
+
+// ActionSet holds one bit per action.
+type ActionSet uint32
+
+func (s ActionSet) Has(a Action) bool {
+	return s&(1<<a) != 0
+}
+
+func (s *ActionSet) Add(a Action) {
+	*s |= 1 << a
+}
+
+// DEBUG_MASK holds every development action.
+var DEBUG_MASK = func() ActionSet {
+	var s ActionSet
+	for a := Action(0); a < ACTION_COUNT; a++ {
+		if a.IsDebug() {
+			s.Add(a)
+		}
+	}
+	return s
+}()
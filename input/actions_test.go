@@ -0,0 +1,41 @@
+package input
+
+import "testing"
+
+func TestActionsFitAnActionSet(t *testing.T) {
+	if ACTION_COUNT > 32 {
+		t.Fatalf("%d actions don't fit the 32 bits of an ActionSet", ACTION_COUNT)
+	}
+	for a := Action(0); a < ACTION_COUNT; a++ {
+		if ACTION_NAMES[a] == "" {
+			t.Errorf("action %d has no name", a)
+		}
+	}
+}
+
+func TestDebugMask(t *testing.T) {
+	debug := map[Action]bool{
+		DEBUG_DIE:             true,
+		DEBUG_SHIFT:           true,
+		DEBUG_DECAY_UP:        true,
+		DEBUG_DECAY_DOWN:      true,
+		DEBUG_LIGHT_UP:        true,
+		DEBUG_LIGHT_DOWN:      true,
+		DEBUG_TOGGLE_LIGHTING: true,
+		DEBUG_TOGGLE_OVERLAY:  true,
+		DEBUG_NEXT_LIGHTING:   true,
+	}
+	for a := Action(0); a < ACTION_COUNT; a++ {
+		if a.IsDebug() != debug[a] || DEBUG_MASK.Has(a) != debug[a] {
+			t.Errorf("%s: IsDebug %t, in DEBUG_MASK %t, want %t", a, a.IsDebug(), DEBUG_MASK.Has(a), debug[a])
+		}
+	}
+
+	// Actions a release build must keep, the perks coming after the debug
+	// ones in the enum
+	for _, a := range []Action{MOVE_UP, MOVE_DOWN, MOVE_LEFT, MOVE_RIGHT, ATTACK, PAUSE, USE_SLOT_1, NEXT_WEAPON, PICK_PERK_1, PICK_PERK_2, PICK_PERK_3, PICK_PERK_4} {
+		if DEBUG_MASK.Has(a) {
+			t.Errorf("%s is stripped from release builds", a)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	DEFAULT_PATH     = "data/input.json"
+	DEFAULT_DEADZONE = 0.5 // How far a stick must be pushed to count
+)
+
+// Axis is one direction of a gamepad axis, e.g. the left stick pushed up.
+type Axis struct {
+	Axis     int32
+	Negative bool
+}
+
+// Binding lists every input that triggers an action.
+type Binding struct {
+	Keys    []int32
+	Buttons []int32
+	Axes    []Axis
+}
+
+// Bindings maps every action to its inputs.
+type Bindings struct {
+	Gamepad  int32   // Index of the gamepad to read
+	Deadzone float32 // How far a stick must be pushed to count
+	Actions  [ACTION_COUNT]Binding
+}
+
+// DefaultBindings returns the keyboard and gamepad layout used when the
+// bindings file doesn't override an action.
+func DefaultBindings() Bindings {
+	b := Bindings{Deadzone: DEFAULT_DEADZONE}
+
+	b.Actions[MOVE_UP] = Binding{
+		Keys:    []int32{rl.KeyW, rl.KeyUp},
+		Buttons: []int32{rl.GamepadButtonLeftFaceUp},
+		Axes:    []Axis{{rl.GamepadAxisLeftY, true}},
+	}
+	b.Actions[MOVE_DOWN] = Binding{
+		Keys:    []int32{rl.KeyS, rl.KeyDown},
+		Buttons: []int32{rl.GamepadButtonLeftFaceDown},
+		Axes:    []Axis{{rl.GamepadAxisLeftY, false}},
+	}
+	b.Actions[MOVE_LEFT] = Binding{
+		Keys:    []int32{rl.KeyA, rl.KeyLeft},
+		Buttons: []int32{rl.GamepadButtonLeftFaceLeft},
+		Axes:    []Axis{{rl.GamepadAxisLeftX, true}},
+	}
+	b.Actions[MOVE_RIGHT] = Binding{
+		Keys:    []int32{rl.KeyD, rl.KeyRight},
+		Buttons: []int32{rl.GamepadButtonLeftFaceRight},
+		Axes:    []Axis{{rl.GamepadAxisLeftX, false}},
+	}
+	b.Actions[ATTACK] = Binding{
+		Keys:    []int32{rl.KeySpace},
+		Buttons: []int32{rl.GamepadButtonRightFaceDown},
+	}
+	b.Actions[PAUSE] = Binding{
+		Keys:    []int32{rl.KeyEscape},
+		Buttons: []int32{rl.GamepadButtonMiddleRight},
+	}
+	b.Actions[TOGGLE_MAP] = Binding{
+		Keys:    []int32{rl.KeyT},
+		Buttons: []int32{rl.GamepadButtonMiddleLeft},
+	}
+	b.Actions[USE_SLOT_1] = Binding{
+		Keys:    []int32{rl.KeyOne},
+		Buttons: []int32{rl.GamepadButtonRightFaceLeft},
+	}
+	b.Actions[USE_SLOT_2] = Binding{
+		Keys:    []int32{rl.KeyTwo},
+		Buttons: []int32{rl.GamepadButtonRightFaceUp},
+	}
+	b.Actions[USE_SLOT_3] = Binding{
+		Keys:    []int32{rl.KeyThree},
+		Buttons: []int32{rl.GamepadButtonRightFaceRight},
+	}
+	b.Actions[USE_SLOT_4] = Binding{
+		Keys:    []int32{rl.KeyFour},
+		Buttons: []int32{rl.GamepadButtonLeftTrigger1},
+	}
+	b.Actions[NEXT_WEAPON] = Binding{
+		Keys:    []int32{rl.KeyQ},
+		Buttons: []int32{rl.GamepadButtonRightTrigger1},
+	}
+
+	b.Actions[REPLAY_PAUSE] = Binding{
+		Keys:    []int32{rl.KeySpace},
+		Buttons: []int32{rl.GamepadButtonRightFaceDown},
+	}
+	b.Actions[REPLAY_STEP] = Binding{
+		Keys:    []int32{rl.KeyRight},
+		Buttons: []int32{rl.GamepadButtonLeftFaceRight},
+	}
+	b.Actions[REPLAY_FASTER] = Binding{
+		Keys:    []int32{rl.KeyUp},
+		Buttons: []int32{rl.GamepadButtonLeftFaceUp},
+	}
+	b.Actions[REPLAY_SLOWER] = Binding{
+		Keys:    []int32{rl.KeyDown},
+		Buttons: []int32{rl.GamepadButtonLeftFaceDown},
+	}
+
+	b.Actions[DEBUG_DIE] = Binding{Keys: []int32{rl.KeyE}}
+	b.Actions[DEBUG_SHIFT] = Binding{Keys: []int32{rl.KeyR}}
+	b.Actions[DEBUG_DECAY_UP] = Binding{Keys: []int32{rl.KeyK}}
+	b.Actions[DEBUG_DECAY_DOWN] = Binding{Keys: []int32{rl.KeyJ}}
+	b.Actions[DEBUG_LIGHT_UP] = Binding{Keys: []int32{rl.KeyI}}
+	b.Actions[DEBUG_LIGHT_DOWN] = Binding{Keys: []int32{rl.KeyU}}
+	b.Actions[DEBUG_TOGGLE_LIGHTING] = Binding{Keys: []int32{rl.KeyL}}
+	b.Actions[DEBUG_TOGGLE_OVERLAY] = Binding{Keys: []int32{rl.KeyO}}
+	b.Actions[DEBUG_NEXT_LIGHTING] = Binding{Keys: []int32{rl.KeyP}}
+
+	return b
+}
+
+// bindingsFile is the JSON layout of the bindings file. Actions it leaves
+// out keep their default binding.
+type bindingsFile struct {
+	Gamepad  *int32                 `json:"gamepad"`
+	Deadzone *float32               `json:"deadzone"`
+	Actions  map[string]bindingJSON `json:"actions"`
+}
+
+type bindingJSON struct {
+	Keys    []string `json:"keys"`
+	Buttons []string `json:"buttons"`
+	Axes    []string `json:"axes"` // e.g. "-LEFT_Y" for the left stick pushed up
+}
+
+// LoadBindings reads the bindings file at path over the defaults. A
+// missing file just means the defaults.
+func LoadBindings(path string) (Bindings, error) {
+	b := DefaultBindings()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return b, err
+	}
+
+	var file bindingsFile
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return b, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if file.Gamepad != nil {
+		b.Gamepad = *file.Gamepad
+	}
+	if file.Deadzone != nil {
+		if *file.Deadzone <= 0 || *file.Deadzone >= 1 {
+			return b, fmt.Errorf("%s: deadzone: must be between 0 and 1, got %v", path, *file.Deadzone)
+		}
+		b.Deadzone = *file.Deadzone
+	}
+
+	var errs []error
+	for name, raw := range file.Actions {
+		action, ok := ActionByName(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: actions: unknown action %q", path, name))
+			continue
+		}
+
+		binding, err := raw.parse()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: actions.%s: %w", path, name, err))
+			continue
+		}
+		b.Actions[action] = binding
+	}
+
+	return b, errors.Join(errs...)
+}
+
+func (raw bindingJSON) parse() (Binding, error) {
+	var b Binding
+
+	for _, name := range raw.Keys {
+		key, ok := KEY_NAMES[strings.ToUpper(name)]
+		if !ok {
+			return b, fmt.Errorf("unknown key %q", name)
+		}
+		b.Keys = append(b.Keys, key)
+	}
+
+	for _, name := range raw.Buttons {
+		button, ok := BUTTON_NAMES[strings.ToUpper(name)]
+		if !ok {
+			return b, fmt.Errorf("unknown gamepad button %q", name)
+		}
+		b.Buttons = append(b.Buttons, button)
+	}
+
+	for _, name := range raw.Axes {
+		upper := strings.ToUpper(name)
+		axis := Axis{Negative: strings.HasPrefix(upper, "-")}
+		id, ok := AXIS_NAMES[strings.TrimLeft(upper, "+-")]
+		if !ok {
+			return b, fmt.Errorf("unknown gamepad axis %q", name)
+		}
+		axis.Axis = id
+		b.Axes = append(b.Axes, axis)
+	}
+
+	return b, nil
+}
+
+// KEY_NAMES are the keyboard keys the bindings file may use, letters and
+// digits go by themselves ("W", "1").
+var KEY_NAMES = func() map[string]int32 {
+	keys := map[string]int32{
+		"SPACE":         rl.KeySpace,
+		"ESCAPE":        rl.KeyEscape,
+		"ENTER":         rl.KeyEnter,
+		"TAB":           rl.KeyTab,
+		"BACKSPACE":     rl.KeyBackspace,
+		"UP":            rl.KeyUp,
+		"DOWN":          rl.KeyDown,
+		"LEFT":          rl.KeyLeft,
+		"RIGHT":         rl.KeyRight,
+		"LEFT_SHIFT":    rl.KeyLeftShift,
+		"RIGHT_SHIFT":   rl.KeyRightShift,
+		"LEFT_CONTROL":  rl.KeyLeftControl,
+		"RIGHT_CONTROL": rl.KeyRightControl,
+		"LEFT_ALT":      rl.KeyLeftAlt,
+		"RIGHT_ALT":     rl.KeyRightAlt,
+		"F1":            rl.KeyF1,
+		"F2":            rl.KeyF2,
+		"F3":            rl.KeyF3,
+		"F4":            rl.KeyF4,
+		"F5":            rl.KeyF5,
+		"F6":            rl.KeyF6,
+		"F7":            rl.KeyF7,
+		"F8":            rl.KeyF8,
+		"F9":            rl.KeyF9,
+		"F10":           rl.KeyF10,
+		"F11":           rl.KeyF11,
+		"F12":           rl.KeyF12,
+	}
+	for c := 'A'; c <= 'Z'; c++ {
+		keys[string(c)] = rl.KeyA + c - 'A'
+	}
+	for c := '0'; c <= '9'; c++ {
+		keys[string(c)] = rl.KeyZero + c - '0'
+	}
+	return keys
+}()
+
+// BUTTON_NAMES are the gamepad buttons the bindings file may use, named
+// after their position since labels differ between pads.
+var BUTTON_NAMES = map[string]int32{
+	"LEFT_FACE_UP":     rl.GamepadButtonLeftFaceUp,
+	"LEFT_FACE_RIGHT":  rl.GamepadButtonLeftFaceRight,
+	"LEFT_FACE_DOWN":   rl.GamepadButtonLeftFaceDown,
+	"LEFT_FACE_LEFT":   rl.GamepadButtonLeftFaceLeft,
+	"RIGHT_FACE_UP":    rl.GamepadButtonRightFaceUp,
+	"RIGHT_FACE_RIGHT": rl.GamepadButtonRightFaceRight,
+	"RIGHT_FACE_DOWN":  rl.GamepadButtonRightFaceDown,
+	"RIGHT_FACE_LEFT":  rl.GamepadButtonRightFaceLeft,
+	"LEFT_TRIGGER_1":   rl.GamepadButtonLeftTrigger1,
+	"LEFT_TRIGGER_2":   rl.GamepadButtonLeftTrigger2,
+	"RIGHT_TRIGGER_1":  rl.GamepadButtonRightTrigger1,
+	"RIGHT_TRIGGER_2":  rl.GamepadButtonRightTrigger2,
+	"MIDDLE_LEFT":      rl.GamepadButtonMiddleLeft,
+	"MIDDLE":           rl.GamepadButtonMiddle,
+	"MIDDLE_RIGHT":     rl.GamepadButtonMiddleRight,
+	"LEFT_THUMB":       rl.GamepadButtonLeftThumb,
+	"RIGHT_THUMB":      rl.GamepadButtonRightThumb,
+}
+
+// AXIS_NAMES are the gamepad axes the bindings file may use, prefixed with
+// "-" for their negative direction.
+var AXIS_NAMES = map[string]int32{
+	"LEFT_X":        rl.GamepadAxisLeftX,
+	"LEFT_Y":        rl.GamepadAxisLeftY,
+	"RIGHT_X":       rl.GamepadAxisRightX,
+	"RIGHT_Y":       rl.GamepadAxisRightY,
+	"LEFT_TRIGGER":  rl.GamepadAxisLeftTrigger,
+	"RIGHT_TRIGGER": rl.GamepadAxisRightTrigger,
+}
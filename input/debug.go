@@ -0,0 +1,7 @@
+//go:build !release
+
+package input
+
+// DEBUG_ACTIONS enables the development actions, build with -tags release
+// to strip them.
+const DEBUG_ACTIONS = true
@@ -0,0 +1,150 @@
+package input
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// State is the actions held and the ones that started this frame.
+type State struct {
+	Down    ActionSet
+	Pressed ActionSet
+}
+
+func (s State) IsDown(a Action) bool {
+	return s.Down.Has(a)
+}
+
+func (s State) IsPressed(a Action) bool {
+	return s.Pressed.Has(a)
+}
+
+// Next returns the state of the frame after s, where down are held.
+func (s State) Next(down ActionSet) State {
+	return State{Down: down, Pressed: down &^ s.Down}
+}
+
+// Recording is the actions held on every polled frame, enough to feed the
+// same frames back later.
+type Recording []ActionSet
+
+// Input turns devices into actions once per frame. It can record what it
+// polls, and play a recording back instead of reading the devices.
+type Input struct {
+	bindings Bindings
+	state    State
+
+	recording bool
+	recorded  Recording
+
+	playback Recording
+	played   int
+}
+
+func NewInput(bindings Bindings) *Input {
+	return &Input{bindings: bindings}
+}
+
+// Poll samples the actions of the new frame. It must be called once per
+// frame, before anything reads the state.
+func (in *Input) Poll() {
+	var down ActionSet
+	if in.playback != nil {
+		if in.played < len(in.playback) {
+			down = in.playback[in.played]
+			in.played++
+		}
+	} else {
+		down = in.sample()
+	}
+
+	if !DEBUG_ACTIONS {
+		down &^= DEBUG_MASK
+	}
+
+	in.state = in.state.Next(down)
+
+	if in.recording {
+		in.recorded = append(in.recorded, down)
+	}
+}
+
+// State returns the actions of the current frame.
+func (in *Input) State() State {
+	return in.state
+}
+
+func (in *Input) IsDown(a Action) bool {
+	return in.state.IsDown(a)
+}
+
+func (in *Input) IsPressed(a Action) bool {
+	return in.state.IsPressed(a)
+}
+
+// StartRecording forgets any previous recording and records every frame
+// polled from now on.
+func (in *Input) StartRecording() {
+	in.recording = true
+	in.recorded = nil
+}
+
+// StopRecording stops recording and returns the recorded frames.
+func (in *Input) StopRecording() Recording {
+	in.recording = false
+	return in.recorded
+}
+
+// Play makes Poll return the recorded frames in order instead of reading
+// the devices, then nothing once they run out. A nil recording goes back
+// to the devices.
+func (in *Input) Play(r Recording) {
+	in.playback = r
+	in.played = 0
+}
+
+// Playing reports whether recorded frames are left to play.
+func (in *Input) Playing() bool {
+	return in.playback != nil && in.played < len(in.playback)
+}
+
+// sample reads the keyboard and gamepad through the bindings.
+func (in *Input) sample() ActionSet {
+	var down ActionSet
+	pad := in.bindings.Gamepad
+	hasPad := rl.IsGamepadAvailable(pad)
+
+	for a := Action(0); a < ACTION_COUNT; a++ {
+		if in.bindingDown(in.bindings.Actions[a], pad, hasPad) {
+			down.Add(a)
+		}
+	}
+	return down
+}
+
+func (in *Input) bindingDown(b Binding, pad int32, hasPad bool) bool {
+	for _, key := range b.Keys {
+		if rl.IsKeyDown(key) {
+			return true
+		}
+	}
+
+	if !hasPad {
+		return false
+	}
+
+	for _, button := range b.Buttons {
+		if rl.IsGamepadButtonDown(pad, button) {
+			return true
+		}
+	}
+
+	for _, axis := range b.Axes {
+		value := rl.GetGamepadAxisMovement(pad, axis.Axis)
+		if axis.Negative {
+			value = -value
+		}
+		if value >= in.bindings.Deadzone {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,7 @@
+//go:build release
+
+package input
+
+// DEBUG_ACTIONS enables the development actions, release builds never
+// report them whatever the bindings say.
+const DEBUG_ACTIONS = false
@@ -1,14 +1,88 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"crydes/audio"
 	"crydes/core"
+	"crydes/defs"
 	"crydes/helpers"
+	"crydes/input"
+	"crydes/player"
+	"crydes/replay"
+	"crydes/sim"
+	"crydes/world"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 func main() {
+	seed := flag.Int64("seed", 0, "dungeon seed (0 picks a random one)")
+	headless := flag.Bool("headless", false, "run the simulation without a window and exit")
+	ticks := flag.Int("ticks", 1200, "number of ticks to simulate in headless mode")
+	replayPath := flag.String("replay", "", "watch a recorded run, or check it still plays the same with -headless")
+	generator := flag.String("generator", "", "lay out every floor with one generator: bsp, caves, tunnels or prefabs")
+	validate := flag.Int("validate", 0, "check the first floors of this many seeds, starting from -seed, and exit")
+	size := flag.String("size", fmt.Sprintf("%dx%d", helpers.MAP_WIDTH, helpers.MAP_HEIGHT), "tiles across and down the top floor, as WIDTHxHEIGHT")
+	flag.Parse()
+
+	if _, exists := world.GENERATORS[*generator]; *generator != "" && !exists {
+		fmt.Fprintf(os.Stderr, "unknown generator %q, expected one of %v\n", *generator, world.GENERATOR_ORDER)
+		os.Exit(1)
+	}
+
+	var width, height int
+	if _, err := fmt.Sscanf(*size, "%dx%d", &width, &height); err != nil ||
+		width < helpers.MIN_MAP_SIZE || width > helpers.MAX_MAP_SIZE ||
+		height < helpers.MIN_MAP_SIZE || height > helpers.MAX_MAP_SIZE {
+		fmt.Fprintf(os.Stderr, "invalid size %q, expected WIDTHxHEIGHT between %d and %d tiles\n", *size, helpers.MIN_MAP_SIZE, helpers.MAX_MAP_SIZE)
+		os.Exit(1)
+	}
+	config := world.Config{Generator: *generator, Width: width, Height: height}
+
+	if *seed == 0 {
+		*seed = helpers.RandomSeed()
+	}
+
+	// Definitions are loaded up front so mistakes in them are reported
+	// before anything opens
+	if _, err := defs.Load(defs.DEFAULT_DIR); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid definitions:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	bindings, err := input.LoadBindings(input.DEFAULT_PATH)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid input bindings:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var rec *replay.Replay
+	if *replayPath != "" {
+		if rec, err = replay.Read(*replayPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if *validate > 0 {
+		validateSeeds(*seed, *validate, config)
+		return
+	}
+
+	if *headless {
+		if rec != nil {
+			verifyReplay(rec)
+			return
+		}
+		runHeadless(*seed, *ticks, config)
+		return
+	}
+
 	// Set up monitor info for fullscreen
 	var screenWidth, screenHeight int32
 	if helpers.FULLSCREEN {
@@ -35,9 +109,12 @@ func main() {
 	soundManager := audio.NewSoundManager()
 	defer soundManager.Unload()
 
-	game := core.NewGame(soundManager, int(screenWidth), int(screenHeight))
+	app := core.NewApp(soundManager, input.NewInput(bindings), int(screenWidth), int(screenHeight), *seed, config)
+	if rec != nil {
+		app.WatchReplay(rec)
+	}
 
-	for !rl.WindowShouldClose() {
+	for !rl.WindowShouldClose() && !app.ShouldQuit() {
 		// Handle fullscreen toggle
 		if rl.IsKeyPressed(rl.KeyEnter) && (rl.IsKeyDown(rl.KeyLeftAlt) || rl.IsKeyDown(rl.KeyRightAlt)) {
 			if rl.IsWindowFullscreen() {
@@ -50,6 +127,63 @@ func main() {
 			}
 		}
 
-		game.Run()
+		app.Frame()
+	}
+}
+
+// runHeadless steps an idle player through a simulated run and prints
+// where it ended up, without opening a window or an audio device.
+func runHeadless(seed int64, ticks int, config world.Config) {
+	s := sim.New(seed, config, nil, true)
+	s.Run(ticks, func(int) player.Controls { return player.Controls{} })
+
+	fmt.Printf("seed %d after %d ticks: floor %d, health %d, keys %d, enemies %d, killed %d\n",
+		seed, s.Ticks, s.Depth(), s.Player.Health, s.Player.KeysCollected, len(s.Enemies.Enemies), s.Enemies.KilledCount)
+}
+
+// validateSeeds checks the first floors of count runs from seed onwards,
+// prints the average metrics and exits with an error if a floor left
+// something out of reach.
+func validateSeeds(seed int64, count int, config world.Config) {
+	const floors = 3
+
+	var total world.Metrics
+	reports, invalid, locks := 0, 0, 0
+	for i := 0; i < count; i++ {
+		for _, r := range sim.ValidateSeed(seed+int64(i), floors, config) {
+			if !r.Valid() {
+				invalid++
+				fmt.Printf("seed %d floor %d: %d rooms unreachable, %d things unreachable, key reachable %t, doors unlockable %t\n",
+					seed+int64(i), r.Depth, r.UnreachableRooms, r.Unreachable, r.HasKey, r.Unlockable)
+			}
+			locks += r.Locks
+			total.Rooms += r.Rooms
+			total.CorridorTiles += r.CorridorTiles
+			total.DeadEnds += r.DeadEnds
+			total.Loops += r.Loops
+			reports++
+		}
+	}
+
+	fmt.Printf("%d floors from seed %d, %d invalid. per floor: %.1f rooms, %.0f corridor tiles, %.1f dead ends, %.1f loops, %.1f locked doors\n",
+		reports, seed, invalid,
+		float32(total.Rooms)/float32(reports), float32(total.CorridorTiles)/float32(reports),
+		float32(total.DeadEnds)/float32(reports), float32(total.Loops)/float32(reports),
+		float32(locks)/float32(reports))
+	if invalid > 0 {
+		os.Exit(1)
 	}
 }
+
+// verifyReplay plays a recorded run headlessly and exits with an error if
+// it no longer ends the way it did when it was recorded.
+func verifyReplay(r *replay.Replay) {
+	outcome, err := r.Verify()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("replay of seed %d after %d ticks: health %d, keys %d, killed %d\n",
+		r.Seed, outcome.Ticks, outcome.Health, outcome.Keys, outcome.Killed)
+}
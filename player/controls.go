@@ -0,0 +1,50 @@
+package player
+
+import "crydes/input"
+
+// Controls is the input the player state machine reads on every update.
+// The game maps it from the input actions, a headless simulation sets it
+// directly.
+type Controls struct {
+	Up, Down, Left, Right bool
+
+	Attack     bool // Pressed this frame
+	NextWeapon bool // Pressed this frame
+	Use        int  // Hotbar slot used this frame, counting from 1, 0 for none
+	Perk       int  // Offered perk picked this frame, counting from 1, 0 for none
+	Die        bool // Development shortcut, pressed this frame
+}
+
+// ControlsFrom maps the actions of a frame to the player's controls.
+func ControlsFrom(s input.State) Controls {
+	c := Controls{
+		Up:         s.IsDown(input.MOVE_UP),
+		Down:       s.IsDown(input.MOVE_DOWN),
+		Left:       s.IsDown(input.MOVE_LEFT),
+		Right:      s.IsDown(input.MOVE_RIGHT),
+		Attack:     s.IsPressed(input.ATTACK),
+		NextWeapon: s.IsPressed(input.NEXT_WEAPON),
+		Die:        s.IsPressed(input.DEBUG_DIE),
+	}
+
+	for i, slot := range []input.Action{input.USE_SLOT_1, input.USE_SLOT_2, input.USE_SLOT_3, input.USE_SLOT_4} {
+		if s.IsPressed(slot) {
+			c.Use = i + 1
+			break
+		}
+	}
+
+	for i, pick := range []input.Action{input.PICK_PERK_1, input.PICK_PERK_2, input.PICK_PERK_3, input.PICK_PERK_4} {
+		if s.IsPressed(pick) {
+			c.Perk = i + 1
+			break
+		}
+	}
+
+	// The perk picker shares its keys with the hotbar, the key picking a
+	// perk is still held on the tick the pick lands and mustn't use an item
+	if c.Perk != 0 {
+		c.Use = 0
+	}
+	return c
+}
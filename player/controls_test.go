@@ -0,0 +1,37 @@
+package player
+
+import (
+	"crydes/input"
+	"testing"
+)
+
+func TestControlsFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev      []input.Action // Held on the tick before
+		down      []input.Action
+		use, perk int
+	}{
+		{"hotbar slot", nil, []input.Action{input.USE_SLOT_2}, 2, 0},
+		{"held hotbar slot", []input.Action{input.USE_SLOT_2}, []input.Action{input.USE_SLOT_2}, 0, 0},
+		{"perk pick", nil, []input.Action{input.PICK_PERK_3}, 0, 3},
+		{"perk picked with the hotbar key", nil, []input.Action{input.USE_SLOT_1, input.PICK_PERK_1}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prev, down input.ActionSet
+			for _, a := range tt.prev {
+				prev.Add(a)
+			}
+			for _, a := range tt.down {
+				down.Add(a)
+			}
+
+			c := ControlsFrom(input.State{Down: prev}.Next(down))
+			if c.Use != tt.use || c.Perk != tt.perk {
+				t.Errorf("use %d, perk %d, want use %d, perk %d", c.Use, c.Perk, tt.use, tt.perk)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package player
+
+import (
+	"crydes/events"
+	helpers "crydes/helpers"
+	wrld "crydes/world"
+	"fmt"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	HOTBAR_SLOTS = 4 // Slots of the inventory, used with the 1-4 keys
+	STACK_SIZE   = 5 // Most items of a kind a slot holds
+)
+
+// Slot holds a stack of stored items of one kind, the pickup they came
+// from tells what using one does.
+type Slot struct {
+	Item  events.ItemCollected
+	Count int
+}
+
+// store puts the item in the slot already holding its kind, or in the first
+// empty one. It reports false when there's no room for it.
+func (p *Player) store(item events.ItemCollected) bool {
+	free := -1
+	for i := range p.Inventory {
+		slot := &p.Inventory[i]
+		if slot.Count > 0 && slot.Item.Item == item.Item {
+			if slot.Count >= STACK_SIZE {
+				return false
+			}
+			slot.Count++
+			return true
+		}
+		if slot.Count == 0 && free < 0 {
+			free = i
+		}
+	}
+
+	if free < 0 {
+		return false
+	}
+	p.Inventory[free] = Slot{Item: item, Count: 1}
+	return true
+}
+
+// UseSlot uses one item of the hotbar slot, potions are kept while they
+// would be wasted.
+func (p *Player) UseSlot(i int) {
+	if i < 0 || i >= HOTBAR_SLOTS || p.Inventory[i].Count == 0 {
+		return
+	}
+	slot := &p.Inventory[i]
+
+	if slot.Item.Effect == "heal" && p.Health >= p.MaxHealth {
+		p.ShowMessage(MSG_FULL_HEALTH)
+		return
+	}
+
+	p.applyItem(slot.Item)
+	if slot.Count--; slot.Count == 0 {
+		*slot = Slot{}
+	}
+}
+
+// itemName is how an item is called in messages.
+func itemName(item string) string {
+	return strings.ReplaceAll(item, "_", " ")
+}
+
+// RenderHotbar draws the inventory slots next to the hearts, with the key
+// that uses each one and how many items it holds.
+func (p *Player) RenderHotbar() {
+	const (
+		slotSize = float32(40)
+		padding  = float32(5)
+	)
+	heartsWidth := (float32(8)*5+padding)*float32(p.MaxHealth) + padding
+	startX := 20 + heartsWidth + padding*2
+	startY := float32(rl.GetScreenHeight()) - slotSize - 20
+
+	bgRect := rl.Rectangle{
+		X:      startX - padding,
+		Y:      startY - padding,
+		Width:  (slotSize+padding)*HOTBAR_SLOTS + padding,
+		Height: slotSize + padding*2,
+	}
+	rl.DrawRectangle(int32(bgRect.X), int32(bgRect.Y), int32(bgRect.Width), int32(bgRect.Height), rl.NewColor(0, 0, 0, 100))
+	rl.DrawRectangleLinesEx(bgRect, 2, rl.ColorAlpha(rl.White, 0.3))
+
+	for i, slot := range p.Inventory {
+		x := startX + (slotSize+padding)*float32(i)
+		rl.DrawRectangleLinesEx(rl.NewRectangle(x, startY, slotSize, slotSize), 1, rl.ColorAlpha(rl.White, 0.2))
+		rl.DrawText(fmt.Sprint(i+1), int32(x)+3, int32(startY)+2, 10, rl.ColorAlpha(rl.White, 0.6))
+
+		if slot.Count == 0 {
+			continue
+		}
+		if icon := p.slotIcon(wrld.ItemType(slot.Item.Item)); icon != nil {
+			scale := (slotSize - 8) / float32(icon.Width)
+			rl.DrawTextureEx(*icon, rl.NewVector2(x+4, startY+4), 0, scale, rl.White)
+		}
+		if slot.Count > 1 {
+			rl.DrawText(fmt.Sprint(slot.Count), int32(x+slotSize)-10, int32(startY+slotSize)-12, 10, rl.White)
+		}
+	}
+}
+
+// RenderCoins draws the coins the player holds right of the hotbar.
+func (p *Player) RenderCoins() {
+	const padding = float32(5)
+	heartsWidth := (float32(8)*5+padding)*float32(p.MaxHealth) + padding
+	hotbarWidth := (40+padding)*HOTBAR_SLOTS + padding
+	x := 20 + heartsWidth + padding*2 + hotbarWidth + padding*2
+	y := float32(rl.GetScreenHeight()) - 40 - 20
+
+	rl.DrawRectangle(int32(x-padding), int32(y-padding), 80, int32(40+padding*2), rl.NewColor(0, 0, 0, 100))
+	rl.DrawRectangleLinesEx(rl.NewRectangle(x-padding, y-padding, 80, 40+padding*2), 2, rl.ColorAlpha(rl.White, 0.3))
+	if icon := p.slotIcon(wrld.Coin); icon != nil {
+		rl.DrawTextureEx(*icon, rl.NewVector2(x, y+8), 0, 1.5, rl.White)
+	}
+	rl.DrawText(fmt.Sprint(p.Coins), int32(x)+30, int32(y)+12, 20, rl.Gold)
+}
+
+// slotIcon returns the first frame of the item's animation, loaded the
+// first time the item is shown.
+func (p *Player) slotIcon(item wrld.ItemType) *rl.Texture2D {
+	if p.slotIcons == nil {
+		p.slotIcons = make(map[wrld.ItemType]*helpers.Animation)
+	}
+	anim, loaded := p.slotIcons[item]
+	if !loaded {
+		anim = wrld.LoadItemAnimation(item, p.Map.Headless())
+		p.slotIcons[item] = anim
+	}
+	if anim == nil || len(anim.Frames) == 0 {
+		return nil
+	}
+	return &anim.Frames[0]
+}
@@ -2,42 +2,47 @@ package player
 
 import (
 	"crydes/audio"
+	"crydes/combat"
+	"crydes/defs"
 	effects "crydes/effects/particle"
+	"crydes/events"
 	helpers "crydes/helpers"
+	"crydes/status"
 	wrld "crydes/world"
 	"fmt"
 
-	"time"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
-	MAX_KEYS = 5
-)
+	MAX_KEYS      = 5
+	MAX_HEALTH    = 5   // Hearts the player starts with
+	VICTORY_DELAY = 2.0 // Seconds between the boss falling and the victory
 
-type Effect struct {
-	Type      string
-	Value     float32
-	Duration  time.Duration
-	ExpiresAt time.Time
-}
+	// Size of a player frame. Gameplay measures the player from it so a
+	// headless run, which loads no textures, hits and aims the same way.
+	FRAME_SIZE = 16
+)
 
 type Player struct {
-	Position rl.Vector2
-	Speed    float32
-	Health   int
-	Scale    float32
+	Position  rl.Vector2
+	Previous  rl.Vector2 // Position at the start of the last tick, for interpolated rendering
+	Speed     float32
+	Health    int
+	MaxHealth int // Hearts the player can heal up to, raised by heart containers
+	Scale     float32
 
 	CurrentAnim *helpers.Animation
 	Animations  map[string]*helpers.Animation
 
-	Map   *wrld.Map
-	Sword *Sword
+	Map     *wrld.Map
+	Sword   *Sword    // Swing of the melee weapon in hand
+	Weapons []*Weapon // Carried, the sword first
+	Weapon  int       // Index of the weapon in hand
+	Shots   []*Shot   // Shot or thrown, still flying or about to go off
 
-	DamageChan     chan bool
 	IsTakingDamage bool
-	AttackChan     chan rl.Rectangle
+	invulnerable   combat.Timer // Spares the player right after a hit
 
 	LastDirection  string
 	State          string // Add a state field to track the current state
@@ -45,54 +50,68 @@ type Player struct {
 	heartParticles *effects.ParticleSystem
 	lastHealth     int
 
-	audio         *audio.SoundManager
-	effectsChan   <-chan wrld.ItemEffectEvent
-	ActiveEffects map[string]*Effect
+	audio        *audio.SoundManager
+	bus          *events.Bus
+	Effects      status.Effects // Boosts, poisons and the like running on the player
+	victoryTimer float32        // Counts down to the victory once the boss is defeated
+
+	Controls Controls // Input for the next update
 
 	TextBubble    *TextBubble
 	KeysCollected int
+	DoorKeys      map[wrld.ItemType]int // Keys to locked doors held, by item
+	Coins         int
+	XP            int                // Experience towards the next level
+	Level         int                // Starts at 1
+	PerkPoints    int                // Levels gained whose perk isn't picked yet
+	Perks         map[string]int     // Ranks of the perks picked, by name
+	Inventory     [HOTBAR_SLOTS]Slot // Items stored to be used later
+	slotIcons     map[wrld.ItemType]*helpers.Animation
 	KeyTexture    rl.Texture2D
-	lastStepTime  time.Time
+	stepTimer     float32 // Seconds until the next footstep can be heard
 }
 
-func NewPlayer(x, y float32, mp *wrld.Map, sm *audio.SoundManager, effectsChan <-chan wrld.ItemEffectEvent) *Player {
-	idleRight := helpers.LoadAnimation("IDLE_R",
+// NewPlayer creates a player that takes damage and item effects from the
+// bus. The bus may be nil for a player nobody interacts with.
+func NewPlayer(x, y float32, mp *wrld.Map, sm *audio.SoundManager, bus *events.Bus) *Player {
+	headless := mp.Headless()
+	idleRight := helpers.LoadAnimation(headless, "IDLE_R",
 		"assets/player/1.png",
 		"assets/player/2.png",
 		"assets/player/3.png",
 	)
-	moveRight := helpers.LoadAnimation("MOV_R",
+	moveRight := helpers.LoadAnimation(headless, "MOV_R",
 		"assets/player/15.png",
 		"assets/player/16.png",
 		"assets/player/17.png",
 		"assets/player/18.png",
 	)
-	idleLeft := helpers.LoadAnimation("IDLE_L",
+	idleLeft := helpers.LoadAnimation(headless, "IDLE_L",
 		"assets/player/8.png",
 		"assets/player/9.png",
 		"assets/player/10.png",
 	)
-	moveLeft := helpers.LoadAnimation("MOV_L",
+	moveLeft := helpers.LoadAnimation(headless, "MOV_L",
 		"assets/player/22.png",
 		"assets/player/23.png",
 		"assets/player/24.png",
 		"assets/player/25.png",
 	)
-	damageLeft := helpers.LoadAnimation("DAMAGE_R",
+	damageLeft := helpers.LoadAnimation(headless, "DAMAGE_R",
 		"assets/player/29.png",
 		"assets/player/30.png",
 		"assets/player/31.png",
 		"assets/player/32.png",
 		"assets/player/33.png",
 	)
-	damageRight := helpers.LoadAnimation("DAMAGE_L",
+	damageRight := helpers.LoadAnimation(headless, "DAMAGE_L",
 		"assets/player/36.png",
 		"assets/player/37.png",
 		"assets/player/38.png",
 		"assets/player/39.png",
 		"assets/player/40.png",
 	)
-	die := helpers.LoadAnimation("DIE",
+	die := helpers.LoadAnimation(headless, "DIE",
 		"assets/player/57.png",
 		"assets/player/58.png",
 		"assets/player/59.png",
@@ -102,11 +121,13 @@ func NewPlayer(x, y float32, mp *wrld.Map, sm *audio.SoundManager, effectsChan <
 		"assets/player/63.png",
 	)
 
-	heartTexture := rl.LoadTexture("assets/ui/heart.png")
-	keyTexture := rl.LoadTexture("assets/ui/key.png")
+	sword := newWeapon(defs.Get().Weapons["sword"], 0, headless)
+	heartTexture := helpers.LoadTexture(headless, "assets/ui/heart.png")
+	keyTexture := helpers.LoadTexture(headless, "assets/ui/key.png")
 
 	p := &Player{
 		Position: rl.NewVector2(x, y),
+		Previous: rl.NewVector2(x, y),
 		Speed:    200.0,
 		Animations: map[string]*helpers.Animation{
 			"idle_right":   idleRight,
@@ -123,37 +144,51 @@ func NewPlayer(x, y float32, mp *wrld.Map, sm *audio.SoundManager, effectsChan <
 		Sword: NewSword(
 			rl.NewVector2(-8, -4),
 			"right",
+			sword.anim,
 		),
-		DamageChan:     make(chan bool, 10),
-		AttackChan:     make(chan rl.Rectangle, 10),
-		Health:         5,
+		Weapons:        []*Weapon{sword},
+		Health:         MAX_HEALTH,
+		MaxHealth:      MAX_HEALTH,
 		Scale:          0.5,
 		HeartTexture:   heartTexture,
 		heartParticles: effects.NewParticleSystem(),
-		lastHealth:     5,
+		lastHealth:     MAX_HEALTH,
 		audio:          sm,
-		effectsChan:    effectsChan,
-		ActiveEffects:  make(map[string]*Effect),
-		TextBubble:     NewTextBubble(),
+		bus:            bus,
+		TextBubble:     NewTextBubble(headless),
 		KeysCollected:  0,
+		DoorKeys:       make(map[wrld.ItemType]int),
+		Level:          1,
+		Perks:          make(map[string]int),
 		KeyTexture:     keyTexture,
-		lastStepTime:   time.Now(),
 	}
 
 	// Show initial tutorial message
 	p.TextBubble.ShowMessage(MSG_MOVEMENT)
 
-	go p.listenForEffects()
-	go p.listenForDamage()
+	events.Subscribe(bus, p.onDamage)
+	events.Subscribe(bus, p.onItemCollected)
 
 	return p
 }
 
-const MOV_SPEED = 0.006
+const (
+	MOV_SPEED  = 0.36 // Pixels per second per point of Speed
+	STEP_DELAY = 0.2  // Seconds between two footstep sounds
+)
 
 func (p *Player) Update(refreshRate float32) {
 	// Update effects at the start of each frame
-	p.updateEffects()
+	p.Effects.Update(p, refreshRate)
+	p.updateWeapons(refreshRate)
+	p.invulnerable.Update(refreshRate)
+
+	if p.victoryTimer > 0 {
+		p.victoryTimer -= refreshRate
+		if p.victoryTimer <= 0 {
+			p.State = "victory"
+		}
+	}
 
 	if p.CheckHealth(); p.State == "dying" {
 		p.CurrentAnim = p.Animations["die"]
@@ -161,10 +196,20 @@ func (p *Player) Update(refreshRate float32) {
 		return
 	}
 
+	if p.Controls.Use > 0 {
+		p.UseSlot(p.Controls.Use - 1)
+	}
+	if p.Controls.NextWeapon {
+		p.NextWeapon()
+	}
+	if p.Controls.Perk > 0 {
+		p.ChoosePerk(p.Controls.Perk - 1)
+	}
+
 	switch p.State {
 	case "taking_damage":
 		// Let the damage animation play out; no other actions allowed.
-		p.HandlePlayerMovement()
+		p.HandlePlayerMovement(refreshRate)
 
 		if p.Health == 2 {
 			p.ShowMessage(MSG_LOW_HEALTH)
@@ -172,7 +217,7 @@ func (p *Player) Update(refreshRate float32) {
 
 		p.CurrentAnim = p.Animations["damage_"+p.LastDirection]
 
-		if rl.IsKeyPressed(rl.KeySpace) {
+		if p.Controls.Attack {
 			p.Attack()
 		}
 
@@ -189,13 +234,13 @@ func (p *Player) Update(refreshRate float32) {
 
 	default:
 		// Allow player to move and attack if not taking damage or dying.
-		moving := p.HandlePlayerMovement()
+		moving := p.HandlePlayerMovement(refreshRate)
 
 		if !moving {
 			p.SetIdleAnimation()
 		}
 
-		if rl.IsKeyPressed(rl.KeySpace) {
+		if p.Controls.Attack {
 			p.Attack()
 		}
 
@@ -209,7 +254,7 @@ func (p *Player) Update(refreshRate float32) {
 
 		//! TEMPORATRRARAR
 
-		if rl.IsKeyPressed(rl.KeyE) {
+		if p.Controls.Die {
 			p.Die()
 		}
 
@@ -233,15 +278,23 @@ func (p *Player) Update(refreshRate float32) {
 	}
 }
 
-func (p *Player) HandlePlayerMovement() bool {
+func (p *Player) HandlePlayerMovement(deltaTime float32) bool {
 	// Determine target positions based on current position and speed
 	targetX, targetY := p.Position.X, p.Position.Y
 	moved := false
-	// fmt.Println(p.Speed)
+	deltaTime *= p.Map.SpeedAt(p.Position) // Wading slows the player down
+	speed := p.Speed * p.Effects.Multiplier("speed")
+
+	// Confusion turns every direction around
+	controls := p.Controls
+	if p.Effects.Has("confusion") {
+		controls.Left, controls.Right = controls.Right, controls.Left
+		controls.Up, controls.Down = controls.Down, controls.Up
+	}
 
 	// Horizontal movement
-	if rl.IsKeyDown(rl.KeyRight) || rl.IsKeyDown(rl.KeyD) {
-		targetX += p.Speed * MOV_SPEED
+	if controls.Right {
+		targetX += speed * MOV_SPEED * deltaTime
 		if p.IsTargetPositionWalkable(targetX, p.Position.Y) {
 			p.Position.X = targetX
 			p.CurrentAnim = p.Animations["move_right"]
@@ -249,8 +302,8 @@ func (p *Player) HandlePlayerMovement() bool {
 			moved = true
 		}
 	}
-	if rl.IsKeyDown(rl.KeyLeft) || rl.IsKeyDown(rl.KeyA) {
-		targetX -= p.Speed * MOV_SPEED
+	if controls.Left {
+		targetX -= speed * MOV_SPEED * deltaTime
 		if p.IsTargetPositionWalkable(targetX, p.Position.Y) {
 			p.Position.X = targetX
 			p.CurrentAnim = p.Animations["move_left"]
@@ -260,16 +313,16 @@ func (p *Player) HandlePlayerMovement() bool {
 	}
 
 	// Vertical movement
-	if rl.IsKeyDown(rl.KeyUp) || rl.IsKeyDown(rl.KeyW) {
-		targetY -= p.Speed * MOV_SPEED
+	if controls.Up {
+		targetY -= speed * MOV_SPEED * deltaTime
 		if p.IsTargetPositionWalkable(p.Position.X, targetY) {
 			p.Position.Y = targetY
 			moved = true
 			p.CurrentAnim = p.Animations["move_"+p.LastDirection]
 		}
 	}
-	if rl.IsKeyDown(rl.KeyDown) || rl.IsKeyDown(rl.KeyS) {
-		targetY += p.Speed * MOV_SPEED
+	if controls.Down {
+		targetY += speed * MOV_SPEED * deltaTime
 		if p.IsTargetPositionWalkable(p.Position.X, targetY) {
 			p.Position.Y = targetY
 			moved = true
@@ -278,14 +331,10 @@ func (p *Player) HandlePlayerMovement() bool {
 	}
 
 	// Play footstep sound if we moved, with debounce
-	if moved {
-		now := time.Now()
-		stepDelay := 200 * time.Millisecond // Adjust this value to control footstep frequency
-
-		if now.Sub(p.lastStepTime) >= stepDelay {
-			p.audio.RequestSound("step", 0.5, 1.0)
-			p.lastStepTime = now
-		}
+	p.stepTimer -= deltaTime
+	if moved && p.stepTimer <= 0 {
+		p.audio.RequestSound("step", 0.5, 1.0)
+		p.stepTimer = STEP_DELAY
 	}
 
 	return moved
@@ -350,52 +399,52 @@ func (p *Player) ConvertToMapPosition() (int, int) {
 }
 
 func (p *Player) Render() {
-	// Draw the current animation frame.
-	rl.DrawTextureEx(p.CurrentAnim.Frames[p.CurrentAnim.CurrentFrame], p.Position, 0, p.Scale, rl.White)
+	// Draw the current animation frame, blinking while invulnerable.
+	tint := rl.White
+	if p.invulnerable.Running() && p.State != "taking_damage" && int(p.invulnerable*10)%2 == 0 {
+		tint = rl.ColorAlpha(rl.White, 0.4)
+	}
+	if p.Effects.Has("invisibility") {
+		tint = rl.ColorAlpha(tint, 0.3)
+	}
+	rl.DrawTextureEx(p.CurrentAnim.Frames[p.CurrentAnim.CurrentFrame], p.Position, 0, p.Scale, tint)
 
 	// Render the sword if visible.
 	p.Sword.Render()
 
+	for _, s := range p.Shots {
+		s.Render()
+	}
+
 }
 
-// TakeDamage method to trigger the damage effect
-func (p *Player) TakeDamage() {
-	// If already taking damage or dying, ignore further damage.
-	if p.State == "taking_damage" || p.State == "dying" {
-		return
+// TakeDamage hurts the player by amount hearts, unless they're dying or
+// still invulnerable from the last hit. A shield soaks up what it can. It
+// reports whether the player was hurt.
+func (p *Player) TakeDamage(amount int) bool {
+	if p.State == "dying" || p.invulnerable.Running() {
+		return false
+	}
+	if amount = p.Effects.Absorb(p, amount); amount <= 0 {
+		p.invulnerable.Start(combat.PLAYER_INVULNERABILITY)
+		p.audio.RequestSound("sword_hit", 0.8, 1.5)
+		return false
+	}
+	if !combat.Hurt(&p.Health, amount, &p.invulnerable, combat.PLAYER_INVULNERABILITY) {
+		return false
 	}
 
 	p.audio.RequestSound("damage", 1.0, 1.0)
 	// Change the player's state to taking damage.
 	p.State = "taking_damage"
-	p.DamageChan <- true
-}
-
-// listenForDamage listens for damage events and handles them
-func (p *Player) listenForDamage() {
-	for {
-		select {
-		case <-p.DamageChan:
-			// Set the damage animation and disable other actions.
-
-			p.Health--
-			helpers.DEBUG("Player Health", p.Health)
-
-			p.IsTakingDamage = true
-
-			// helpers.DEBUG("Player Taking Damage DIRECTION : ", p.LastDirection)
-			p.CurrentAnim = p.Animations["damage_"+p.LastDirection]
-
-			// Wait for the duration of the damage animation to complete.
-			// time.Sleep(helpers.DAMAGE_DURATION)
-
-			// Reset to idle state if not dead.
-			// if p.State != "dying" {
-			// 	p.IsTakingDamage = false
-			// 	p.State = "idle" // Reset the state to idle
-			// }
-		}
-	}
+	helpers.DEBUG("Player Health", p.Health)
+	center := p.GetPlayerCenterPoint()
+	p.bus.Publish(events.DamageTaken{Position: rl.NewVector2(center.X, p.Position.Y), Amount: amount, Player: true})
+
+	// Set the damage animation, it disables other actions until it ends
+	p.IsTakingDamage = true
+	p.CurrentAnim = p.Animations["damage_"+p.LastDirection]
+	return true
 }
 
 func (p *Player) CheckHealth() {
@@ -410,16 +459,6 @@ func (p *Player) Die() {
 	p.audio.RequestSound("death", 1.0, 1.0)
 }
 
-func (p *Player) Attack() {
-	p.Sword.Visible = true
-	// p.Sword.ResetAttack()
-	area := p.Sword.GetSwordRect()
-
-	helpers.DEBUG("Player Attack", area)
-
-	p.AttackChan <- area
-}
-
 func (p *Player) GameHasEnded() bool {
 	return p.State == "dying" && p.CurrentAnim.CurrentFrame == len(p.CurrentAnim.Frames)-1
 }
@@ -443,8 +482,8 @@ func (p *Player) GetPlayerRoom() int {
 
 func (p *Player) GetPlayerCenterPoint() rl.Vector2 {
 	return rl.NewVector2(
-		p.Position.X+float32(p.CurrentAnim.Frames[0].Width/2)*p.Scale,
-		p.Position.Y+float32(p.CurrentAnim.Frames[0].Width/2)*p.Scale,
+		p.Position.X+float32(FRAME_SIZE/2)*p.Scale,
+		p.Position.Y+float32(FRAME_SIZE/2)*p.Scale,
 	)
 }
 
@@ -457,7 +496,7 @@ func (p *Player) RenderHearts() {
 	startY := float32(rl.GetScreenHeight() - int(heartSize) - 20)
 
 	// Draw blurry background - make it taller to accommodate effects
-	totalWidth := (heartSize+padding)*float32(5) + padding
+	totalWidth := (heartSize+padding)*float32(p.MaxHealth) + padding
 	// effectHeight := float32(30) // Height for effect indicators
 	bgRect := rl.Rectangle{
 		X:      startX - padding,
@@ -476,72 +515,7 @@ func (p *Player) RenderHearts() {
 	// Draw border
 	rl.DrawRectangleLinesEx(bgRect, 2, rl.ColorAlpha(rl.White, 0.3))
 
-	// Move effects to bottom center
-	// effectScale := float32(2.0)
-	effectWidth := float32(100) // Base width for each effect
-	effectHeight := float32(30)
-	effectPadding := float32(5)
-
-	// Calculate total width needed for all possible effects
-	maxEffects := 2 // speed and poison
-	totalEffectWidth := (effectWidth+effectPadding)*float32(maxEffects) - effectPadding
-
-	// Center position calculation
-	effectStartX := float32(rl.GetScreenWidth())/2 - totalEffectWidth/2
-	effectStartY := float32(rl.GetScreenHeight() - int(effectHeight) - 20)
-
-	// Draw blurry background for effects
-	effectBgRect := rl.Rectangle{
-		X:      effectStartX - effectPadding,
-		Y:      effectStartY - effectPadding,
-		Width:  totalEffectWidth + effectPadding*2,
-		Height: effectHeight + effectPadding*2,
-	}
-	rl.DrawRectangle(
-		int32(effectBgRect.X),
-		int32(effectBgRect.Y),
-		int32(effectBgRect.Width),
-		int32(effectBgRect.Height),
-		rl.NewColor(0, 0, 0, 100),
-	)
-
-	// Draw border for effects
-	rl.DrawRectangleLinesEx(effectBgRect, 2, rl.ColorAlpha(rl.White, 0.3))
-
-	// Render active effects
-	effectX := effectStartX
-	for effectType, effect := range p.ActiveEffects {
-		remaining := effect.ExpiresAt.Sub(time.Now())
-		if remaining > 0 {
-			effectColor := rl.White
-			effectText := effectType
-			switch effectType {
-			case "speed":
-				effectColor = rl.Green
-				effectText = "Speed Boost"
-			case "poison":
-				effectColor = rl.Purple
-				effectText = "Poisoned"
-			}
-
-			// Draw effect name (smaller text)
-			rl.DrawText(effectText, int32(effectX), int32(effectStartY+5), 12, effectColor)
-
-			// Draw timer bar
-			barWidth := effectWidth
-			barHeight := float32(5)
-			progress := float32(remaining) / float32(effect.Duration)
-			rl.DrawRectangle(
-				int32(effectX),
-				int32(effectStartY)+20,
-				int32(barWidth*progress),
-				int32(barHeight),
-				effectColor,
-			)
-
-			effectX += effectWidth + effectPadding
-		}
-	}
+	p.renderEffects()
 
 	// Check if health has decreased
 	if p.Health < p.lastHealth {
@@ -607,110 +581,92 @@ func (p *Player) RenderHearts() {
 		}
 		rl.DrawTextureEx(p.KeyTexture, position, 0, keyScale, color)
 	}
-}
 
-func (p *Player) updateEffects() {
-	now := time.Now()
-	for effectType, effect := range p.ActiveEffects {
-		if now.After(effect.ExpiresAt) {
-			println("death")
-			// Remove expired effect and restore original values
-			switch effectType {
-			case "speed":
-				println("death 1")
-				p.Speed /= effect.Value // Restore original speed
-			case "poison":
-				// Stop taking poison damage
-			}
-			println("death 2")
-			delete(p.ActiveEffects, effectType)
+	// Keys to locked doors go below, tinted like their locks
+	position := rl.Vector2{X: keyStartX, Y: keyStartY + keySize + padding*2}
+	for lock, key := range wrld.LOCK_KEYS {
+		for i := 0; i < p.DoorKeys[key]; i++ {
+			rl.DrawTextureEx(p.KeyTexture, position, 0, keyScale/2, wrld.Lock(lock).Color())
+			position.X += keySize/2 + padding
 		}
 	}
 }
 
-func (p *Player) applyEffect(effectType string, value float32, duration time.Duration) {
-	// Remove existing effect of the same type if it exists
-	if existing, exists := p.ActiveEffects[effectType]; exists {
-		// Remove the existing effect first
-		println("herhehrehrkehrkher")
-		switch effectType {
-		case "speed":
-			p.Speed /= existing.Value // Restore original speed before applying new one
-		}
+// onItemCollected stores a picked up item in the hotbar, or applies its
+// effect right away when it isn't kept or there's no room for it.
+func (p *Player) onItemCollected(item events.ItemCollected) {
+	if item.Stored && p.store(item) {
+		p.audio.RequestSound("key", 0.6, 1.5)
+		p.ShowMessage(fmt.Sprintf("Stored a %s.", itemName(item.Item)))
+		return
 	}
+	p.applyItem(item)
+}
 
-	// Apply the new effect
-	p.ActiveEffects[effectType] = &Effect{
-		Type:      effectType,
-		Value:     value,
-		Duration:  duration,
-		ExpiresAt: time.Now().Add(duration),
+// applyItem applies the effect of an item.
+func (p *Player) applyItem(item events.ItemCollected) {
+	switch item.Effect {
+	case "heal":
+		p.audio.RequestSound("heal", 1.0, 1.0)
+		p.Health = helpers.Min(p.Health+int(item.Value), p.MaxHealth)
+	case "key":
+		p.KeysCollected++
+		p.audio.RequestSound("key", 1.0, 1.0) // Assuming you have a collect sound
+		p.bus.Publish(events.KeyCollected{Count: p.KeysCollected})
+		if p.KeysCollected >= MAX_KEYS {
+			// The way out opens, past whatever guards it
+			p.ShowMessage("I've Collected All of them!!")
+		} else {
+			p.ShowMessage(fmt.Sprintf("Key collected! %d/5", p.KeysCollected))
+		}
+	case "door_key":
+		p.DoorKeys[wrld.ItemType(item.Item)] += int(item.Value)
+		p.audio.RequestSound("key", 1.0, 1.2)
+		p.ShowMessage(fmt.Sprintf("Found a %s.", itemName(item.Item)))
+	case "coin":
+		p.Coins += int(item.Value)
+		p.audio.RequestSound("key", 0.5, 2.0)
+	case "max_health":
+		p.MaxHealth += int(item.Value)
+		p.Health += int(item.Value)
+		p.audio.RequestSound("heal", 1.0, 0.8)
+		p.ShowMessage(MSG_HEART_CONTAINER)
+	case "key_hint":
+		// The simulation knows where the key lies and tells the player
+	case "weapon":
+		p.GiveWeapon(item.Item, int(item.Value))
+		p.audio.RequestSound("key", 1.0, 0.8)
+	default:
+		p.Effects.Apply(p, item.Effect, item.Value, float32(item.Duration.Seconds()))
 	}
+}
 
-	// Apply immediate effect
-	switch effectType {
-	case "speed":
-		p.Speed *= value
-		p.TextBubble.ShowMessage(MSG_SPEED_BOOST)
-	case "poison":
-		go p.handlePoisonEffect(value, duration)
-		p.TextBubble.ShowMessage(MSG_POISONED)
+// UseDoorKey opens the lock with one of the player's keys, small keys are
+// used up on the way. It reports false when they hold no key to the lock.
+func (p *Player) UseDoorKey(lock wrld.Lock) bool {
+	key := lock.Key()
+	if p.DoorKeys[key] <= 0 {
+		return false
 	}
+	if !lock.Reusable() {
+		p.DoorKeys[key]--
+	}
+	return true
 }
 
-func (p *Player) handlePoisonEffect(damage float32, duration time.Duration) {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
-	endTime := time.Now().Add(duration)
-
-	for {
-		select {
-		case <-ticker.C:
-			if time.Now().After(endTime) {
-				return
-			}
-			if p.State != "dying" {
-				p.Health -= int(damage)
-				if p.Health <= 0 {
-					p.Die()
-					return
-				}
-			}
-		}
+// Victory ends the run in the player's favour, once they had a moment to
+// take in the boss's fall.
+func (p *Player) Victory() {
+	if p.victoryTimer <= 0 && p.State != "victory" {
+		p.victoryTimer = VICTORY_DELAY
+		p.ShowMessage("It's over. I'm free!")
 	}
 }
 
-func (p *Player) listenForEffects() {
-	for effect := range p.effectsChan {
-		switch effect.Effect.Type {
-		case "heal":
-			p.audio.RequestSound("heal", 1.0, 1.0)
-			p.Health = helpers.Min(p.Health+int(effect.Effect.Value), 5)
-		case "speed":
-			println("HELL YEAH")
-			p.applyEffect("speed", effect.Effect.Value, effect.Effect.Duration)
-			p.ShowMessage(MSG_SPEED_BOOST)
-		case "poison":
-			p.applyEffect("poison", effect.Effect.Value, effect.Effect.Duration)
-
-			p.ShowMessage(MSG_POISONED)
-		case "key":
-			p.KeysCollected++
-			p.audio.RequestSound("key", 1.0, 1.0) // Assuming you have a collect sound
-			if p.KeysCollected >= MAX_KEYS {
-				// Player has won!
-				go func() {
-					time.Sleep(2 * time.Second) // Wait for dungeon's taunt to finish
-					p.State = "victory"
-				}()
-				p.ShowMessage("I've Collected All of them!!")
-			} else {
-				p.ShowMessage(fmt.Sprintf("Key collected! %d/5", p.KeysCollected))
-			}
-		case "coin":
-			// Implement coin collection logic
-		}
+// onDamage takes hits aimed at the player.
+func (p *Player) onDamage(damage events.DamageDealt) {
+	if damage.Target == events.TARGET_PLAYER && p.TakeDamage(damage.Amount) && damage.Status.Type != "" {
+		p.Effects.Apply(p, damage.Status.Type, damage.Status.Value, damage.Status.Duration)
 	}
 }
 
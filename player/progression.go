@@ -0,0 +1,116 @@
+package player
+
+import (
+	"crydes/helpers"
+	"fmt"
+)
+
+const XP_PER_LEVEL = 10 // Experience the first level takes, each one after takes that much more
+
+// Perk is a lasting upgrade the player picks when they level up. Ranks of
+// a perk add up, each one granting its bonus again.
+type Perk struct {
+	Name        string
+	Title       string // Shown on the perk picker
+	Description string
+	MaxRank     int
+
+	Hearts   int     // Hearts added to the player's maximum
+	Stat     string  // Stat the perk changes, read through PerkMultiplier
+	Bonus    float32 // Part of the stat added per rank, negative to lower it
+	Immunity string  // Status effect the player can no longer suffer
+}
+
+// PERKS are the perks there are, in the order the picker offers them.
+var PERKS = []*Perk{
+	{Name: "vitality", Title: "Vitality", Description: "+1 heart", MaxRank: 3, Hearts: 1},
+	{Name: "quick_blade", Title: "Quick Blade", Description: "Sword recovers 20% faster", MaxRank: 3, Stat: "melee_cooldown", Bonus: -0.2},
+	{Name: "far_sight", Title: "Far Sight", Description: "Light reaches 20% further", MaxRank: 3, Stat: "light_radius", Bonus: 0.2},
+	{Name: "antidote", Title: "Antidote", Description: "Immune to poison", MaxRank: 1, Immunity: "poison"},
+}
+
+// XPToNext returns the experience the player needs to reach the next
+// level.
+func (p *Player) XPToNext() int {
+	return XP_PER_LEVEL * p.Level
+}
+
+// GainXP gives the player experience, each level it brings them earns a
+// perk to pick.
+func (p *Player) GainXP(amount int) {
+	if amount <= 0 || p.State == "dying" {
+		return
+	}
+
+	p.XP += amount
+	for p.XP >= p.XPToNext() {
+		p.XP -= p.XPToNext()
+		p.Level++
+		p.PerkPoints++
+		p.ShowMessage(fmt.Sprintf("Level %d! I feel stronger.", p.Level))
+		p.audio.RequestSound("key", 1.0, 1.5)
+	}
+}
+
+// PerkOffers returns the perks the player can still rank up.
+func (p *Player) PerkOffers() []*Perk {
+	var offers []*Perk
+	for _, perk := range PERKS {
+		if p.Perks[perk.Name] < perk.MaxRank {
+			offers = append(offers, perk)
+		}
+	}
+	return offers
+}
+
+// ChoosingPerk reports whether the player has a perk to pick.
+func (p *Player) ChoosingPerk() bool {
+	return p.PerkPoints > 0 && len(p.PerkOffers()) > 0
+}
+
+// ChoosePerk ranks up the perk at index i of the offers, spending a level
+// up on it.
+func (p *Player) ChoosePerk(i int) {
+	offers := p.PerkOffers()
+	if p.PerkPoints <= 0 || i < 0 || i >= len(offers) {
+		return
+	}
+
+	perk := offers[i]
+	p.PerkPoints--
+	p.Perks[perk.Name]++
+
+	p.MaxHealth += perk.Hearts
+	p.Health = helpers.Min(p.Health+perk.Hearts, p.MaxHealth)
+	if perk.Immunity != "" {
+		p.Effects.SetImmune(perk.Immunity, true)
+		p.Effects.Remove(p, perk.Immunity)
+	}
+	p.ShowMessage(fmt.Sprintf("%s: %s.", perk.Title, perk.Description))
+}
+
+// PerkMultiplier returns what the player's perks multiply stat by, 1 when
+// none does.
+func (p *Player) PerkMultiplier(stat string) float32 {
+	m := float32(1)
+	for _, perk := range PERKS {
+		if perk.Stat == stat {
+			m *= 1 + perk.Bonus*float32(p.Perks[perk.Name])
+		}
+	}
+	return m
+}
+
+// LightRadius returns how far the light the player carries reaches.
+func (p *Player) LightRadius() float32 {
+	return helpers.LIGHT_RADIUS * p.PerkMultiplier("light_radius")
+}
+
+// restorePerks puts back what the perks do beyond the saved stats.
+func (p *Player) restorePerks() {
+	for _, perk := range PERKS {
+		if perk.Immunity != "" {
+			p.Effects.SetImmune(perk.Immunity, p.Perks[perk.Name] > 0)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package player
+
+import (
+	"crydes/combat"
+	"crydes/defs"
+	"crydes/events"
+	"crydes/status"
+	wrld "crydes/world"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PlayerState is the serializable form of the player.
+type PlayerState struct {
+	X             float32       `json:"x"`
+	Y             float32       `json:"y"`
+	Health        int           `json:"health"`
+	MaxHealth     int           `json:"max_health,omitempty"` // 0 in saves from before heart containers
+	Speed         float32       `json:"speed"`
+	KeysCollected int           `json:"keys_collected"`
+	Effects       []EffectState `json:"effects"`
+
+	Coins     int                   `json:"coins,omitempty"`
+	DoorKeys  map[wrld.ItemType]int `json:"door_keys,omitempty"`
+	Inventory []SlotState           `json:"inventory,omitempty"` // Saves from before the hotbar load empty
+	Weapons   []WeaponState         `json:"weapons,omitempty"`   // Saves from before weapons load with the sword only
+	Weapon    int                   `json:"weapon,omitempty"`
+
+	XP         int            `json:"xp,omitempty"`
+	Level      int            `json:"level,omitempty"` // 0 in saves from before levels, read as 1
+	Perks      map[string]int `json:"perks,omitempty"`
+	PerkPoints int            `json:"perk_points,omitempty"`
+}
+
+// WeaponState is the serializable form of a carried weapon, shots in
+// flight aren't kept.
+type WeaponState struct {
+	Name     string  `json:"name"`
+	Ammo     int     `json:"ammo,omitempty"`
+	Cooldown float32 `json:"cooldown,omitempty"`
+}
+
+// SlotState is the serializable form of a hotbar slot, empty slots keep
+// their place with a count of 0.
+type SlotState struct {
+	Item     string        `json:"item,omitempty"`
+	Effect   string        `json:"effect,omitempty"`
+	Value    float32       `json:"value,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Count    int           `json:"count"`
+}
+
+// EffectState is the serializable form of an effect running on the
+// player.
+type EffectState = status.EffectState
+
+// Snapshot captures the player's position, health, keys, inventory,
+// weapons, progression and active effects.
+func (p *Player) Snapshot() PlayerState {
+	state := PlayerState{
+		X:             p.Position.X,
+		Y:             p.Position.Y,
+		Health:        p.Health,
+		MaxHealth:     p.MaxHealth,
+		Coins:         p.Coins,
+		XP:            p.XP,
+		Level:         p.Level,
+		PerkPoints:    p.PerkPoints,
+		Perks:         make(map[string]int),
+		Speed:         p.Speed,
+		KeysCollected: p.KeysCollected,
+		DoorKeys:      make(map[wrld.ItemType]int),
+		Weapon:        p.Weapon,
+	}
+
+	for _, w := range p.Weapons {
+		state.Weapons = append(state.Weapons, WeaponState{Name: w.Def.Name, Ammo: w.Ammo, Cooldown: float32(w.Cooldown)})
+	}
+
+	for key, count := range p.DoorKeys {
+		if count > 0 {
+			state.DoorKeys[key] = count
+		}
+	}
+
+	for name, rank := range p.Perks {
+		if rank > 0 {
+			state.Perks[name] = rank
+		}
+	}
+
+	for _, slot := range p.Inventory {
+		state.Inventory = append(state.Inventory, SlotState{
+			Item:     slot.Item.Item,
+			Effect:   slot.Item.Effect,
+			Value:    slot.Item.Value,
+			Duration: slot.Item.Duration,
+			Count:    slot.Count,
+		})
+	}
+
+	state.Effects = p.Effects.Snapshot()
+
+	return state
+}
+
+// Restore puts the player back in a saved state. Speed is restored as
+// saved since it already includes any active speed boost.
+func (p *Player) Restore(state PlayerState) {
+	p.Position = rl.NewVector2(state.X, state.Y)
+	p.Health = state.Health
+	p.MaxHealth = state.MaxHealth
+	if p.MaxHealth == 0 {
+		p.MaxHealth = MAX_HEALTH
+	}
+	p.Coins = state.Coins
+	p.XP, p.Level, p.PerkPoints = state.XP, state.Level, state.PerkPoints
+	if p.Level == 0 {
+		p.Level = 1
+	}
+	p.lastHealth = state.Health
+	p.Previous = p.Position
+	p.Speed = state.Speed
+	p.KeysCollected = state.KeysCollected
+
+	p.DoorKeys = make(map[wrld.ItemType]int)
+	for key, count := range state.DoorKeys {
+		p.DoorKeys[key] = count
+	}
+
+	p.Inventory = [HOTBAR_SLOTS]Slot{}
+	for i, slot := range state.Inventory {
+		if i >= HOTBAR_SLOTS || slot.Count <= 0 {
+			continue
+		}
+		p.Inventory[i] = Slot{
+			Item:  events.ItemCollected{Item: slot.Item, Effect: slot.Effect, Value: slot.Value, Duration: slot.Duration, Stored: true},
+			Count: slot.Count,
+		}
+	}
+
+	// The sword is always carried first, weapons already loaded are reused
+	carried := make(map[string]*Weapon)
+	for _, w := range p.Weapons {
+		carried[w.Def.Name] = w
+	}
+	p.Weapons = p.Weapons[:1]
+	p.Weapons[0].Cooldown = 0
+	for _, saved := range state.Weapons {
+		def, exists := defs.Get().Weapons[saved.Name]
+		if !exists {
+			continue
+		}
+		w := carried[saved.Name]
+		if w == nil {
+			w = newWeapon(def, 0, p.Map.Headless())
+		}
+		w.Ammo, w.Cooldown = saved.Ammo, combat.Timer(saved.Cooldown)
+		if w != p.Weapons[0] {
+			p.Weapons = append(p.Weapons, w)
+		}
+	}
+	p.Weapon = state.Weapon
+	if p.Weapon < 0 || p.Weapon >= len(p.Weapons) {
+		p.Weapon = 0
+	}
+	p.Shots = nil
+
+	p.Perks = make(map[string]int)
+	for name, rank := range state.Perks {
+		p.Perks[name] = rank
+	}
+
+	p.Effects.Restore(state.Effects)
+	p.restorePerks()
+}
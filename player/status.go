@@ -0,0 +1,77 @@
+package player
+
+import (
+	"crydes/events"
+	"crydes/helpers"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Heal gives the player amount hearts back, up to their maximum.
+func (p *Player) Heal(amount int) {
+	if p.State == "dying" || p.Health >= p.MaxHealth {
+		return
+	}
+	p.Health = helpers.Min(p.Health+amount, p.MaxHealth)
+	p.audio.RequestSound("heal", 0.5, 1.2)
+}
+
+// Hurt takes amount hearts from the player for an effect running on them,
+// their invulnerability after a hit doesn't help.
+func (p *Player) Hurt(amount int) {
+	if p.State == "dying" || amount <= 0 {
+		return
+	}
+	p.Health -= amount
+	center := p.GetPlayerCenterPoint()
+	p.bus.Publish(events.DamageTaken{Position: rl.NewVector2(center.X, p.Position.Y), Amount: amount, Player: true})
+	p.CheckHealth()
+}
+
+// Say has the player speak their mind.
+func (p *Player) Say(message string) {
+	p.ShowMessage(message)
+}
+
+// renderEffects draws the effects running on the player in a column above
+// the hearts, each with its label and a bar of the time left.
+func (p *Player) renderEffects() {
+	active := p.Effects.All()
+	if len(active) == 0 {
+		return
+	}
+
+	const (
+		width      = float32(100)
+		height     = float32(30)
+		padding    = float32(5)
+		heartsSize = float32(8 * 5) // Side of a heart, as RenderHearts draws it
+	)
+	totalHeight := (height+padding)*float32(len(active)) - padding
+	startX := float32(20)
+	startY := float32(rl.GetScreenHeight()) - heartsSize - 20 - padding*3 - totalHeight
+
+	bgRect := rl.Rectangle{
+		X:      startX - padding,
+		Y:      startY - padding,
+		Width:  width + padding*2,
+		Height: totalHeight + padding*2,
+	}
+	rl.DrawRectangle(int32(bgRect.X), int32(bgRect.Y), int32(bgRect.Width), int32(bgRect.Height), rl.NewColor(0, 0, 0, 100))
+	rl.DrawRectangleLinesEx(bgRect, 2, rl.ColorAlpha(rl.White, 0.3))
+
+	y := startY
+	for _, e := range active {
+		label := e.Kind.Label
+		if e.Stacks > 1 {
+			label = fmt.Sprintf("%s x%d", label, e.Stacks)
+		}
+		rl.DrawText(label, int32(startX), int32(y+5), 12, e.Kind.Color)
+
+		progress := e.Remaining() / e.Duration
+		rl.DrawRectangle(int32(startX), int32(y)+20, int32(width*progress), 5, e.Kind.Color)
+
+		y += height + padding
+	}
+}
@@ -6,6 +6,13 @@ import (
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Size of a sword frame, used for the hitbox so it doesn't depend on the
+// textures being loaded.
+const (
+	SWORD_FRAME_WIDTH  = 46
+	SWORD_FRAME_HEIGHT = 34
+)
+
 type Sword struct {
 	Position  rl.Vector2
 	Animation *helpers.Animation
@@ -16,42 +23,11 @@ type Sword struct {
 	Scale  float32
 }
 
-// NewSword creates a new sword instance with the given sprite.
-func NewSword(offset rl.Vector2, direction string) *Sword {
-	// Load the left sprite of the sword and use it as the base frame.
-
-	framesPaths := []string{
-		"assets/sword/1.png",
-		"assets/sword/2.png",
-		"assets/sword/3.png",
-		"assets/sword/4.png",
-		"assets/sword/5.png",
-	}
-
-	frames := make([]rl.Texture2D, 0, len(framesPaths))
-	for _, framePath := range framesPaths {
-		frame := rl.LoadTexture(framePath)
-
-		if frame.ID == 0 {
-			rl.TraceLog(rl.LogError, "Failed to load sword sprite: %s", framePath)
-			panic("Failed to load sword sprite")
-		}
-
-		frames = append(frames, frame)
-	}
-
-	// Create a simple idle sword animation with just one frame.
-	swordAnimation := &helpers.Animation{
-		Frames:       frames,
-		FrameTime:    0.1, // Modify frame time if you have multiple frames.
-		CurrentFrame: 0,
-		Timer:        0,
-		ID:           "sword_idle",
-	}
-
+// NewSword creates a new sword instance swinging with the given animation.
+func NewSword(offset rl.Vector2, direction string, animation *helpers.Animation) *Sword {
 	return &Sword{
 		Position:  rl.NewVector2(0, 0),
-		Animation: swordAnimation,
+		Animation: animation,
 		Visible:   false,
 		Direction: direction, // This indicates whether the sprite is mirrored for the right direction.
 		Offset:    offset,
@@ -108,8 +84,8 @@ func (s *Sword) Render() {
 }
 
 func (s *Sword) GetSwordRect() rl.Rectangle {
-	width := float32(s.Animation.Frames[s.Animation.CurrentFrame].Width) * 0.5 * s.Scale
-	height := float32(s.Animation.Frames[s.Animation.CurrentFrame].Height) * s.Scale
+	width := float32(SWORD_FRAME_WIDTH) * 0.5 * s.Scale
+	height := float32(SWORD_FRAME_HEIGHT) * s.Scale
 
 	if s.Direction == "right" {
 		return rl.NewRectangle(s.Position.X+width, s.Position.Y, width, height)
@@ -1,8 +1,6 @@
 package player
 
 import (
-	"time"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -36,26 +34,31 @@ const (
 
 	// Status messages
 	MSG_LOW_HEALTH  = "I need to find healing..."
-	MSG_POISONED    = "This poison burns..."
-	MSG_SPEED_BOOST = "I feel faster!"
+	MSG_FULL_HEALTH = "I'm not hurt, better keep it."
+
+	MSG_HEART_CONTAINER = "I feel tougher!"
 
 	// SHIFTING
 	FIRST_SHIFT = "What happened?"
 )
 
 type TextBubble struct {
-	text          string
-	isVisible     bool
-	alpha         float32
-	fadeIn        bool
-	showStartTime time.Time
-	width         float32
-	height        float32
-	wrappedText   []string
+	text        string
+	isVisible   bool
+	alpha       float32
+	fadeIn      bool
+	shownFor    float32 // Seconds the current message has been shown
+	width       float32
+	height      float32
+	wrappedText []string
+	headless    bool // Can't measure text, there's no font without a window
 }
 
-func NewTextBubble() *TextBubble {
+// NewTextBubble creates a hidden bubble. Headless bubbles don't wrap their
+// text.
+func NewTextBubble(headless bool) *TextBubble {
 	return &TextBubble{
+		headless:  headless,
 		alpha:     0,
 		isVisible: false,
 	}
@@ -66,10 +69,14 @@ func (tb *TextBubble) ShowMessage(text string) {
 	tb.isVisible = true
 	tb.fadeIn = true
 	tb.alpha = 0
-	tb.showStartTime = time.Now()
+	tb.shownFor = 0
 
 	// Calculate wrapped text and bubble dimensions
-	tb.wrappedText = wrapText(text, BUBBLE_MAX_WIDTH, BUBBLE_FONT_SIZE)
+	if tb.headless {
+		tb.wrappedText = []string{text}
+	} else {
+		tb.wrappedText = wrapText(text, BUBBLE_MAX_WIDTH, BUBBLE_FONT_SIZE)
+	}
 	tb.width = float32(BUBBLE_MAX_WIDTH + BUBBLE_PADDING*2)
 	tb.height = float32(len(tb.wrappedText)*BUBBLE_FONT_SIZE + BUBBLE_PADDING*2)
 }
@@ -79,7 +86,8 @@ func (tb *TextBubble) Update(deltaTime float32) {
 		return
 	}
 
-	elapsed := time.Since(tb.showStartTime).Seconds()
+	tb.shownFor += deltaTime
+	elapsed := tb.shownFor
 
 	// Handle fade in
 	if tb.fadeIn {
@@ -0,0 +1,318 @@
+package player
+
+import (
+	"crydes/combat"
+	"crydes/defs"
+	"crydes/events"
+	helpers "crydes/helpers"
+	wrld "crydes/world"
+	"fmt"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const BLAST_DURATION = 0.3 // Seconds the flash of a thrown weapon blowing up lasts
+
+// Weapon is a weapon the player carries.
+type Weapon struct {
+	Def      *defs.WeaponDef
+	Ammo     int          // Uses left, ignored by weapons with unlimited ammo
+	Cooldown combat.Timer // Until it can be used again
+
+	anim *helpers.Animation // The swing of melee weapons, the shot of the others
+	icon rl.Texture2D       // Loaded the first time the weapon is shown
+}
+
+// newWeapon creates a weapon from its definition. A headless weapon loads
+// no textures.
+func newWeapon(def *defs.WeaponDef, ammo int, headless bool) *Weapon {
+	return &Weapon{
+		Def:  def,
+		Ammo: helpers.Min(ammo, def.Ammo),
+		anim: def.Animation.Load(def.Name, headless),
+	}
+}
+
+// Ready reports whether the weapon can be used, its cooldown being over
+// and ammo left.
+func (w *Weapon) Ready() bool {
+	return !w.Cooldown.Running() && (w.Def.Ammo == 0 || w.Ammo > 0)
+}
+
+// CurrentWeapon returns the weapon in the player's hands.
+func (p *Player) CurrentWeapon() *Weapon {
+	return p.Weapons[p.Weapon]
+}
+
+// NextWeapon puts the next weapon carried in the player's hands.
+func (p *Player) NextWeapon() {
+	p.Weapon = (p.Weapon + 1) % len(p.Weapons)
+	p.audio.RequestSound("step", 0.8, 1.5)
+}
+
+// GiveWeapon adds a weapon to those the player carries, with ammo uses,
+// or tops up the ammo of the one they already have.
+func (p *Player) GiveWeapon(name string, ammo int) {
+	def, exists := defs.Get().Weapons[name]
+	if !exists {
+		return
+	}
+
+	for _, w := range p.Weapons {
+		if w.Def != def {
+			continue
+		}
+		if def.Ammo > 0 {
+			w.Ammo = helpers.Min(w.Ammo+ammo, def.Ammo)
+			p.ShowMessage(fmt.Sprintf("%d %ss left.", w.Ammo, itemName(name)))
+		}
+		return
+	}
+
+	p.Weapons = append(p.Weapons, newWeapon(def, ammo, p.Map.Headless()))
+	p.ShowMessage(fmt.Sprintf("Found a %s!", itemName(name)))
+}
+
+// Attack uses the weapon in the player's hands: melee weapons hit in front
+// of them, others are shot or thrown the way they're heading.
+func (p *Player) Attack() {
+	w := p.CurrentWeapon()
+	if !w.Ready() {
+		if !w.Cooldown.Running() {
+			p.ShowMessage(fmt.Sprintf("I'm out of %ss.", itemName(w.Def.Name)))
+		}
+		return
+	}
+
+	cooldown := w.Def.Cooldown
+	if w.Def.Kind == "melee" {
+		cooldown *= p.PerkMultiplier("melee_cooldown")
+	}
+	w.Cooldown.Start(cooldown)
+	if w.Def.Ammo > 0 {
+		w.Ammo--
+	}
+	p.audio.RequestSound(w.Def.Sound, 1.0, 1.0)
+	amount, crit := combat.Damage(w.Def.Damage, w.Def.CritChance, w.Def.CritMultiplier, p.Map.Rand().Float32())
+
+	if w.Def.Kind != "melee" {
+		p.Shots = append(p.Shots, &Shot{
+			Weapon:   w.Def,
+			Position: p.GetPlayerCenterPoint(),
+			Velocity: rl.Vector2Scale(p.aim(), w.Def.Speed),
+			Damage:   amount,
+			Crit:     crit,
+			fuse:     w.Def.Fuse,
+			anim:     w.anim,
+		})
+		return
+	}
+
+	p.Sword.Animation = w.anim
+	p.Sword.Visible = true
+	area := p.Sword.GetSwordRect()
+
+	helpers.DEBUG("Player Attack", area)
+
+	p.bus.Publish(events.DamageDealt{Target: events.TARGET_ENEMIES, Area: area, Amount: amount, Crit: crit, Knockback: w.Def.Knockback, Status: w.Def.Effect.Status()})
+}
+
+// aim returns the direction the player is heading, or facing when they
+// stand still.
+func (p *Player) aim() rl.Vector2 {
+	var dir rl.Vector2
+	if p.Controls.Left {
+		dir.X--
+	}
+	if p.Controls.Right {
+		dir.X++
+	}
+	if p.Controls.Up {
+		dir.Y--
+	}
+	if p.Controls.Down {
+		dir.Y++
+	}
+
+	if dir.X == 0 && dir.Y == 0 {
+		dir.X = 1
+		if p.LastDirection == "left" {
+			dir.X = -1
+		}
+	}
+	return rl.Vector2Normalize(dir)
+}
+
+// updateWeapons counts weapon cooldowns down and moves the shots in
+// flight, dropping the spent ones.
+func (p *Player) updateWeapons(deltaTime float32) {
+	for _, w := range p.Weapons {
+		w.Cooldown.Update(deltaTime)
+	}
+
+	live := p.Shots[:0]
+	for _, s := range p.Shots {
+		if s.Update(deltaTime, p.Map) {
+			p.blowUp(s)
+		}
+		if !s.Spent || s.blast > 0 {
+			live = append(live, s)
+		}
+	}
+	p.Shots = live
+}
+
+// blowUp hurts every enemy in reach of a thrown weapon going off, and the
+// player too when they stand too close.
+func (p *Player) blowUp(s *Shot) {
+	r := s.Weapon.Radius
+	area := rl.NewRectangle(s.Position.X-r, s.Position.Y-r, r*2, r*2)
+	p.bus.Publish(events.DamageDealt{Target: events.TARGET_ENEMIES, Area: area, Amount: s.Damage, Crit: s.Crit, Knockback: s.Weapon.Knockback, Status: s.Weapon.Effect.Status()})
+
+	if helpers.Distance(s.Position, p.GetPlayerCenterPoint()) <= r {
+		p.bus.Publish(events.DamageDealt{Target: events.TARGET_PLAYER, Amount: 1, Status: s.Weapon.Effect.Status()})
+	}
+	if s.Weapon.HitSound != "" {
+		p.audio.RequestSound(s.Weapon.HitSound, 1.0, 1.0)
+	}
+}
+
+// Shot is a projectile in flight or a thrown weapon waiting to go off.
+type Shot struct {
+	Weapon   *defs.WeaponDef
+	Position rl.Vector2
+	Velocity rl.Vector2 // Pixels per second, zero once a thrown weapon lands
+	Damage   int        // Rolled when shot
+	Crit     bool
+	Spent    bool // Hit something or went off
+
+	traveled float32 // Pixels flown
+	fuse     float32 // Seconds until a thrown weapon goes off
+	blast    float32 // Seconds left of the flash once it went off
+	age      float32 // Seconds since it was shot, drives its animation
+	anim     *helpers.Animation
+}
+
+// Update moves the shot, projectiles are spent against walls or once out
+// of range while thrown weapons land there. It reports whether a thrown
+// weapon went off.
+func (s *Shot) Update(deltaTime float32, mp *wrld.Map) bool {
+	s.age += deltaTime
+	if s.Spent {
+		s.blast -= deltaTime
+		return false
+	}
+
+	if s.Velocity.X != 0 || s.Velocity.Y != 0 {
+		next := rl.Vector2Add(s.Position, rl.Vector2Scale(s.Velocity, deltaTime))
+		if s.traveled >= s.Weapon.Range || !mp.IsWalkableFloat(next.X, next.Y) {
+			s.Velocity = rl.Vector2{}
+			s.Spent = s.Weapon.Kind == "projectile"
+		} else {
+			s.traveled += rl.Vector2Distance(s.Position, next)
+			s.Position = next
+		}
+	}
+
+	if s.Weapon.Kind != "thrown" {
+		return false
+	}
+	if s.fuse -= deltaTime; s.fuse <= 0 {
+		s.Spent = true
+		s.blast = BLAST_DURATION
+		return true
+	}
+	return false
+}
+
+// Bounds returns the tip of the shot, what it hits things with.
+func (s *Shot) Bounds() rl.Rectangle {
+	return rl.NewRectangle(s.Position.X-1, s.Position.Y-1, 2, 2)
+}
+
+// Hit stops a projectile that flew into an enemy, returning the area it
+// hurts.
+func (s *Shot) Hit() rl.Rectangle {
+	s.Spent = true
+	return s.Bounds()
+}
+
+// Render draws the shot turned the way it flies, or the flash of a thrown
+// weapon going off.
+func (s *Shot) Render() {
+	if s.Spent {
+		if s.blast > 0 {
+			progress := 1 - s.blast/BLAST_DURATION
+			radius := s.Weapon.Radius * (0.5 + progress/2)
+			rl.DrawCircleV(s.Position, radius, rl.ColorAlpha(rl.Orange, 0.6*(1-progress)))
+			rl.DrawCircleV(s.Position, radius/2, rl.ColorAlpha(rl.Yellow, 0.8*(1-progress)))
+		}
+		return
+	}
+	if len(s.anim.Frames) == 0 {
+		return
+	}
+
+	frame := s.anim.Frames[int(s.age/s.anim.FrameTime)%len(s.anim.Frames)]
+	rotation := float32(0)
+	if s.Weapon.Kind == "projectile" {
+		rotation = float32(math.Atan2(float64(s.Velocity.Y), float64(s.Velocity.X)) * 180 / math.Pi)
+	}
+	w, h := float32(frame.Width), float32(frame.Height)
+	rl.DrawTexturePro(frame,
+		rl.NewRectangle(0, 0, w, h),
+		rl.NewRectangle(s.Position.X, s.Position.Y, w/2, h/2),
+		rl.NewVector2(w/4, h/4),
+		rotation,
+		rl.White,
+	)
+}
+
+// RenderWeapons draws the weapons carried above the hotbar, the one in
+// hand framed, with the ammo left and the cooldown shading the icon.
+func (p *Player) RenderWeapons() {
+	const (
+		slotSize = float32(40)
+		padding  = float32(5)
+	)
+	heartsWidth := (float32(8)*5+padding)*float32(p.MaxHealth) + padding
+	startX := 20 + heartsWidth + padding*2
+	startY := float32(rl.GetScreenHeight()) - slotSize*2 - 20 - padding*4
+
+	bgRect := rl.Rectangle{
+		X:      startX - padding,
+		Y:      startY - padding,
+		Width:  (slotSize+padding)*float32(len(p.Weapons)) + padding,
+		Height: slotSize + padding*2,
+	}
+	rl.DrawRectangle(int32(bgRect.X), int32(bgRect.Y), int32(bgRect.Width), int32(bgRect.Height), rl.NewColor(0, 0, 0, 100))
+	rl.DrawRectangleLinesEx(bgRect, 2, rl.ColorAlpha(rl.White, 0.3))
+
+	for i, w := range p.Weapons {
+		x := startX + (slotSize+padding)*float32(i)
+		border := rl.ColorAlpha(rl.White, 0.2)
+		if i == p.Weapon {
+			border = rl.Gold
+		}
+		rl.DrawRectangleLinesEx(rl.NewRectangle(x, startY, slotSize, slotSize), 1, border)
+
+		if w.icon.ID == 0 {
+			w.icon = helpers.LoadTexture(p.Map.Headless(), w.Def.Icon)
+		}
+		if w.icon.ID != 0 {
+			scale := (slotSize - 8) / float32(w.icon.Width)
+			if w.icon.Height > w.icon.Width {
+				scale = (slotSize - 8) / float32(w.icon.Height)
+			}
+			tint := rl.White
+			if !w.Ready() {
+				tint = rl.Gray
+			}
+			rl.DrawTextureEx(w.icon, rl.NewVector2(x+4, startY+4), 0, scale, tint)
+		}
+		if w.Def.Ammo > 0 {
+			rl.DrawText(fmt.Sprint(w.Ammo), int32(x+slotSize)-12, int32(startY+slotSize)-12, 10, rl.White)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package replay
+
+import (
+	"crydes/audio"
+	"crydes/input"
+	"crydes/sim"
+	"crydes/world"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// VERSION is bumped whenever the layout of a replay file or what the
+// simulation makes of the recorded actions changes, and only then, so a
+// replay that would diverge is refused instead.
+const VERSION = 15
+
+// DEFAULT_PATH is where the game keeps the replay of the last run.
+const DEFAULT_PATH = "last_run.replay.json"
+
+// Outcome is where a run ended up, a replay that doesn't end the same way
+// has diverged from the recorded run.
+type Outcome struct {
+	Ticks  int     `json:"ticks"`
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Health int     `json:"health"`
+	Keys   int     `json:"keys"`
+	Killed int     `json:"killed"`
+	Map    int64   `json:"map"`   // Seed of the layout the run ended in
+	Depth  int     `json:"depth"` // Floor the run ended on
+}
+
+// OutcomeOf sums up the current state of a simulation.
+func OutcomeOf(s *sim.Simulation) Outcome {
+	return Outcome{
+		Ticks:  s.Ticks,
+		X:      s.Player.Position.X,
+		Y:      s.Player.Position.Y,
+		Health: s.Player.Health,
+		Keys:   s.Player.KeysCollected,
+		Killed: s.Enemies.KilledCount,
+		Map:    s.World.Map.Seed(),
+		Depth:  s.Depth(),
+	}
+}
+
+// Replay is a run reduced to what it started from and the actions held on
+// every tick, stepping a simulation through them plays the run again.
+type Replay struct {
+	Seed      int64           // Seed of a run started from scratch
+	Generator string          // Generator forced on every layout, empty if none
+	MapWidth  int             // Tiles across the top floor
+	MapHeight int             // Tiles down the top floor
+	Start     *sim.State      // State of a run continued from a save, nil otherwise
+	Ticks     input.Recording // Actions held on every tick
+	Outcome   *Outcome        // How the recorded run ended, nil until it's known
+}
+
+// New starts recording a run generated from seed and laid out as the
+// config says.
+func New(seed int64, config world.Config) *Replay {
+	return &Replay{Seed: seed, Generator: config.Generator, MapWidth: config.Width, MapHeight: config.Height}
+}
+
+// FromState starts recording a run continued from a saved state and laid
+// out as the config says.
+func FromState(state sim.State, config world.Config) *Replay {
+	return &Replay{
+		Seed:      state.RunSeed,
+		Generator: config.Generator,
+		MapWidth:  config.Width,
+		MapHeight: config.Height,
+		Start:     &state,
+	}
+}
+
+// Record appends the actions of one tick.
+func (r *Replay) Record(down input.ActionSet) {
+	r.Ticks = append(r.Ticks, down)
+}
+
+// Finish records how the run ended.
+func (r *Replay) Finish(s *sim.Simulation) {
+	outcome := OutcomeOf(s)
+	r.Outcome = &outcome
+}
+
+// Config returns how the recorded run laid out its dungeons.
+func (r *Replay) Config() world.Config {
+	return world.Config{Generator: r.Generator, Width: r.MapWidth, Height: r.MapHeight}
+}
+
+// NewSimulation builds the simulation the recorded run started from. The
+// sound manager is optional, pass nil to play it silently.
+func (r *Replay) NewSimulation(sm *audio.SoundManager, headless bool) *sim.Simulation {
+	if r.Start != nil {
+		return sim.Restore(*r.Start, r.Config(), sm, headless)
+	}
+	return sim.New(r.Seed, r.Config(), sm, headless)
+}
+
+// Step plays the recorded tick at index on s, state being the actions of
+// the previous tick. It returns the actions of the tick it played.
+func (r *Replay) Step(s *sim.Simulation, index int, state input.State) input.State {
+	state = state.Next(r.Ticks[index])
+	s.StepInput(state)
+	return state
+}
+
+// Verify plays the replay on a headless simulation and checks it ends the
+// way the recorded run did.
+func (r *Replay) Verify() (Outcome, error) {
+	s := r.NewSimulation(nil, true)
+
+	var state input.State
+	for i := range r.Ticks {
+		state = r.Step(s, i, state)
+	}
+
+	got := OutcomeOf(s)
+	if r.Outcome != nil && got != *r.Outcome {
+		return got, fmt.Errorf("replay diverged: recorded %+v, played %+v", *r.Outcome, got)
+	}
+	return got, nil
+}
+
+// run is a stretch of ticks holding the same actions.
+type run struct {
+	Actions input.ActionSet `json:"a"`
+	Count   int             `json:"n"`
+}
+
+// file is the JSON layout of a replay, ticks are run-length encoded since
+// actions are held for many ticks in a row.
+type file struct {
+	Version   int        `json:"version"`
+	Seed      int64      `json:"seed"`
+	Generator string     `json:"generator,omitempty"`
+	MapWidth  int        `json:"map_width"`
+	MapHeight int        `json:"map_height"`
+	Start     *sim.State `json:"start,omitempty"`
+	Ticks     []run      `json:"ticks"`
+	Outcome   *Outcome   `json:"outcome,omitempty"`
+}
+
+// Write saves the replay to path.
+func Write(path string, r *Replay) error {
+	f := file{
+		Version:   VERSION,
+		Seed:      r.Seed,
+		Generator: r.Generator,
+		MapWidth:  r.MapWidth,
+		MapHeight: r.MapHeight,
+		Start:     r.Start,
+		Outcome:   r.Outcome,
+	}
+	for _, down := range r.Ticks {
+		if n := len(f.Ticks); n > 0 && f.Ticks[n-1].Actions == down {
+			f.Ticks[n-1].Count++
+			continue
+		}
+		f.Ticks = append(f.Ticks, run{Actions: down, Count: 1})
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("encoding replay: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing replay: %w", err)
+	}
+	return nil
+}
+
+// Read loads a replay from path.
+func Read(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading replay: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decoding replay: %w", err)
+	}
+
+	if f.Version != VERSION {
+		return nil, fmt.Errorf("replay %s has version %d, expected %d", path, f.Version, VERSION)
+	}
+
+	r := &Replay{
+		Seed:      f.Seed,
+		Generator: f.Generator,
+		MapWidth:  f.MapWidth,
+		MapHeight: f.MapHeight,
+		Start:     f.Start,
+		Outcome:   f.Outcome,
+	}
+	for _, run := range f.Ticks {
+		for i := 0; i < run.Count; i++ {
+			r.Ticks = append(r.Ticks, run.Actions)
+		}
+	}
+	return r, nil
+}
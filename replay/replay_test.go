@@ -0,0 +1,108 @@
+package replay
+
+import (
+	"crydes/input"
+	"crydes/sim"
+	"crydes/world"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain runs the tests from the root of the repository, where the
+// definitions and their assets are.
+func TestMain(m *testing.M) {
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// script returns the actions held on tick, walking a square and swinging
+// now and then so the run meets enemies.
+func script(tick int) input.ActionSet {
+	var down input.ActionSet
+	down.Add([]input.Action{input.MOVE_RIGHT, input.MOVE_DOWN, input.MOVE_LEFT, input.MOVE_UP}[(tick/240)%4])
+	if tick%45 < 3 {
+		down.Add(input.ATTACK)
+	}
+	return down
+}
+
+// record plays ticks of the script on a new run from seed, the way the
+// game records it, and returns the replay and the simulation it ran.
+func record(seed int64, ticks int) (*Replay, *sim.Simulation) {
+	r := New(seed, world.DEFAULT_CONFIG)
+	s := r.NewSimulation(nil, true)
+
+	var state input.State
+	for i := 0; i < ticks; i++ {
+		down := script(i)
+		r.Record(down)
+		state = state.Next(down)
+		s.StepInput(state)
+	}
+	r.Finish(s)
+	return r, s
+}
+
+// play steps a new simulation through every tick of the replay.
+func play(r *Replay) *sim.Simulation {
+	s := r.NewSimulation(nil, true)
+	var state input.State
+	for i := range r.Ticks {
+		state = r.Step(s, i, state)
+	}
+	return s
+}
+
+func stateJSON(t *testing.T, s *sim.Simulation) string {
+	t.Helper()
+	data, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRecordedRunVerifies(t *testing.T) {
+	for _, seed := range []int64{7, 2024} {
+		r, recorded := record(seed, 1800)
+
+		path := filepath.Join(t.TempDir(), "run.replay.json")
+		if err := Write(path, r); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		outcome, err := loaded.Verify()
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if outcome != *r.Outcome {
+			t.Errorf("seed %d: verified %+v, recorded %+v", seed, outcome, *r.Outcome)
+		}
+
+		// Past the outcome, the player, the enemies and the world must all
+		// end the same
+		played := play(loaded)
+		if got, want := stateJSON(t, played), stateJSON(t, recorded); got != want {
+			t.Errorf("seed %d: replayed state differs from the recorded run", seed)
+		}
+		if len(played.Enemies.Enemies) != len(recorded.Enemies.Enemies) {
+			t.Errorf("seed %d: %d enemies replayed, %d recorded", seed, len(played.Enemies.Enemies), len(recorded.Enemies.Enemies))
+		}
+	}
+}
+
+func TestDivergedRunFails(t *testing.T) {
+	r, _ := record(7, 600)
+	r.Outcome.Health++
+	if _, err := r.Verify(); err == nil {
+		t.Error("a replay that ends differently from its outcome verified")
+	}
+}
@@ -0,0 +1,72 @@
+package save
+
+import (
+	"crydes/sim"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// VERSION is bumped whenever the layout of File changes. Files written by
+// another version are rejected rather than half loaded.
+const VERSION = 5
+
+// DEFAULT_PATH is where the game keeps its single save slot.
+const DEFAULT_PATH = "savegame.json"
+
+// File is a saved run.
+type File struct {
+	Version int       `json:"version"`
+	Sim     sim.State `json:"sim"`
+}
+
+// Write saves the run to path.
+func Write(path string, f *File) error {
+	f.Version = VERSION
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("encoding save: %w", err)
+	}
+
+	// Write next to the target first so a crash never leaves a torn save
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing save: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Read loads a saved run from path.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading save: %w", err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decoding save: %w", err)
+	}
+
+	if f.Version != VERSION {
+		return nil, fmt.Errorf("save %s has version %d, expected %d", path, f.Version, VERSION)
+	}
+
+	return &f, nil
+}
+
+// Exists reports whether there is a save at path.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Delete removes the save at path, if any.
+func Delete(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package sim
+
+import (
+	"crydes/defs"
+	"crydes/events"
+	"crydes/player"
+	"fmt"
+)
+
+const BOSS_DELAY = 2.0 // Seconds between the last key and the arena
+
+// onLastKey starts the countdown to the arena once every key is found.
+func (s *Simulation) onLastKey(key events.KeyCollected) {
+	if key.Count >= player.MAX_KEYS && !s.World.Map.IsArena() {
+		s.BossTimer = BOSS_DELAY
+	}
+}
+
+// updateBoss takes the player to the arena once the countdown is over, a
+// shift in progress finishes first.
+func (s *Simulation) updateBoss(deltaTime float32) {
+	if s.BossTimer <= 0 || s.ShiftClock.Shifting() {
+		return
+	}
+	if s.BossTimer -= deltaTime; s.BossTimer <= 0 {
+		s.BossTimer = 0
+		s.EnterArena()
+	}
+}
+
+// EnterArena moves the player into a boss arena generated from the next
+// seed of the run, alone with a boss picked by weight. The dungeon stops
+// shifting until the boss falls.
+func (s *Simulation) EnterArena() {
+	s.SeedsDrawn++
+	x, y := s.World.SwitchArena(s.seedSource.Int63())
+	s.arriveAt(x, y)
+
+	boss := defs.Get().RandomBoss(s.World.Map.Rand())
+	s.Enemies.Rooms = s.World.Map.GetRoomsRects()
+	s.Enemies.SpawnBoss(boss)
+	s.Collectibles.Restore(nil)
+	s.ShiftClock.Timer = 0
+
+	s.Player.ShowMessage(fmt.Sprintf("%s blocks the way out!", boss.Title))
+	s.Events.Publish(events.BossEncountered{Name: boss.Name})
+}
+
+// InArena reports whether the player is fighting a boss.
+func (s *Simulation) InArena() bool {
+	return s.World.Map.IsArena()
+}
+
+// onBossDefeated wins the run, the boss's minions fall with it.
+func (s *Simulation) onBossDefeated(events.BossDefeated) {
+	s.Enemies.KillMinions()
+	s.Player.Victory()
+}
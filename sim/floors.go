@@ -0,0 +1,118 @@
+package sim
+
+import (
+	"crydes/enemies"
+	"crydes/events"
+	"crydes/world"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// FloorState is a floor the player left, kept as it was so they can climb
+// back to it.
+type FloorState struct {
+	Map     world.MapState       `json:"map"` // Holds the depth of the floor
+	Enemies []enemies.EnemyState `json:"enemies"`
+	Items   []world.ItemState    `json:"items"`
+}
+
+// Depth returns the floor the player is on, 1 being the top one.
+func (s *Simulation) Depth() int {
+	return s.World.Map.Depth()
+}
+
+// Descend takes the player one floor down, to the floor it left there or
+// to a new one generated from the next seed of the run.
+func (s *Simulation) Descend() {
+	s.changeFloor(s.Depth() + 1)
+}
+
+// Ascend takes the player one floor up, the top floor has nowhere to go.
+func (s *Simulation) Ascend() {
+	if s.Depth() > 1 {
+		s.changeFloor(s.Depth() - 1)
+	}
+}
+
+// changeFloor keeps the current floor as it is and moves the player onto
+// the stairs leading back to it, on the floor at depth.
+func (s *Simulation) changeFloor(depth int) {
+	from := s.Depth()
+	s.Floors[from] = FloorState{
+		Map:     s.World.Snapshot(),
+		Enemies: s.Enemies.Snapshot(),
+		Items:   s.Collectibles.Snapshot(),
+	}
+
+	killed := s.Enemies.KilledCount
+	if floor, visited := s.Floors[depth]; visited {
+		delete(s.Floors, depth)
+		s.World.Restore(floor.Map)
+		s.Enemies.Rooms = s.World.Map.GetRoomsRects()
+		s.Enemies.Restore(floor.Enemies, killed)
+		s.Enemies.Projectiles = nil
+		s.Collectibles.Restore(floor.Items)
+	} else {
+		s.World.Map.SetDepth(depth)
+		s.SeedsDrawn++
+		s.arriveAt(s.World.SwitchMap(s.seedSource.Int63()))
+		s.populate()
+		s.Enemies.KilledCount = killed
+	}
+
+	// Arrive on the stairs leading back
+	arrival := world.STAIRS_UP
+	if depth < from {
+		arrival = world.STAIRS_DOWN
+	}
+	if stairs := s.World.Stairs(arrival); stairs != nil {
+		s.arriveAt(stairs.Position.X, stairs.Position.Y)
+	}
+
+	// A floor gets its whole delay before it shifts
+	s.ShiftClock.Timer = 0
+
+	s.Events.Publish(events.FloorChanged{Depth: depth})
+}
+
+// updateStairs takes the stairs the player walks onto. Arriving on stairs
+// doesn't count, the player must step off them first.
+func (s *Simulation) updateStairs() {
+	stairs := s.World.StairsAt(s.Player.Position)
+	wasOnStairs := s.onStairs
+	s.onStairs = stairs != nil
+
+	if stairs == nil || wasOnStairs || s.ShiftClock.Shifting() || s.Player.State == "dying" {
+		return
+	}
+
+	switch stairs.Type {
+	case world.STAIRS_DOWN:
+		s.Descend()
+	case world.STAIRS_UP:
+		s.Ascend()
+	}
+}
+
+// floorStates returns the floors the player left, top one first.
+func (s *Simulation) floorStates() []FloorState {
+	floors := make([]FloorState, 0, len(s.Floors))
+	for _, floor := range s.Floors {
+		floors = append(floors, floor)
+	}
+	sort.Slice(floors, func(i, j int) bool {
+		return floors[i].Map.Depth < floors[j].Map.Depth
+	})
+	return floors
+}
+
+// arriveAt places the player on another layout.
+func (s *Simulation) arriveAt(x, y float32) {
+	s.Player.Position = rl.NewVector2(x, y)
+	s.Player.Previous = s.Player.Position // Don't slide across the new layout
+	s.Player.Shots = nil                  // Shots in flight stay behind
+	s.Numbers.Clear()
+	s.lastSafe = s.Player.Position
+	s.onStairs = s.World.StairsAt(s.Player.Position) != nil
+}
@@ -0,0 +1,103 @@
+package sim
+
+import (
+	"crydes/events"
+	"crydes/player"
+	"math/rand"
+)
+
+// ShiftPhase is where the dungeon is in its shift cycle.
+type ShiftPhase int
+
+const (
+	SHIFT_WAITING    ShiftPhase = iota // Counting down to the next shift
+	SHIFT_FADING_OUT                   // The screen goes dark
+	SHIFT_TAUNTING                     // Dark screen, the dungeon taunts the player
+	SHIFT_FADING_IN                    // The new layout comes into view
+)
+
+const (
+	SHIFT_FADE_DURATION  = 1.0 // Seconds to fade out, and as many to fade back in
+	SHIFT_TAUNT_DURATION = 2.0 // Seconds the taunt stays on screen
+	SHIFT_KEY_WARNING    = 2.0 // Seconds left before the shift once a key is picked up
+)
+
+// ShiftClock schedules the dungeon shifts. It counts simulation time, so
+// a run stepped with the same input shifts on the same tick every time.
+type ShiftClock struct {
+	Phase      ShiftPhase `json:"phase"`
+	Timer      float32    `json:"timer"`       // Seconds waited for the next shift
+	Delay      float32    `json:"delay"`       // Seconds between two shifts
+	PhaseTimer float32    `json:"phase_timer"` // Seconds spent in the current phase
+}
+
+// shiftDelay returns how long a layout generated from seed lasts, between
+// 60 and 70 seconds.
+func shiftDelay(seed int64) float32 {
+	return float32(60 + rand.New(rand.NewSource(seed)).Intn(10))
+}
+
+// Shifting reports whether a shift is in progress.
+func (c *ShiftClock) Shifting() bool {
+	return c.Phase != SHIFT_WAITING
+}
+
+// Fade returns how dark the screen should be, from 0 to 1.
+func (c *ShiftClock) Fade() float32 {
+	switch c.Phase {
+	case SHIFT_FADING_OUT:
+		return c.PhaseTimer / SHIFT_FADE_DURATION
+	case SHIFT_TAUNTING:
+		return 1
+	case SHIFT_FADING_IN:
+		return 1 - c.PhaseTimer/SHIFT_FADE_DURATION
+	}
+	return 0
+}
+
+func (c *ShiftClock) enter(phase ShiftPhase) {
+	c.Phase = phase
+	c.PhaseTimer = 0
+}
+
+// updateShiftClock advances the shift cycle, regenerating the dungeon
+// once the screen is dark.
+func (s *Simulation) updateShiftClock(deltaTime float32) {
+	c := &s.ShiftClock
+	c.PhaseTimer += deltaTime
+
+	switch c.Phase {
+	case SHIFT_WAITING:
+		if s.InArena() {
+			return // The arena holds until the boss falls
+		}
+		c.Timer += deltaTime
+		if c.Timer >= c.Delay {
+			c.enter(SHIFT_FADING_OUT)
+			s.Events.Publish(events.ShiftStarted{})
+		}
+	case SHIFT_FADING_OUT:
+		if c.PhaseTimer >= SHIFT_FADE_DURATION {
+			c.enter(SHIFT_TAUNTING)
+		}
+	case SHIFT_TAUNTING:
+		if c.PhaseTimer >= SHIFT_TAUNT_DURATION {
+			s.ShiftNow()
+			c.enter(SHIFT_FADING_IN)
+		}
+	case SHIFT_FADING_IN:
+		if c.PhaseTimer >= SHIFT_FADE_DURATION {
+			c.enter(SHIFT_WAITING)
+			c.Timer = 0
+			c.Delay = shiftDelay(s.World.Map.Seed())
+		}
+	}
+}
+
+// onKeyCollected brings the next shift close, every key angers the dungeon.
+// The last key leads to the arena instead.
+func (s *Simulation) onKeyCollected(key events.KeyCollected) {
+	if !s.ShiftClock.Shifting() && key.Count < player.MAX_KEYS {
+		s.ShiftClock.Timer = s.ShiftClock.Delay - SHIFT_KEY_WARNING
+	}
+}
@@ -0,0 +1,36 @@
+package sim
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestShiftsLeakNoGoroutines runs a simulation through many shifts and
+// checks it's left with as many goroutines as it started with.
+func TestShiftsLeakNoGoroutines(t *testing.T) {
+	const SHIFTS = 25
+	shiftTime := float32(2*SHIFT_FADE_DURATION + SHIFT_TAUNT_DURATION)
+	shiftTicks := int(shiftTime/TICK) + 10
+
+	s := NewHeadless(11)
+	s.Run(120, wander)
+	before := runtime.NumGoroutine()
+
+	seed := s.World.Map.Seed()
+	for i := 0; i < SHIFTS; i++ {
+		s.ShiftClock.Timer = s.ShiftClock.Delay // The clock runs out on the next tick
+		s.Run(shiftTicks, wander)
+
+		if s.World.Map.Seed() == seed {
+			t.Fatalf("shift %d didn't generate a new layout", i+1)
+		}
+		seed = s.World.Map.Seed()
+	}
+
+	// Give anything that was started a moment to show up or wind down
+	time.Sleep(50 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines after %d shifts, %d before", after, SHIFTS, before)
+	}
+}
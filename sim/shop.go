@@ -0,0 +1,73 @@
+package sim
+
+import (
+	"crydes/defs"
+	"crydes/events"
+	"crydes/helpers"
+	"crydes/world"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// updateShop sells the player the ware they walk onto if they can pay for
+// it, handing it over like an item picked up. They must step off a ware
+// before buying it again.
+func (s *Simulation) updateShop() {
+	ware := s.World.Map.Shop().WareAt(s.Player.Position)
+	wasOnWare := s.onWare
+	s.onWare = ware != nil
+
+	if ware == nil || wasOnWare || s.Player.State == "dying" {
+		return
+	}
+
+	name := strings.ReplaceAll(string(ware.Item), "_", " ")
+	if s.Player.Coins < ware.Price {
+		s.Player.ShowMessage(fmt.Sprintf("A %s for %d coins, I only have %d.", name, ware.Price, s.Player.Coins))
+		return
+	}
+
+	s.Player.Coins -= ware.Price
+	ware.Stock--
+	s.soundManager.RequestSound("key", 1.0, 0.5)
+	s.Events.Publish(world.ItemEvent(0, ware.Item))
+}
+
+// onEnemyKilled drops a coin where the enemy fell, at the odds of its kind.
+func (s *Simulation) onEnemyKilled(killed events.EnemyKilled) {
+	archetype, exists := defs.Get().Enemies[killed.Type]
+	if !exists {
+		return
+	}
+	if s.World.Map.Rand().Float32() < archetype.CoinDrop {
+		s.Collectibles.Drop(world.Coin, killed.Position.X, killed.Position.Y)
+	}
+}
+
+// onItemCollected tells the player where the key of the floor lies when
+// they bought a hint.
+func (s *Simulation) onItemCollected(item events.ItemCollected) {
+	if item.Effect != "key_hint" {
+		return
+	}
+
+	for _, key := range s.Collectibles.Items() {
+		if key.ID != world.KEY_ID || key.Collected {
+			continue
+		}
+		dx, dy := key.Position.X-s.Player.Position.X, key.Position.Y-s.Player.Position.Y
+		tiles := int(math.Hypot(float64(dx), float64(dy))) / helpers.TILE_SIZE
+		s.Player.ShowMessage(fmt.Sprintf("The key lies to the %s, %d steps away.", compass(dx, dy), tiles))
+		return
+	}
+	s.Player.ShowMessage("There's no key left on this floor.")
+}
+
+// compass names the direction of (dx, dy) on screen, y pointing south.
+func compass(dx, dy float32) string {
+	directions := [...]string{"east", "south-east", "south", "south-west", "west", "north-west", "north", "north-east"}
+	angle := math.Atan2(float64(dy), float64(dx))
+	sector := int(math.Round(angle/(math.Pi/4))+8) % 8
+	return directions[sector]
+}
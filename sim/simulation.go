@@ -0,0 +1,232 @@
+package sim
+
+import (
+	"crydes/audio"
+	"crydes/combat"
+	"crydes/enemies"
+	"crydes/events"
+	"crydes/input"
+	"crydes/player"
+	"crydes/world"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TICK is the length of one fixed simulation step in seconds, the game
+// steps at 120 Hz whatever its frame rate and interpolates in between.
+const TICK = float32(1.0 / 120.0)
+
+// Simulation owns the gameplay state of a run: the dungeon, the player
+// state machine, enemies and collectibles. It never draws anything, the
+// game renders it and headless callers just step it.
+type Simulation struct {
+	World        *world.World
+	Player       *player.Player
+	Enemies      *enemies.EnemiesManager
+	Collectibles *world.CollectibleManager
+	Events       *events.Bus // Drained once at the end of every update
+	ShiftClock   ShiftClock
+	Floors       map[int]FloorState // Floors the player left, by depth
+	Validation   Report             // What validating the current floor found
+	Numbers      combat.Numbers     // Damage floating over whoever got hurt
+	BossTimer    float32            // Seconds left before the arena once every key is found
+
+	Ticks int // Fixed steps taken so far
+
+	// Seeding
+	Seed       int64      // Seed the run was started with
+	SeedsDrawn int        // Seeds taken from seedSource, so saves can replay it
+	seedSource *rand.Rand // Derives the seed of every regenerated dungeon
+
+	onStairs bool       // The player stands on stairs it arrived on or already took
+	lastSafe rl.Vector2 // Where a pit throws the player back to
+	atLock   bool       // The player stands at a locked door, and was told so
+	onWare   bool       // The player stands on a ware they bought or were told the price of
+
+	soundManager *audio.SoundManager
+	settled      []rl.Vector2 // Positions moved aside while interpolating
+	config       world.Config // How every dungeon of the run is laid out
+	headless     bool
+}
+
+// New builds a simulation whose first dungeon is generated from seed and
+// laid out as the config says. The sound manager is optional, pass nil to
+// run silently. A headless simulation loads no textures and makes no
+// raylib calls.
+func New(seed int64, config world.Config, sm *audio.SoundManager, headless bool) *Simulation {
+	bus := events.NewBus()
+	w := world.NewWorld(seed, config, headless)
+	cm := world.NewCollectibleManager(bus, headless)
+
+	x, y := w.PlayerSpawn()
+	p := player.NewPlayer(x, y, w.Map, sm, bus)
+	cm.SetPlayerPosition(&p.Position)
+
+	em := enemies.NewEnemiesManager(x, y, w.Map, bus, w.Map.GetRoomsRects(), sm)
+	em.SpawnEnemies()
+
+	cm.ScatterCollectibles(w.Map.GetRoomsRects(), w.Map)
+
+	// A few potions around the spawn to start with
+	cm.AddItem(1, world.HealthPotion, x+20, y+20)
+	cm.AddItem(2, world.SpeedPotion, x+30, y+30)
+	cm.AddItem(3, world.Poison, x-30, y+30)
+
+	s := &Simulation{
+		World:        w,
+		Player:       p,
+		Enemies:      em,
+		Collectibles: cm,
+		Events:       bus,
+		ShiftClock:   ShiftClock{Delay: shiftDelay(seed)},
+		Floors:       map[int]FloorState{},
+		Seed:         seed,
+		seedSource:   rand.New(rand.NewSource(seed)),
+		lastSafe:     rl.NewVector2(x, y),
+		soundManager: sm,
+		config:       config,
+		headless:     headless,
+	}
+	events.Subscribe(bus, s.onKeyCollected)
+	events.Subscribe(bus, s.onLastKey)
+	events.Subscribe(bus, s.onBossDefeated)
+	events.Subscribe(bus, func(killed events.EnemyKilled) {
+		s.Player.GainXP(killed.XP)
+	})
+	events.Subscribe(bus, s.onEnemyKilled)
+	events.Subscribe(bus, s.onItemCollected)
+	events.Subscribe(bus, func(hurt events.DamageTaken) {
+		s.Numbers.Add(hurt.Position, hurt.Amount, hurt.Crit, hurt.Player)
+	})
+	s.validate()
+
+	return s
+}
+
+// NewHeadless builds a silent headless simulation with the default config,
+// for tests and servers.
+func NewHeadless(seed int64) *Simulation {
+	return New(seed, world.DEFAULT_CONFIG, nil, true)
+}
+
+// Config returns how the dungeons of the run are laid out.
+func (s *Simulation) Config() world.Config {
+	return s.config
+}
+
+// Headless reports whether the simulation runs without textures.
+func (s *Simulation) Headless() bool {
+	return s.headless
+}
+
+// Step advances the simulation by one fixed tick using the given controls.
+func (s *Simulation) Step(controls player.Controls) {
+	s.Player.Previous = s.Player.Position
+	for _, e := range s.Enemies.Enemies {
+		e.Previous = e.Position
+	}
+	if s.Enemies.Boss != nil {
+		s.Enemies.Boss.Previous = s.Enemies.Boss.Position
+	}
+
+	s.Player.Controls = controls
+	s.Update(TICK)
+	s.Ticks++
+}
+
+// StepInput advances the simulation by one fixed tick using the actions
+// of that tick, the player's controls and the development shortcuts that
+// change the run.
+func (s *Simulation) StepInput(state input.State) {
+	if state.IsPressed(input.DEBUG_SHIFT) {
+		s.ShiftNow()
+	}
+	s.Step(player.ControlsFrom(state))
+}
+
+// Run steps the simulation n times, asking controls for the input of each tick.
+func (s *Simulation) Run(n int, controls func(tick int) player.Controls) {
+	for i := 0; i < n; i++ {
+		s.Step(controls(s.Ticks))
+	}
+}
+
+// Update advances every gameplay system by deltaTime seconds using the
+// controls currently set on the player.
+func (s *Simulation) Update(deltaTime float32) {
+	s.World.PropsManager.Update(deltaTime)
+	s.Player.Update(deltaTime)
+	s.Enemies.Update(deltaTime, s.Player)
+	s.Collectibles.Update(deltaTime)
+	s.updateTiles(deltaTime)
+	s.updateShop()
+	s.updateStairs()
+	s.updateShiftClock(deltaTime)
+	s.updateBoss(deltaTime)
+	s.Numbers.Update(deltaTime)
+	s.Events.Drain()
+}
+
+// ShiftNow regenerates the dungeon right away from the next seed of the run.
+func (s *Simulation) ShiftNow() {
+	s.SeedsDrawn++
+	s.Shift(s.seedSource.Int63())
+}
+
+// Shift regenerates the current floor from seed and respawns the player,
+// enemies and loot in it.
+func (s *Simulation) Shift(seed int64) {
+	x, y := s.World.SwitchMap(seed)
+	s.arriveAt(x, y)
+	s.populate()
+	s.Events.Publish(events.DungeonShifted{Seed: seed})
+}
+
+// populate spawns enemies and loot in a freshly generated layout, then
+// moves what the player couldn't reach from where they stand.
+func (s *Simulation) populate() {
+	s.Enemies.Rooms = s.World.Map.GetRoomsRects()
+	s.Enemies.ResetEnemies()
+	s.Collectibles.ScatterCollectibles(s.World.Map.GetRoomsRects(), s.World.Map)
+	s.validate()
+}
+
+// Interpolate moves the player and enemies alpha of the way from where
+// they were at the start of the last tick to where they are now, so the
+// frames drawn between two ticks move smoothly. Settle must be called once
+// the frame is drawn.
+func (s *Simulation) Interpolate(alpha float32) {
+	s.settled = append(s.settled[:0], s.Player.Position)
+	s.Player.Position = rl.Vector2Lerp(s.Player.Previous, s.Player.Position, alpha)
+
+	for _, e := range s.Enemies.Enemies {
+		s.settled = append(s.settled, e.Position)
+		e.Position = rl.Vector2Lerp(e.Previous, e.Position, alpha)
+	}
+	if b := s.Enemies.Boss; b != nil {
+		s.settled = append(s.settled, b.Position)
+		b.Position = rl.Vector2Lerp(b.Previous, b.Position, alpha)
+	}
+}
+
+// Settle puts back the positions Interpolate moved.
+func (s *Simulation) Settle() {
+	if len(s.settled) == 0 {
+		return
+	}
+
+	s.Player.Position = s.settled[0]
+	for i, e := range s.Enemies.Enemies {
+		e.Position = s.settled[i+1]
+	}
+	if b := s.Enemies.Boss; b != nil {
+		b.Position = s.settled[len(s.Enemies.Enemies)+1]
+	}
+	s.settled = s.settled[:0]
+}
+
+// GameOver reports whether the run ended, either way.
+func (s *Simulation) GameOver() bool {
+	return s.Player.State == "victory" || s.Player.GameHasEnded()
+}
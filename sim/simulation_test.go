@@ -0,0 +1,114 @@
+package sim
+
+import (
+	"crydes/events"
+	"crydes/helpers"
+	"crydes/player"
+	"crydes/world"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// TestMain runs the tests from the root of the repository, where the
+// definitions and their assets are.
+func TestMain(m *testing.M) {
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// wander walks the player around in a square, swinging now and then, so
+// runs meet enemies and items without any input recorded.
+func wander(tick int) player.Controls {
+	c := player.Controls{Attack: tick%45 == 0}
+	switch (tick / 240) % 4 {
+	case 0:
+		c.Right = true
+	case 1:
+		c.Down = true
+	case 2:
+		c.Left = true
+	case 3:
+		c.Up = true
+	}
+	return c
+}
+
+// stateJSON serializes the whole state of s, to compare two runs.
+func stateJSON(t *testing.T, s *Simulation) string {
+	t.Helper()
+	data, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestHeadlessRun(t *testing.T) {
+	s := NewHeadless(7)
+	if !s.Headless() || !s.World.Map.Headless() {
+		t.Fatal("headless simulation built a map that loads textures")
+	}
+
+	spawn := s.Player.Position
+	s.Run(1200, wander)
+
+	if s.Player.Position == spawn {
+		t.Error("player never left the spawn")
+	}
+	if s.Ticks != 1200 {
+		t.Errorf("ticks = %d, want 1200", s.Ticks)
+	}
+	feet := s.Player.GetPlayerCenterPoint()
+	if !s.World.Map.IsWalkableFloat(feet.X, feet.Y) {
+		t.Errorf("player ended off the floor at %v", feet)
+	}
+}
+
+func TestSameSeedSameRun(t *testing.T) {
+	for _, seed := range []int64{1, 42, 1234} {
+		a, b := NewHeadless(seed), NewHeadless(seed)
+		a.Run(1500, wander)
+		b.Run(1500, wander)
+
+		if stateJSON(t, a) != stateJSON(t, b) {
+			t.Errorf("seed %d: two runs with the same input ended differently", seed)
+		}
+	}
+}
+
+func TestSeedsDiffer(t *testing.T) {
+	a, b := NewHeadless(1), NewHeadless(2)
+	if stateJSON(t, a) == stateJSON(t, b) {
+		t.Error("seeds 1 and 2 generated the same run")
+	}
+}
+
+// TestKillGivesXP checks the player earns the experience carried by the
+// kill, not looked up among the enemies on the floor.
+func TestKillGivesXP(t *testing.T) {
+	s := NewHeadless(4)
+	s.Events.Publish(events.EnemyKilled{ID: -1, Type: "spider", XP: 3})
+	s.Events.Drain()
+
+	if s.Player.XP != 3 {
+		t.Errorf("xp = %d after a kill worth 3", s.Player.XP)
+	}
+}
+
+// TestConfigSizesFloors checks the top floor takes the size of the config
+// and the floors below grow from it.
+func TestConfigSizesFloors(t *testing.T) {
+	s := New(3, world.Config{Width: 60, Height: 80}, nil, true)
+	if w, h := s.World.Map.Width(), s.World.Map.Height(); w != 60 || h != 80 {
+		t.Errorf("top floor is %dx%d, want 60x80", w, h)
+	}
+
+	s.Descend()
+	want := helpers.MAP_GROWTH
+	if w, h := s.World.Map.Width(), s.World.Map.Height(); w != 60+want || h != 80+want {
+		t.Errorf("second floor is %dx%d, want %dx%d", w, h, 60+want, 80+want)
+	}
+}
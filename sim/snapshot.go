@@ -0,0 +1,86 @@
+package sim
+
+import (
+	"crydes/audio"
+	"crydes/enemies"
+	"crydes/player"
+	"crydes/world"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// State is the serializable form of a simulation.
+type State struct {
+	Map         world.MapState       `json:"map"`
+	Player      player.PlayerState   `json:"player"`
+	Enemies     []enemies.EnemyState `json:"enemies"`
+	Items       []world.ItemState    `json:"items"`
+	KilledCount int                  `json:"killed_count"`
+	Ticks       int                  `json:"ticks"`
+	ShiftClock  ShiftClock           `json:"shift_clock"`
+	RunSeed     int64                `json:"run_seed"`
+	SeedsDrawn  int                  `json:"seeds_drawn"` // Seeds derived from RunSeed so far
+	Floors      []FloorState         `json:"floors"`      // Floors left behind, top one first
+	OnStairs    bool                 `json:"on_stairs"`
+	OnWare      bool                 `json:"on_ware,omitempty"`
+	LastSafeX   float32              `json:"last_safe_x"` // Where a pit throws the player back to
+	LastSafeY   float32              `json:"last_safe_y"`
+	Boss        *enemies.BossState   `json:"boss,omitempty"`       // Boss still fighting in the arena
+	BossTimer   float32              `json:"boss_timer,omitempty"` // Seconds left before the arena
+}
+
+// Snapshot captures the whole gameplay state.
+func (s *Simulation) Snapshot() State {
+	return State{
+		Map:         s.World.Snapshot(),
+		Player:      s.Player.Snapshot(),
+		Enemies:     s.Enemies.Snapshot(),
+		Items:       s.Collectibles.Snapshot(),
+		KilledCount: s.Enemies.KilledCount,
+		Ticks:       s.Ticks,
+		ShiftClock:  s.ShiftClock,
+		RunSeed:     s.Seed,
+		SeedsDrawn:  s.SeedsDrawn,
+		Floors:      s.floorStates(),
+		OnStairs:    s.onStairs,
+		OnWare:      s.onWare,
+		LastSafeX:   s.lastSafe.X,
+		LastSafeY:   s.lastSafe.Y,
+		Boss:        s.Enemies.SnapshotBoss(),
+		BossTimer:   s.BossTimer,
+	}
+}
+
+// Restore builds a simulation from a saved state, laying out the dungeons
+// it generates from then on as the config says. The pathfinder and props
+// are rebuilt from the saved dungeon.
+func Restore(state State, config world.Config, sm *audio.SoundManager, headless bool) *Simulation {
+	s := New(state.Map.Seed, config, sm, headless)
+
+	s.World.Restore(state.Map)
+	s.Player.Restore(state.Player)
+	s.Enemies.Rooms = s.World.Map.GetRoomsRects()
+	s.Enemies.Restore(state.Enemies, state.KilledCount)
+	s.Enemies.RestoreBoss(state.Boss)
+	s.BossTimer = state.BossTimer
+	s.Collectibles.Restore(state.Items)
+	s.Ticks = state.Ticks
+	s.ShiftClock = state.ShiftClock
+	s.onStairs = state.OnStairs
+	s.onWare = state.OnWare
+	s.lastSafe = rl.NewVector2(state.LastSafeX, state.LastSafeY)
+	for _, floor := range state.Floors {
+		s.Floors[floor.Map.Depth] = floor
+	}
+
+	// Replay the seed source so later shifts match the original run
+	s.Seed = state.RunSeed
+	s.seedSource = rand.New(rand.NewSource(s.Seed))
+	for i := 0; i < state.SeedsDrawn; i++ {
+		s.seedSource.Int63()
+	}
+	s.SeedsDrawn = state.SeedsDrawn
+
+	return s
+}
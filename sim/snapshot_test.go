@@ -0,0 +1,73 @@
+package sim
+
+import (
+	"crydes/world"
+	"reflect"
+	"testing"
+)
+
+// TestRestoreKeepsPropsAndRandom checks a run restored from a snapshot has
+// its props where they were and draws what the original run draws next.
+func TestRestoreKeepsPropsAndRandom(t *testing.T) {
+	for _, seed := range []int64{3, 99} {
+		s := NewHeadless(seed)
+		s.Run(600, wander)
+
+		restored := Restore(s.Snapshot(), s.Config(), nil, true)
+		if got, want := stateJSON(t, restored), stateJSON(t, s); got != want {
+			t.Fatalf("seed %d: restored state differs from the saved one", seed)
+		}
+		for i := 0; i < 10; i++ {
+			if got, want := restored.World.Map.Rand().Int63(), s.World.Map.Rand().Int63(); got != want {
+				t.Fatalf("seed %d: draw %d after restoring is %d, want %d", seed, i, got, want)
+			}
+		}
+	}
+}
+
+// TestFloorRevisitKeepsProps checks the props of a floor stay where they
+// were when the player climbs back to it.
+func TestFloorRevisitKeepsProps(t *testing.T) {
+	s := NewHeadless(5)
+	before := props(s)
+	if len(before) == 0 {
+		t.Fatal("the top floor has no props")
+	}
+
+	s.Descend()
+	s.Ascend()
+	after := props(s)
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("props moved after climbing back: %d props, %d before", len(after), len(before))
+	}
+}
+
+func props(s *Simulation) []world.PropState {
+	return s.World.Snapshot().Props
+}
+
+// TestEnemyIDsUnique checks no two enemies share an ID, on a new floor and
+// once a run is restored and spawns more.
+func TestEnemyIDsUnique(t *testing.T) {
+	s := NewHeadless(8)
+	checkEnemyIDs(t, "new floor", s)
+
+	restored := Restore(s.Snapshot(), s.Config(), nil, true)
+	restored.Descend()
+	restored.Ascend()
+	checkEnemyIDs(t, "restored run", restored)
+}
+
+func checkEnemyIDs(t *testing.T, name string, s *Simulation) {
+	t.Helper()
+	if len(s.Enemies.Enemies) < 2 {
+		t.Fatalf("%s: only %d enemies", name, len(s.Enemies.Enemies))
+	}
+	seen := map[int]bool{}
+	for _, e := range s.Enemies.Enemies {
+		if seen[e.ID] {
+			t.Errorf("%s: enemy ID %d given twice", name, e.ID)
+		}
+		seen[e.ID] = true
+	}
+}
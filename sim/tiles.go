@@ -0,0 +1,61 @@
+package sim
+
+import (
+	"crydes/events"
+	"crydes/world"
+	"fmt"
+	"strings"
+)
+
+// updateTiles runs the traps and applies the tiles around the player:
+// doors open as they walk up to them, locked ones if they hold the key,
+// pits hurt and throw them back to the last safe tile they stood on,
+// raised spikes hurt and plates raise them.
+func (s *Simulation) updateTiles(deltaTime float32) {
+	mp := s.World.Map
+	mp.UpdateTraps(deltaTime)
+
+	if s.Player.State == "dying" {
+		return
+	}
+
+	x, y := s.Player.ConvertToMapPosition()
+	atLock := false
+	for _, dir := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		dx, dy := x+dir[0], y+dir[1]
+		if mp.OpenDoor(dx, dy) {
+			s.soundManager.RequestSound("step", 1.0, 0.5) // No door sound yet, a heavy step will do
+		}
+
+		lock, locked := mp.LockAt(dx, dy)
+		if !locked {
+			continue
+		}
+		if s.Player.UseDoorKey(lock) {
+			mp.Unlock(dx, dy)
+			s.soundManager.RequestSound("key", 1.0, 0.7)
+			continue
+		}
+		if !s.atLock {
+			s.Player.ShowMessage(fmt.Sprintf("Locked. I need a %s.", strings.ReplaceAll(string(lock.Key()), "_", " ")))
+		}
+		atLock = true
+	}
+	s.atLock = atLock
+
+	switch mp.TileAt(x, y) {
+	case world.TilePit:
+		s.Events.Publish(events.DamageDealt{Target: events.TARGET_PLAYER, Amount: world.PIT_DAMAGE})
+		s.Player.Position = s.lastSafe
+		s.Player.Previous = s.lastSafe
+		return
+	case world.TileSpikes:
+		if mp.SpikesUp(x, y) {
+			s.Events.Publish(events.DamageDealt{Target: events.TARGET_PLAYER, Amount: world.SPIKES_DAMAGE})
+		}
+		return
+	case world.TilePlate:
+		mp.PressPlate(x, y)
+	}
+	s.lastSafe = s.Player.Position
+}
@@ -0,0 +1,96 @@
+package sim
+
+import (
+	"crydes/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Report is what the validation pass found on a freshly populated floor.
+type Report struct {
+	world.Metrics
+	Seed  int64 // Seed of the layout
+	Depth int   // Floor of the layout
+
+	Relocated   int  // Items, enemies, wares and stairs moved where the player can reach them
+	Unreachable int  // Things still out of reach once moved, only when nothing is reachable
+	HasKey      bool // Whether the floor holds a key the player can reach
+	Locks       int  // Locked doors on the floor
+	Unlockable  bool // Whether the keys to every locked door can be found in time
+}
+
+// Valid reports whether the player can walk to every room, the key and
+// everything spawned on the floor, opening the locked doors on the way.
+func (r Report) Valid() bool {
+	return r.UnreachableRooms == 0 && r.Unreachable == 0 && r.HasKey && r.Unlockable
+}
+
+// validate floods the floor from the player and moves everything they
+// couldn't walk to onto the nearest tile they can, then checks the keys to
+// the locked doors can be picked up in an order that opens them all.
+func (s *Simulation) validate() Report {
+	mp := s.World.Map
+	reach := mp.Reach(s.Player.Position)
+	report := Report{
+		Metrics: mp.Measure(&reach),
+		Seed:    mp.Seed(),
+		Depth:   mp.Depth(),
+		Locks:   mp.Locks(),
+	}
+
+	relocate := func(pos *rl.Vector2) bool {
+		moved, ok := mp.Relocate(&reach, *pos)
+		if !ok {
+			report.Unreachable++
+			return false
+		}
+		if moved != *pos {
+			*pos = moved
+			report.Relocated++
+		}
+		return true
+	}
+
+	keys := map[world.Lock]rl.Vector2{}
+	for _, item := range s.Collectibles.Items() {
+		if relocate(&item.Position) && item.ID == world.KEY_ID {
+			report.HasKey = true
+		}
+		if lock, isKey := world.LockOf(item.ItemType); isKey {
+			keys[lock] = item.Position
+		}
+	}
+	for _, e := range s.Enemies.Enemies {
+		relocate(&e.Position)
+		e.Previous = e.Position
+	}
+	if shop := mp.Shop(); shop != nil {
+		for _, ware := range shop.Wares {
+			relocate(&ware.Position)
+		}
+	}
+	for _, kind := range []string{world.STAIRS_DOWN, world.STAIRS_UP} {
+		if stairs := s.World.Stairs(kind); stairs != nil {
+			relocate(&stairs.Position)
+		}
+	}
+
+	report.Unlockable = mp.Unlockable(s.Player.Position, keys)
+
+	s.Validation = report
+	return report
+}
+
+// ValidateSeed plays the first floors of the run generated from seed and
+// laid out as the config says, walking down the stairs as soon as each
+// floor is ready, and returns the report of every floor. Definitions must
+// be loaded first.
+func ValidateSeed(seed int64, floors int, config world.Config) []Report {
+	s := New(seed, config, nil, true)
+	reports := []Report{s.Validation}
+	for len(reports) < floors {
+		s.Descend()
+		reports = append(reports, s.Validation)
+	}
+	return reports
+}
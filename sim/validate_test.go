@@ -0,0 +1,26 @@
+package sim
+
+import (
+	"crydes/world"
+	"testing"
+)
+
+// TestSeedsAreSolvable checks the first floors of a range of runs, failing
+// on the first floor that leaves a room, the key or a locked door out of
+// reach.
+func TestSeedsAreSolvable(t *testing.T) {
+	const FLOORS = 3
+	seeds := int64(50)
+	if testing.Short() {
+		seeds = 10
+	}
+
+	for seed := int64(1); seed <= seeds; seed++ {
+		for _, r := range ValidateSeed(seed, FLOORS, world.DEFAULT_CONFIG) {
+			if !r.Valid() {
+				t.Fatalf("seed %d floor %d: %d rooms unreachable, %d things unreachable, key reachable %t, doors unlockable %t",
+					seed, r.Depth, r.UnreachableRooms, r.Unreachable, r.HasKey, r.Unlockable)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package status
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// KINDS are the status effects there are, by name.
+var KINDS = map[string]*Kind{}
+
+// ORDER is the order the effects are listed in.
+var ORDER []string
+
+func register(kind *Kind) {
+	KINDS[kind.Name] = kind
+	ORDER = append(ORDER, kind.Name)
+}
+
+func init() {
+	register(&Kind{
+		Name:     "speed",
+		Label:    "Speed Boost",
+		Message:  "I feel faster!",
+		Stacking: StackRefresh,
+		Stat:     "speed",
+		Color:    rl.Green,
+	})
+
+	register(&Kind{
+		Name:     "slow",
+		Label:    "Slowed",
+		Message:  "My legs feel heavy...",
+		Stacking: StackRefresh,
+		Stat:     "speed",
+		Color:    rl.SkyBlue,
+	})
+
+	register(&Kind{
+		Name:     "poison",
+		Label:    "Poisoned",
+		Message:  "This poison burns...",
+		Stacking: StackRefresh,
+		Interval: 1,
+		OnTick:   func(t Target, e *Effect) { t.Hurt(int(e.Value)) },
+		Color:    rl.Purple,
+	})
+
+	register(&Kind{
+		Name:      "burn",
+		Label:     "Burning",
+		Message:   "I'm on fire!",
+		Stacking:  StackIntensity,
+		MaxStacks: 3,
+		Interval:  0.5,
+		OnTick:    func(t Target, e *Effect) { t.Hurt(int(e.Strength())) },
+		Color:     rl.Orange,
+	})
+
+	register(&Kind{
+		Name:     "regeneration",
+		Label:    "Regenerating",
+		Message:  "My wounds are closing.",
+		Stacking: StackExtend,
+		Interval: 2,
+		OnTick:   func(t Target, e *Effect) { t.Heal(int(e.Value)) },
+		Color:    rl.Pink,
+	})
+
+	register(&Kind{
+		Name:     "shield",
+		Label:    "Shielded",
+		Message:  "Nothing can touch me now.",
+		Stacking: StackRefresh,
+		Absorbs:  true,
+		OnExpire: func(t Target, e *Effect) { t.Say("My shield is gone.") },
+		Color:    rl.Gold,
+	})
+
+	register(&Kind{
+		Name:     "invisibility",
+		Label:    "Invisible",
+		Message:  "I can't even see myself.",
+		Stacking: StackExtend,
+		OnExpire: func(t Target, e *Effect) { t.Say("They can see me again.") },
+		Color:    rl.LightGray,
+	})
+
+	register(&Kind{
+		Name:     "confusion",
+		Label:    "Confused",
+		Message:  "Which way is up?",
+		Stacking: StackRefresh,
+		Color:    rl.Magenta,
+	})
+}
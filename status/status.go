@@ -0,0 +1,267 @@
+// Package status runs the status effects on the player and enemies:
+// boosts, poisons and the like that last a while, tick now and then and
+// stack by the rules of their kind.
+package status
+
+import (
+	"math"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TICK_SLACK is how close to a tick, or to its end, an effect must be to
+// count it as reached, so float steps don't lose the last one.
+const TICK_SLACK = 1e-3
+
+// Target is what effects act on, the player or an enemy.
+type Target interface {
+	Heal(amount int)
+	Hurt(amount int) // Health lost to an effect, invulnerability doesn't help
+	Say(message string)
+}
+
+// Stacking is what applying an effect already running does.
+type Stacking int
+
+const (
+	StackRefresh   Stacking = iota // Replaces its value and restarts it
+	StackExtend                    // Adds the new duration to what's left
+	StackIntensity                 // Adds a stack, up to MaxStacks, and restarts it
+)
+
+// Kind describes a status effect and the hooks that make it act.
+type Kind struct {
+	Name      string
+	Label     string   // Shown on the HUD
+	Color     rl.Color // How it's shown, on the HUD and on enemies
+	Message   string   // Said by the player when it takes hold
+	Stacking  Stacking
+	MaxStacks int     // Stacks StackIntensity effects go up to
+	Interval  float32 // Seconds between two ticks, 0 for effects that never tick
+	Stat      string  // Stat whose value the effect multiplies, if any
+	Absorbs   bool    // Value is the damage it soaks up before breaking
+
+	OnApply  func(t Target, e *Effect)
+	OnTick   func(t Target, e *Effect)
+	OnExpire func(t Target, e *Effect)
+}
+
+// Effect is a status effect running on a target.
+type Effect struct {
+	Kind     *Kind
+	Value    float32
+	Stacks   int
+	Duration float32 // Seconds it lasts in all
+	Elapsed  float32 // Seconds it has run
+	Ticks    int     // Ticks done since it was last restarted
+}
+
+// Remaining returns the seconds the effect has left.
+func (e *Effect) Remaining() float32 {
+	return e.Duration - e.Elapsed
+}
+
+// Strength returns the value of the effect times its stacks.
+func (e *Effect) Strength() float32 {
+	return e.Value * float32(e.Stacks)
+}
+
+// Effects are the status effects on a target, in the order they were
+// applied.
+type Effects struct {
+	active []*Effect
+	immune map[string]bool
+}
+
+// Apply starts the named effect on t for duration seconds, or stacks it
+// with the one running. Unknown effects and those t is immune to are
+// ignored, it returns nil for them.
+func (s *Effects) Apply(t Target, name string, value, duration float32) *Effect {
+	kind := KINDS[name]
+	if kind == nil || s.immune[name] {
+		return nil
+	}
+
+	e := s.Get(name)
+	if e == nil {
+		e = &Effect{Kind: kind, Value: value, Stacks: 1, Duration: duration}
+		s.active = append(s.active, e)
+	} else {
+		switch kind.Stacking {
+		case StackRefresh:
+			e.Value, e.Duration, e.Elapsed, e.Ticks = value, duration, 0, 0
+		case StackExtend:
+			e.Duration += duration
+		case StackIntensity:
+			e.Stacks = int(math.Min(float64(e.Stacks+1), float64(kind.MaxStacks)))
+			e.Value, e.Duration, e.Elapsed, e.Ticks = value, duration, 0, 0
+		}
+	}
+
+	if kind.Message != "" {
+		t.Say(kind.Message)
+	}
+	if kind.OnApply != nil {
+		kind.OnApply(t, e)
+	}
+	return e
+}
+
+// Update runs the effects for deltaTime seconds, ticking them on their
+// interval and ending those that ran out.
+func (s *Effects) Update(t Target, deltaTime float32) {
+	for _, e := range append([]*Effect(nil), s.active...) {
+		e.Elapsed += deltaTime
+
+		if e.Kind.Interval > 0 && e.Kind.OnTick != nil {
+			for due := int(e.Elapsed/e.Kind.Interval + TICK_SLACK); e.Ticks < due; e.Ticks++ {
+				e.Kind.OnTick(t, e)
+			}
+		}
+
+		if e.Elapsed+TICK_SLACK >= e.Duration {
+			s.end(t, e)
+		}
+	}
+}
+
+// end removes a running effect and lets it undo what it did.
+func (s *Effects) end(t Target, e *Effect) {
+	for i, a := range s.active {
+		if a == e {
+			s.active = append(s.active[:i], s.active[i+1:]...)
+			break
+		}
+	}
+	if e.Kind.OnExpire != nil {
+		e.Kind.OnExpire(t, e)
+	}
+}
+
+// Remove ends the named effect right away.
+func (s *Effects) Remove(t Target, name string) {
+	if e := s.Get(name); e != nil {
+		s.end(t, e)
+	}
+}
+
+// Clear drops every effect without running their hooks.
+func (s *Effects) Clear() {
+	s.active = nil
+}
+
+// Get returns the named effect, nil when it isn't running.
+func (s *Effects) Get(name string) *Effect {
+	for _, e := range s.active {
+		if e.Kind.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Has reports whether the named effect is running.
+func (s *Effects) Has(name string) bool {
+	return s.Get(name) != nil
+}
+
+// All returns the running effects, oldest first.
+func (s *Effects) All() []*Effect {
+	return s.active
+}
+
+// Multiplier returns what the running effects multiply stat by, 1 when
+// none does.
+func (s *Effects) Multiplier(stat string) float32 {
+	m := float32(1)
+	for _, e := range s.active {
+		if e.Kind.Stat == stat {
+			m *= e.Value
+		}
+	}
+	return m
+}
+
+// Absorb soaks up as much of amount as the running shields can, breaking
+// those used up, and returns the damage that gets through.
+func (s *Effects) Absorb(t Target, amount int) int {
+	for _, e := range append([]*Effect(nil), s.active...) {
+		if !e.Kind.Absorbs || amount <= 0 {
+			continue
+		}
+		soaked := int(math.Min(float64(amount), float64(e.Value)))
+		e.Value -= float32(soaked)
+		amount -= soaked
+		if e.Value <= 0 {
+			s.end(t, e)
+		}
+	}
+	return amount
+}
+
+// SetImmune makes the target immune to the named effect, or not. An
+// effect already running is left to run out.
+func (s *Effects) SetImmune(name string, immune bool) {
+	if s.immune == nil {
+		s.immune = map[string]bool{}
+	}
+	s.immune[name] = immune
+}
+
+// Immune reports whether the target shrugs off the named effect.
+func (s *Effects) Immune(name string) bool {
+	return s.immune[name]
+}
+
+// EffectState is the serializable form of a running effect. Remaining is
+// stored instead of the expiry time so the effect resumes where it stopped.
+type EffectState struct {
+	Type      string        `json:"type"`
+	Value     float32       `json:"value"`
+	Duration  time.Duration `json:"duration"`
+	Remaining time.Duration `json:"remaining"`
+	Stacks    int           `json:"stacks,omitempty"` // 0 in saves from before stacking, read as 1
+	Ticks     int           `json:"ticks,omitempty"`
+}
+
+// Snapshot captures the running effects.
+func (s *Effects) Snapshot() []EffectState {
+	var states []EffectState
+	for _, e := range s.active {
+		states = append(states, EffectState{
+			Type:      e.Kind.Name,
+			Value:     e.Value,
+			Duration:  seconds(e.Duration),
+			Remaining: seconds(e.Remaining()),
+			Stacks:    e.Stacks,
+			Ticks:     e.Ticks,
+		})
+	}
+	return states
+}
+
+// Restore replaces the running effects with saved ones, without running
+// their hooks. Effects that no longer exist are dropped.
+func (s *Effects) Restore(states []EffectState) {
+	s.active = nil
+	for _, state := range states {
+		kind := KINDS[state.Type]
+		if kind == nil || state.Remaining <= 0 {
+			continue
+		}
+		duration := float32(state.Duration.Seconds())
+		s.active = append(s.active, &Effect{
+			Kind:     kind,
+			Value:    state.Value,
+			Stacks:   int(math.Max(1, float64(state.Stacks))),
+			Duration: duration,
+			Elapsed:  duration - float32(state.Remaining.Seconds()),
+			Ticks:    state.Ticks,
+		})
+	}
+}
+
+func seconds(s float32) time.Duration {
+	return time.Duration(s * float32(time.Second))
+}
@@ -0,0 +1,82 @@
+package world
+
+import (
+	"crydes/helpers"
+	"math/rand"
+)
+
+// ARENA is the generator name of the layout a boss is fought in. It isn't
+// one of GENERATORS, shifts never pick it.
+const ARENA = "arena"
+
+const (
+	ARENA_WIDTH    = 32 // Tiles across the arena floor
+	ARENA_HEIGHT   = 24 // Tiles down the arena floor
+	ARENA_MARGIN   = 2  // Wall tiles around the arena
+	PILLAR_SIZE    = 2  // Tiles across a pillar
+	PILLAR_INSET   = 7  // Tiles between a pillar and the nearest side of the arena
+	ARENA_EDGE_GAP = 2  // Tiles between the player's arrival, or the boss, and the arena's edge
+)
+
+// ArenaGenerator lays out a single large room with a pillar near each
+// corner, something to hide behind when the boss charges.
+type ArenaGenerator struct{}
+
+func (ArenaGenerator) Generate(grid *Grid, rng *rand.Rand) []*Room {
+	room := &Room{
+		Rectangle: helpers.Rectangle{X: ARENA_MARGIN, Y: ARENA_MARGIN, Width: ARENA_WIDTH, Height: ARENA_HEIGHT},
+		Size:      LargeRoom,
+	}
+	grid.carveRoom(room.Rectangle)
+
+	for _, x := range []int32{room.X + PILLAR_INSET, room.X + room.Width - PILLAR_INSET - PILLAR_SIZE} {
+		for _, y := range []int32{room.Y + PILLAR_INSET, room.Y + room.Height - PILLAR_INSET - PILLAR_SIZE} {
+			for dx := int32(0); dx < PILLAR_SIZE; dx++ {
+				for dy := int32(0); dy < PILLAR_SIZE; dy++ {
+					grid.Set(int(x+dx), int(y+dy), TileWall)
+				}
+			}
+		}
+	}
+
+	return []*Room{room}
+}
+
+// SwitchArena replaces the layout with a boss arena generated from seed,
+// returning where the player arrives: the bottom middle of the arena.
+func (m *Map) SwitchArena(seed int64) (float32, float32) {
+	m.seed = seed
+	m.reseed(seed, 0)
+	m.initDungeon()
+	m.dungeon = NewGrid(ARENA_WIDTH+ARENA_MARGIN*2, ARENA_HEIGHT+ARENA_MARGIN*2)
+	m.generator = ARENA
+	m.rooms = ArenaGenerator{}.Generate(&m.dungeon, m.rng)
+
+	room := m.rooms[0]
+	return float32((room.X + room.Width/2) * helpers.TILE_SIZE),
+		float32((room.Y + room.Height - 1 - ARENA_EDGE_GAP) * helpers.TILE_SIZE)
+}
+
+// IsArena reports whether the current layout is a boss arena.
+func (m *Map) IsArena() bool {
+	return m.generator == ARENA
+}
+
+// BossSpawn returns where the boss waits in the arena, the top middle of
+// it, across from the player.
+func (m *Map) BossSpawn() (float32, float32) {
+	room := m.rooms[0]
+	return float32((room.X + room.Width/2) * helpers.TILE_SIZE),
+		float32((room.Y + ARENA_EDGE_GAP) * helpers.TILE_SIZE)
+}
+
+// SwitchArena moves the world into a boss arena generated from seed.
+func (w *World) SwitchArena(seed int64) (float32, float32) {
+	x, y := w.Map.SwitchArena(seed)
+
+	w.Pathfinder = NewPathfinder(w.Map)
+	w.PropsManager = newPropsManager(w.Map.GetRooms(), w.Map)
+	w.PropsManager.SetUpProps()
+
+	return x, y
+}
@@ -0,0 +1,109 @@
+package world
+
+import (
+	"crydes/helpers"
+	"math/rand"
+)
+
+// BSPGenerator splits the map into rooms with Binary Space Partitioning and
+// links them with Kruskal corridors.
+type BSPGenerator struct{}
+
+// bspLayout is a BSP layout being generated.
+type bspLayout struct {
+	rng   *rand.Rand
+	rooms []*Room
+}
+
+func (BSPGenerator) Generate(grid *Grid, rng *rand.Rand) []*Room {
+	b := &bspLayout{rng: rng}
+	for len(b.rooms) < 3 {
+		b.rooms = []*Room{}
+		b.bspSplit(helpers.Rectangle{X: 1, Y: 1, Width: int32(grid.Width - 2), Height: int32(grid.Height - 2)}, 0)
+	}
+
+	for _, room := range b.rooms {
+		grid.carveRoom(room.Rectangle)
+	}
+	connectRooms(grid, b.rooms, rng)
+	return b.rooms
+}
+
+// Split the map into rooms using Binary Space Partitioning.
+func (b *bspLayout) bspSplit(area helpers.Rectangle, depth int) {
+	if depth >= helpers.MAX_DEPTH {
+		roomSize := b.chooseRoomSize(depth)
+		roomWidth, roomHeight := b.getRoomDimensions(roomSize)
+
+		if int(area.Width)-int(roomWidth) < 3 || int(area.Height)-int(roomHeight) < 3 {
+			return
+		}
+
+		maxAttempts := 100
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			roomX := int(area.X) + (int(area.Width)-int(roomWidth))/2 + b.rng.Intn(3) - 1
+			roomY := int(area.Y) + (int(area.Height)-int(roomHeight))/2 + b.rng.Intn(3) - 1
+
+			newRoom := Room{
+				Rectangle: helpers.Rectangle{X: int32(roomX), Y: int32(roomY), Width: roomWidth, Height: roomHeight},
+				Size:      roomSize,
+			}
+
+			if b.isValidRoomPlacement(newRoom.Rectangle) {
+				b.rooms = append(b.rooms, &newRoom)
+				return
+			}
+		}
+		return
+	}
+
+	splitRatio := 0.4 + b.rng.Float64()*0.2
+
+	if b.rng.Intn(2) == 0 && area.Width > helpers.MIN_ROOM_SIZE*2 {
+		split := int(float64(area.Width) * splitRatio)
+		b.bspSplit(helpers.Rectangle{area.X, area.Y, int32(split), area.Height}, depth+1)
+		b.bspSplit(helpers.Rectangle{area.X + int32(split), area.Y, area.Width - int32(split), area.Height}, depth+1)
+	} else if area.Height > helpers.MIN_ROOM_SIZE*2 {
+		split := int(float64(area.Height) * splitRatio)
+		b.bspSplit(helpers.Rectangle{area.X, area.Y, area.Width, int32(split)}, depth+1)
+		b.bspSplit(helpers.Rectangle{area.X, area.Y + int32(split), area.Width, area.Height - int32(split)}, depth+1)
+	}
+}
+
+func (b *bspLayout) chooseRoomSize(depth int) RoomSize {
+	if depth > 5 {
+		return RoomSize(b.rng.Intn(3))
+	}
+	if b.rng.Float32() < 0.6 {
+		return SmallRoom
+	}
+	return MediumRoom
+}
+
+func (b *bspLayout) getRoomDimensions(size RoomSize) (width, height int32) {
+	switch size {
+	case SmallRoom:
+		width = int32(b.rng.Intn(4) + 3)  // 3-5
+		height = int32(b.rng.Intn(4) + 3) // 3-5
+	case MediumRoom:
+		width = int32(b.rng.Intn(4) + 6)  // 6-8
+		height = int32(b.rng.Intn(4) + 6) // 6-8
+	case LargeRoom:
+		width = int32(b.rng.Intn(7) + 9)  // 9-13
+		height = int32(b.rng.Intn(7) + 9) // 9-13
+	}
+
+	width += 3
+	height += 3
+
+	return
+}
+
+func (b *bspLayout) isValidRoomPlacement(newRoom helpers.Rectangle) bool {
+	for _, room := range b.rooms {
+		if room.Intersects(newRoom) {
+			return false
+		}
+	}
+	return true
+}
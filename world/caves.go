@@ -0,0 +1,67 @@
+package world
+
+import (
+	"math/rand"
+)
+
+const (
+	CAVE_FILL      = 0.52 // Odds a tile starts out as rock
+	CAVE_SMOOTHING = 4    // Cellular automaton passes over the noise
+	CAVE_CHAMBERS  = 8    // Open chambers the cave is grown around
+)
+
+// CaveGenerator grows natural caves with a cellular automaton over random
+// noise. Open chambers are kept clear in it to serve as rooms, linked by
+// corridors so every part of the cave can be reached.
+type CaveGenerator struct{}
+
+func (CaveGenerator) Generate(grid *Grid, rng *rand.Rand) []*Room {
+	for x := 1; x < grid.Width-1; x++ {
+		for y := 1; y < grid.Height-1; y++ {
+			if rng.Float32() >= CAVE_FILL {
+				grid.Set(x, y, 1)
+			}
+		}
+	}
+
+	for i := 0; i < CAVE_SMOOTHING; i++ {
+		smoothCave(grid)
+	}
+
+	var rooms []*Room
+	for attempt := 0; len(rooms) < CAVE_CHAMBERS && (attempt < 200 || len(rooms) < 3); attempt++ {
+		width, height := int32(6+rng.Intn(7)), int32(6+rng.Intn(7))
+		rect := randomRect(grid, rng, width, height)
+		if overlapsAny(rooms, rect, 2) {
+			continue
+		}
+		rooms = append(rooms, &Room{Rectangle: rect, Size: roomSizeFor(width, height)})
+	}
+
+	for _, room := range rooms {
+		grid.carveRoom(room.Rectangle)
+	}
+	connectRooms(grid, rooms, rng)
+
+	// Pockets the corridors missed are filled in
+	x, y := rooms[0].X+rooms[0].Width/2, rooms[0].Y+rooms[0].Height/2
+	grid.keepReachable(x, y)
+
+	return rooms
+}
+
+// smoothCave runs one pass of the automaton: a tile turns to rock when
+// most of the 3x3 block around it is rock, and opens up otherwise.
+func smoothCave(grid *Grid) {
+	next := grid.Clone()
+	for x := 1; x < grid.Width-1; x++ {
+		for y := 1; y < grid.Height-1; y++ {
+			if rock := 9 - grid.countAdjacentWalkable(int32(x), int32(y)); rock >= 5 {
+				next.Set(x, y, 0)
+			} else {
+				next.Set(x, y, 1)
+			}
+		}
+	}
+	*grid = next
+}
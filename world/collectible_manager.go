@@ -1,23 +1,36 @@
 package world
 
 import (
+	"crydes/defs"
+	"crydes/events"
 	"crydes/helpers"
 	"math/rand"
+	"sort"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+const (
+	KEY_ID      = 999  // Id of the key of a floor
+	LOCK_KEY_ID = 1000 // Id of the first key to a locked door, the others follow
+	DROP_ID     = 2000 // Id of the first item dropped during play
+)
+
 type CollectibleManager struct {
-	items       map[int]*CollectibleItem
-	effectsChan chan ItemEffectEvent
-	playerPos   *rl.Vector2
+	items     map[int]*CollectibleItem
+	bus       *events.Bus
+	playerPos *rl.Vector2
+	headless  bool // Items load no textures
 }
 
-func NewCollectibleManager() *CollectibleManager {
+// NewCollectibleManager creates a manager whose items publish
+// ItemCollected on the bus when picked up.
+func NewCollectibleManager(bus *events.Bus, headless bool) *CollectibleManager {
 	return &CollectibleManager{
-		items:       make(map[int]*CollectibleItem),
-		effectsChan: make(chan ItemEffectEvent, 10), // Buffered channel
-		playerPos:   nil,
+		items:     make(map[int]*CollectibleItem),
+		bus:       bus,
+		playerPos: nil,
+		headless:  headless,
 	}
 }
 
@@ -26,13 +39,25 @@ func (cm *CollectibleManager) SetPlayerPosition(plPos *rl.Vector2) {
 }
 
 func (cm *CollectibleManager) AddItem(id int, itemType ItemType, x, y float32) {
-	animation := LoadItemAnimation(itemType)
-	item := NewCollectibleItem(id, itemType, x, y, animation, cm.effectsChan)
+	animation := LoadItemAnimation(itemType, cm.headless)
+	if animation == nil {
+		helpers.DEBUG("Undefined item type", itemType)
+		return
+	}
+	item := NewCollectibleItem(id, itemType, x, y, animation, cm.bus)
 	cm.items[id] = item
 }
 
 func (cm *CollectibleManager) Update(refreshRate float32) {
-	for _, item := range cm.items {
+	// Walk items in id order so pickups on the same tick always resolve alike
+	ids := make([]int, 0, len(cm.items))
+	for id := range cm.items {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		item := cm.items[id]
 		item.SetPlayerPosition(cm.playerPos)
 		item.Update(refreshRate)
 		// fmt.Println(cm.playerPos, item.Position)
@@ -49,15 +74,12 @@ func (cm *CollectibleManager) UpdatePlayerPosition(pos *rl.Vector2) {
 	cm.playerPos = pos
 }
 
-func (cm *CollectibleManager) GetEffectsChan() chan ItemEffectEvent {
-	return cm.effectsChan
-}
-
 func (cm *CollectibleManager) ScatterCollectibles(rooms []helpers.Rectangle, mp *Map) {
 	// Clear existing items
 	cm.items = make(map[int]*CollectibleItem)
 
 	var itemID int = 1
+	rng := mp.Rand()
 
 	for i, room := range rooms {
 		// Skip the first room (starting room)
@@ -72,11 +94,11 @@ func (cm *CollectibleManager) ScatterCollectibles(rooms []helpers.Rectangle, mp
 		}
 
 		// Calculate number of items based on room size
-		numItems := calculateItemsForRoom(actualRoom.Size)
+		numItems := calculateItemsForRoom(actualRoom.Size, rng)
 
 		for j := 0; j < numItems; j++ {
-			pos := room.GetRandomPosInRect()
-			itemType := getRandomItemType()
+			pos := room.GetRandomPosInRect(rng)
+			itemType := getRandomItemType(rng, mp.Depth())
 
 			cm.AddItem(itemID, itemType, pos.X, pos.Y)
 			itemID++
@@ -86,28 +108,58 @@ func (cm *CollectibleManager) ScatterCollectibles(rooms []helpers.Rectangle, mp
 	// set up key
 	lastRoom := rooms[len(rooms)-1]
 	// destX, destY := g.GetLastRoomPos()
-	cm.AddItem(999, Key, lastRoom.GetRandomPosInRect().X, lastRoom.GetRandomPosInRect().Y)
+	keyPos := lastRoom.GetRandomPosInRect(rng)
+	cm.AddItem(KEY_ID, Key, keyPos.X, keyPos.Y)
+
+	// And the keys to the locked doors, in the rooms the layout left them
+	for i, key := range mp.LockKeys() {
+		pos := rooms[key.Room].GetRandomPosInRect(rng)
+		cm.AddItem(LOCK_KEY_ID+i, key.Lock.Key(), pos.X, pos.Y)
+	}
 }
 
-func calculateItemsForRoom(size RoomSize) int {
+// Drop adds an item during play, e.g. a coin dropped by a killed enemy. It
+// gets the next id free past DROP_ID.
+func (cm *CollectibleManager) Drop(itemType ItemType, x, y float32) {
+	id := DROP_ID
+	for existing := range cm.items {
+		if existing >= id {
+			id = existing + 1
+		}
+	}
+	cm.AddItem(id, itemType, x, y)
+}
+
+// Items returns the items left on the floor in id order.
+func (cm *CollectibleManager) Items() []*CollectibleItem {
+	ids := make([]int, 0, len(cm.items))
+	for id := range cm.items {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	items := make([]*CollectibleItem, len(ids))
+	for i, id := range ids {
+		items[i] = cm.items[id]
+	}
+	return items
+}
+
+func calculateItemsForRoom(size RoomSize, rng *rand.Rand) int {
 	switch size {
 	case SmallRoom:
-		return 1 + rand.Intn(2) // 0-1 items
+		return 1 + rng.Intn(2) // 0-1 items
 	case MediumRoom:
-		return 1 + rand.Intn(2) // 1-2 items
+		return 1 + rng.Intn(2) // 1-2 items
 	case LargeRoom:
-		return 2 + rand.Intn(2) // 2-3 items
+		return 2 + rng.Intn(2) // 2-3 items
 	default:
 		return 1
 	}
 }
 
-func getRandomItemType() ItemType {
-	types := []ItemType{
-		HealthPotion,
-		SpeedPotion,
-		Poison,
-		// Add more item types here as needed
-	}
-	return types[rand.Intn(len(types))]
+// getRandomItemType picks an item by its spawn weight on the floor at
+// depth, deeper floors hold fewer potions.
+func getRandomItemType(rng *rand.Rand, depth int) ItemType {
+	return ItemType(defs.Get().RandomItem(rng, depth).Name)
 }
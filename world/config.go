@@ -0,0 +1,15 @@
+package world
+
+import "crydes/helpers"
+
+// Config is how the dungeons of a run are laid out, it holds for every
+// floor and shift of the run.
+type Config struct {
+	Generator string // Forced on every layout, empty lets each floor pick one
+	Width     int    // Tiles across the top floor, deeper floors grow from it
+	Height    int    // Tiles down the top floor
+}
+
+// DEFAULT_CONFIG lays out runs the way the game does when started without
+// options.
+var DEFAULT_CONFIG = Config{Width: helpers.MAP_WIDTH, Height: helpers.MAP_HEIGHT}
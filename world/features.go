@@ -0,0 +1,178 @@
+package world
+
+import "math/rand"
+
+const (
+	DOOR_ODDS         = 0.5  // Odds a narrow doorway into a room is given a door
+	DOORWAY_MAX_WIDTH = 5    // Widest opening that gets narrowed down to a door
+	WATER_ODDS        = 0.25 // Odds a room holds a pool
+	PIT_ODDS          = 0.2  // Odds a room has pits in its floor
+	TRAP_ODDS         = 0.2  // Odds a room is crossed by a row of spike traps
+	PLATE_ODDS        = 0.5  // Odds a trapped room hides a pressure plate
+)
+
+// placeFeatures covers the floor of a finished layout with doors, water,
+// pits and traps. The middle of every room, where the player arrives and
+// stairs stand, is kept free of hazards and nothing placed ever cuts a
+// room off from the others.
+func placeFeatures(grid *Grid, rooms []*Room, rng *rand.Rand) {
+	for _, room := range rooms {
+		placeDoors(grid, rooms, room, DOOR_ODDS, rng)
+	}
+
+	for _, room := range rooms {
+		if rng.Float32() < WATER_ODDS {
+			placePool(grid, room, rng)
+		}
+		if rng.Float32() < PIT_ODDS {
+			placePits(grid, rooms, room, rng)
+		}
+		if rng.Float32() < TRAP_ODDS {
+			placeSpikes(grid, room, rng)
+		}
+	}
+}
+
+// placeDoors narrows the openings along the walls of the room into doors,
+// each with the given odds.
+func placeDoors(grid *Grid, rooms []*Room, room *Room, odds float32, rng *rand.Rand) {
+	x0, y0 := int(room.X)-1, int(room.Y)-1
+	x1, y1 := int(room.X+room.Width), int(room.Y+room.Height)
+
+	sides := [][]Point{{}, {}, {}, {}}
+	for x := x0 + 1; x < x1; x++ {
+		sides[0] = append(sides[0], Point{X: x, Y: y0})
+		sides[1] = append(sides[1], Point{X: x, Y: y1})
+	}
+	for y := y0 + 1; y < y1; y++ {
+		sides[2] = append(sides[2], Point{X: x0, Y: y})
+		sides[3] = append(sides[3], Point{X: x1, Y: y})
+	}
+
+	// Top and bottom sides run along x, left and right ones along y
+	along := [][2]int{{1, 0}, {1, 0}, {0, 1}, {0, 1}}
+	for i, side := range sides {
+		for start := 0; start < len(side); {
+			if grid.At(side[start].X, side[start].Y) != TileFloor {
+				start++
+				continue
+			}
+			end := start
+			for end < len(side) && grid.At(side[end].X, side[end].Y) == TileFloor {
+				end++
+			}
+
+			if end-start <= DOORWAY_MAX_WIDTH && rng.Float32() < odds {
+				placeDoor(grid, rooms, side[start:end], along[i])
+			}
+			start = end
+		}
+	}
+}
+
+// placeDoor walls off an opening running along the given direction but for
+// a door in its middle. It leaves the opening as it was unless the door
+// ends up between two walls, leading somewhere on both sides, without
+// cutting rooms off.
+func placeDoor(grid *Grid, rooms []*Room, opening []Point, along [2]int) {
+	door := opening[len(opening)/2]
+	for _, tile := range opening {
+		grid.Set(tile.X, tile.Y, TileWall)
+	}
+	grid.Set(door.X, door.Y, TileDoorClosed)
+
+	walled := grid.At(door.X-along[0], door.Y-along[1]) == TileWall && grid.At(door.X+along[0], door.Y+along[1]) == TileWall
+	leads := grid.At(door.X-along[1], door.Y-along[0]) != TileWall && grid.At(door.X+along[1], door.Y+along[0]) != TileWall
+	if !walled || !leads || !roomsConnected(grid, rooms) {
+		for _, tile := range opening {
+			grid.Set(tile.X, tile.Y, TileFloor)
+		}
+	}
+}
+
+// placePool floods a small round pool somewhere in the room.
+func placePool(grid *Grid, room *Room, rng *rand.Rand) {
+	cx, cy := randomTileIn(room, rng)
+	radius := 1 + rng.Intn(2)
+	for x := cx - radius; x <= cx+radius; x++ {
+		for y := cy - radius; y <= cy+radius; y++ {
+			dx, dy := x-cx, y-cy
+			if dx*dx+dy*dy <= radius*radius && inside(room, x, y) && grid.At(x, y) == TileFloor {
+				grid.Set(x, y, TileWater)
+			}
+		}
+	}
+}
+
+// placePits opens a few pits in the room, dropping any that would cut rooms
+// off.
+func placePits(grid *Grid, rooms []*Room, room *Room, rng *rand.Rand) {
+	count := 1 + rng.Intn(3)
+	for i := 0; i < count; i++ {
+		x, y := randomTileIn(room, rng)
+		if grid.At(x, y) != TileFloor || isCenter(room, x, y) {
+			continue
+		}
+		grid.Set(x, y, TilePit)
+		if !roomsConnected(grid, rooms) {
+			grid.Set(x, y, TileFloor)
+		}
+	}
+}
+
+// placeSpikes lays a row or column of spike traps across the room, with a
+// pressure plate hidden somewhere else in it now and then.
+func placeSpikes(grid *Grid, room *Room, rng *rand.Rand) {
+	if rng.Intn(2) == 0 {
+		y := int(room.Y) + rng.Intn(int(room.Height))
+		for x := int(room.X); x < int(room.X+room.Width); x++ {
+			if grid.At(x, y) == TileFloor && !isCenter(room, x, y) {
+				grid.Set(x, y, TileSpikes)
+			}
+		}
+	} else {
+		x := int(room.X) + rng.Intn(int(room.Width))
+		for y := int(room.Y); y < int(room.Y+room.Height); y++ {
+			if grid.At(x, y) == TileFloor && !isCenter(room, x, y) {
+				grid.Set(x, y, TileSpikes)
+			}
+		}
+	}
+
+	if rng.Float32() < PLATE_ODDS {
+		x, y := randomTileIn(room, rng)
+		if grid.At(x, y) == TileFloor && !isCenter(room, x, y) {
+			grid.Set(x, y, TilePlate)
+		}
+	}
+}
+
+// roomsConnected reports whether every room can be reached from the first
+// once the locked doors are open.
+func roomsConnected(grid *Grid, rooms []*Room) bool {
+	if len(rooms) == 0 {
+		return false
+	}
+	first := rooms[0]
+	reach := grid.unlocked(first.X+first.Width/2, first.Y+first.Height/2)
+	for _, room := range rooms {
+		if !roomReached(&reach, room) {
+			return false
+		}
+	}
+	return true
+}
+
+func randomTileIn(room *Room, rng *rand.Rand) (int, int) {
+	return int(room.X) + rng.Intn(int(room.Width)), int(room.Y) + rng.Intn(int(room.Height))
+}
+
+func inside(room *Room, x, y int) bool {
+	return x >= int(room.X) && x < int(room.X+room.Width) && y >= int(room.Y) && y < int(room.Y+room.Height)
+}
+
+// isCenter reports whether (x, y) is the middle tile of the room, where
+// the player spawns and stairs are placed.
+func isCenter(room *Room, x, y int) bool {
+	return x == int(room.X+room.Width/2) && y == int(room.Y+room.Height/2)
+}
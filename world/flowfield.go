@@ -0,0 +1,144 @@
+package world
+
+import (
+	"crydes/helpers"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const unreachable = -1
+
+// FlowField stores the walking distance from every tile to a target tile,
+// so any number of enemies can head for the player for the cost of a
+// single breadth-first search.
+type FlowField struct {
+	mp    *Map
+	dist  []int32 // Indexed x*height + y
+	queue []int32 // Reused between rebuilds
+
+	width, height int // Size of the map the field was built for
+
+	target  rl.Vector2 // Exact position the field leads to
+	targetX int
+	targetY int
+
+	timer float32
+	valid bool
+}
+
+func NewFlowField(m *Map) *FlowField {
+	return &FlowField{mp: m}
+}
+
+// Update rebuilds the field when the target moved to another tile, at most
+// once every ENEMIES_REPATH_INTERVAL seconds.
+func (ff *FlowField) Update(deltaTime float32, target rl.Vector2) {
+	ff.timer += deltaTime
+	ff.target = target
+
+	tx, ty := int(target.X)/helpers.TILE_SIZE, int(target.Y)/helpers.TILE_SIZE
+	if ff.valid && (ff.timer < helpers.ENEMIES_REPATH_INTERVAL || (tx == ff.targetX && ty == ff.targetY)) {
+		return
+	}
+
+	ff.timer = 0
+	ff.build(tx, ty)
+}
+
+// Invalidate forces a rebuild on the next update, after the map changed.
+func (ff *FlowField) Invalidate() {
+	ff.valid = false
+}
+
+func (ff *FlowField) build(tx, ty int) {
+	ff.targetX, ff.targetY = tx, ty
+	ff.valid = true
+
+	// The map may have changed size since the last build
+	if ff.width != ff.mp.Width() || ff.height != ff.mp.Height() {
+		ff.width, ff.height = ff.mp.Width(), ff.mp.Height()
+		ff.dist = make([]int32, ff.width*ff.height)
+	}
+	for i := range ff.dist {
+		ff.dist[i] = unreachable
+	}
+
+	if !ff.mp.IsWalkable(tx, ty) {
+		return
+	}
+
+	ff.dist[tx*ff.height+ty] = 0
+	ff.queue = append(ff.queue[:0], int32(tx*ff.height+ty))
+
+	for head := 0; head < len(ff.queue); head++ {
+		x, y := int(ff.queue[head])/ff.height, int(ff.queue[head])%ff.height
+		d := ff.dist[x*ff.height+y]
+		if d >= helpers.ENEMIES_CHASE_TILES {
+			continue
+		}
+
+		for _, dir := range [4][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			nx, ny := x+dir[0], y+dir[1]
+			next := nx*ff.height + ny
+			if !ff.safe(nx, ny) || ff.dist[next] != unreachable {
+				continue
+			}
+			ff.dist[next] = d + 1
+			ff.queue = append(ff.queue, int32(next))
+		}
+	}
+}
+
+// safe reports whether enemies may walk through the tile, they keep out of
+// pits and spike traps.
+func (ff *FlowField) safe(x, y int) bool {
+	return ff.mp.IsWalkable(x, y) && !ff.mp.TileAt(x, y).Hazard()
+}
+
+// Distance returns how many tiles away from the target a tile is, or -1
+// when it can't reach it within ENEMIES_CHASE_TILES.
+func (ff *FlowField) Distance(x, y int) int {
+	if !ff.valid || x < 0 || x >= ff.width || y < 0 || y >= ff.height {
+		return unreachable
+	}
+	return int(ff.dist[x*ff.height+y])
+}
+
+// NextStep returns the point to walk towards from a position: the center
+// of the neighbouring tile closest to the target, or the target itself once
+// on its tile. Diagonals are only taken when both sides are open so
+// movers never clip wall corners. It reports false when the target can't
+// be reached from there.
+func (ff *FlowField) NextStep(from rl.Vector2) (rl.Vector2, bool) {
+	x, y := int(from.X)/helpers.TILE_SIZE, int(from.Y)/helpers.TILE_SIZE
+
+	best := ff.Distance(x, y)
+	if best == unreachable {
+		return from, false
+	}
+	if best == 0 {
+		return ff.target, true
+	}
+
+	bestX, bestY := x, y
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			if dx != 0 && dy != 0 && (!ff.mp.IsWalkable(x+dx, y) || !ff.mp.IsWalkable(x, y+dy)) {
+				continue
+			}
+
+			d := ff.Distance(x+dx, y+dy)
+			if d != unreachable && d < best {
+				best, bestX, bestY = d, x+dx, y+dy
+			}
+		}
+	}
+
+	return rl.Vector2{
+		X: float32(bestX*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+		Y: float32(bestY*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+	}, true
+}
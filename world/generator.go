@@ -0,0 +1,162 @@
+package world
+
+import (
+	"crydes/helpers"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Generator lays out a dungeon. It carves walkable tiles into a grid full
+// of walls and returns the rooms it made, at least 3 of them. Props, loot
+// and enemies are placed from the rooms, the last one holds the key.
+type Generator interface {
+	Generate(grid *Grid, rng *rand.Rand) []*Room
+}
+
+// GENERATORS are the generators a layout can be made with, by name.
+var GENERATORS = map[string]Generator{
+	"bsp":     BSPGenerator{},
+	"caves":   CaveGenerator{},
+	"tunnels": TunnelGenerator{},
+	"prefabs": PrefabGenerator{},
+}
+
+// GENERATOR_ORDER is the order generators are picked in, so a seed always
+// picks the same one.
+var GENERATOR_ORDER = []string{"bsp", "caves", "tunnels", "prefabs"}
+
+// pickGenerator returns the name of the generator for a new layout of the
+// floor at depth. Unless the config forces one, the top floor is always
+// BSP and deeper floors pick one of GENERATOR_ORDER on every shift.
+func pickGenerator(config Config, depth int, rng *rand.Rand) string {
+	if config.Generator != "" {
+		return config.Generator
+	}
+	if depth <= 1 {
+		return "bsp"
+	}
+	return GENERATOR_ORDER[rng.Intn(len(GENERATOR_ORDER))]
+}
+
+// roomSizeFor sizes a room the way BSP rooms are sized, from its area in
+// tiles.
+func roomSizeFor(width, height int32) RoomSize {
+	switch area := width * height; {
+	case area <= 8*8:
+		return SmallRoom
+	case area <= 11*11:
+		return MediumRoom
+	default:
+		return LargeRoom
+	}
+}
+
+// overlapsAny reports whether rect, grown by margin tiles on every side,
+// overlaps one of the rooms.
+func overlapsAny(rooms []*Room, rect helpers.Rectangle, margin int32) bool {
+	grown := helpers.Rectangle{X: rect.X - margin, Y: rect.Y - margin, Width: rect.Width + margin*2, Height: rect.Height + margin*2}
+	for _, room := range rooms {
+		if room.Intersects(grown) {
+			return true
+		}
+	}
+	return false
+}
+
+// connectRooms links every room with corridors along a minimum spanning
+// tree of their distances, plus a few extra corridors for loops.
+func connectRooms(grid *Grid, rooms []*Room, rng *rand.Rand) {
+	numRooms := len(rooms)
+	if numRooms == 0 {
+		return
+	}
+
+	// Create a list of all possible connections
+	type connection struct {
+		room1, room2 int
+		distance     float64
+	}
+
+	var connections []connection
+
+	// Generate all possible connections between rooms
+	for i := 0; i < numRooms; i++ {
+		for j := i + 1; j < numRooms; j++ {
+			dist := distanceBetweenRooms(rooms[i], rooms[j])
+			connections = append(connections, connection{i, j, dist})
+		}
+	}
+
+	// Sort connections by distance
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].distance < connections[j].distance
+	})
+
+	// Union-Find data structure for detecting cycles
+	parent := make([]int, numRooms)
+	for i := range parent {
+		parent[i] = i
+	}
+
+	// Find with path compression
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+
+	// Union by rank
+	union := func(x, y int) {
+		parent[find(x)] = find(y)
+	}
+
+	// Create minimum spanning tree
+	connected := make(map[int]map[int]bool)
+	for i := 0; i < numRooms; i++ {
+		connected[i] = make(map[int]bool)
+	}
+
+	// Connect rooms using Kruskal's algorithm
+	for _, conn := range connections {
+		if find(conn.room1) != find(conn.room2) {
+			union(conn.room1, conn.room2)
+			connected[conn.room1][conn.room2] = true
+			connected[conn.room2][conn.room1] = true
+			grid.createCorridor(rooms[conn.room1], rooms[conn.room2], rng)
+		}
+	}
+
+	// Add a few extra connections for loops (optional)
+	for _, conn := range connections {
+		if !connected[conn.room1][conn.room2] && rng.Float64() < 0.2 { // 20% chance for extra connections
+			grid.createCorridor(rooms[conn.room1], rooms[conn.room2], rng)
+			connected[conn.room1][conn.room2] = true
+			connected[conn.room2][conn.room1] = true
+		}
+	}
+}
+
+func distanceBetweenRooms(r1, r2 *Room) float64 {
+	c1x := float64(r1.X) + float64(r1.Width)/2
+	c1y := float64(r1.Y) + float64(r1.Height)/2
+	c2x := float64(r2.X) + float64(r2.Width)/2
+	c2y := float64(r2.Y) + float64(r2.Height)/2
+
+	dx := c1x - c2x
+	dy := c1y - c2y
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+// randomRect places a width by height rectangle anywhere on the grid,
+// keeping off the outer wall.
+func randomRect(grid *Grid, rng *rand.Rand, width, height int32) helpers.Rectangle {
+	return helpers.Rectangle{
+		X:      2 + int32(rng.Intn(grid.Width-int(width)-4)),
+		Y:      2 + int32(rng.Intn(grid.Height-int(height)-4)),
+		Width:  width,
+		Height: height,
+	}
+}
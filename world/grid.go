@@ -0,0 +1,209 @@
+package world
+
+import (
+	"crydes/helpers"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Grid holds the tiles of a dungeon. Its size is picked when a layout is
+// generated.
+type Grid struct {
+	Width, Height int
+	tiles         []Tile // Indexed x*Height + y
+}
+
+// NewGrid returns a width by height grid full of walls.
+func NewGrid(width, height int) Grid {
+	return Grid{Width: width, Height: height, tiles: make([]Tile, width*height)}
+}
+
+// InBounds reports whether (x, y) is a tile of the grid.
+func (g *Grid) InBounds(x, y int) bool {
+	return x >= 0 && x < g.Width && y >= 0 && y < g.Height
+}
+
+// At returns the tile at (x, y), tiles out of the grid are walls.
+func (g *Grid) At(x, y int) Tile {
+	if !g.InBounds(x, y) {
+		return 0
+	}
+	return g.tiles[x*g.Height+y]
+}
+
+// Set changes the tile at (x, y), tiles out of the grid are left alone.
+func (g *Grid) Set(x, y int, tile Tile) {
+	if g.InBounds(x, y) {
+		g.tiles[x*g.Height+y] = tile
+	}
+}
+
+// Clone returns a copy of the grid that doesn't share its tiles.
+func (g *Grid) Clone() Grid {
+	return Grid{Width: g.Width, Height: g.Height, tiles: append([]Tile(nil), g.tiles...)}
+}
+
+// carveRoom opens every tile of the room.
+func (g *Grid) carveRoom(room helpers.Rectangle) {
+	for x := room.X; x < room.X+room.Width; x++ {
+		for y := room.Y; y < room.Y+room.Height; y++ {
+			g.Set(int(x), int(y), 1)
+		}
+	}
+}
+
+// createCorridor carves a winding corridor between the centers of two rooms.
+func (g *Grid) createCorridor(room1, room2 *Room, rng *rand.Rand) {
+	// Get room centers
+	start := rl.Vector2{
+		X: float32(room1.X + room1.Width/2),
+		Y: float32(room1.Y + room1.Height/2),
+	}
+	end := rl.Vector2{
+		X: float32(room2.X + room2.Width/2),
+		Y: float32(room2.Y + room2.Height/2),
+	}
+
+	// Create 2-3 control points for more organic paths
+	numPoints := rng.Intn(2) + 2
+	controlPoints := make([]rl.Vector2, numPoints)
+	controlPoints[0] = start
+	controlPoints[numPoints-1] = end
+
+	// Generate intermediate control points
+	for i := 1; i < numPoints-1; i++ {
+		controlPoints[i] = rl.Vector2{
+			X: start.X + (end.X-start.X)*float32(i)/float32(numPoints-1) + float32(rng.Intn(5)-2),
+			Y: start.Y + (end.Y-start.Y)*float32(i)/float32(numPoints-1) + float32(rng.Intn(5)-2),
+		}
+	}
+
+	// Carve paths through all control points
+	for i := 0; i < len(controlPoints)-1; i++ {
+		g.carvePath(controlPoints[i], controlPoints[i+1], rng)
+	}
+}
+
+func (g *Grid) carvePath(start, end rl.Vector2, rng *rand.Rand) {
+	x := int32(start.X)
+	y := int32(start.Y)
+
+	// Make the corridor wider at the start
+	g.carveArea(x, y, 3)
+
+	for x != int32(end.X) || y != int32(end.Y) {
+		if x < int32(end.X) {
+			x++
+		} else if x > int32(end.X) {
+			x--
+		}
+
+		if y < int32(end.Y) {
+			y++
+		} else if y > int32(end.Y) {
+			y--
+		}
+
+		// Always carve a wider path (minimum width)
+		g.carveArea(x, y, 2)
+
+		// Randomly make even wider corridors at some points
+		if rng.Float32() < 0.3 {
+			g.carveArea(x, y, 3)
+		}
+	}
+
+	// Make the corridor wider at the end
+	g.carveArea(int32(end.X), int32(end.Y), 3)
+}
+
+func (g *Grid) carveArea(x, y int32, radius int32) {
+	// Cap the radius to a maximum value (e.g., 3)
+	maxRadius := int32(2)
+	if radius > maxRadius {
+		radius = maxRadius
+	}
+
+	// First pass: carve the main area
+	for dx := -radius; dx <= radius; dx++ {
+		for dy := -radius; dy <= radius; dy++ {
+			newX := x + dx
+			newY := y + dy
+			if g.isValidPosition(newX, newY) {
+				g.Set(int(newX), int(newY), 1)
+			}
+		}
+	}
+
+	// Second pass: smooth out corners to prevent 1-tile gaps
+	for dx := -radius - 1; dx <= radius+1; dx++ {
+		for dy := -radius - 1; dy <= radius+1; dy++ {
+			newX := x + dx
+			newY := y + dy
+			if g.isValidPosition(newX, newY) {
+				// If surrounded by walkable tiles, make this tile walkable too
+				if g.countAdjacentWalkable(newX, newY) >= 5 {
+					g.Set(int(newX), int(newY), 1)
+				}
+			}
+		}
+	}
+}
+
+func (g *Grid) countAdjacentWalkable(x, y int32) int {
+	count := 0
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			newX := x + int32(dx)
+			newY := y + int32(dy)
+			if g.isValidPosition(newX, newY) && g.At(int(newX), int(newY)) == 1 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (g *Grid) isValidPosition(x, y int32) bool {
+	return x > 0 && int(x) < g.Width-1 && y > 0 && int(y) < g.Height-1
+}
+
+// keepReachable walls off every walkable tile that can't be walked to from
+// (x, y), so a layout never has pockets the player can't reach.
+func (g *Grid) keepReachable(x, y int32) {
+	*g = g.reachable(x, y)
+}
+
+// reachable returns a grid where only the tiles the player can get to from
+// (x, y) are floor.
+func (g *Grid) reachable(x, y int32) Grid {
+	return g.flood(x, y, Tile.Passable)
+}
+
+// unlocked returns a grid where only the tiles the player can get to from
+// (x, y) once every locked door is open are floor.
+func (g *Grid) unlocked(x, y int32) Grid {
+	return g.flood(x, y, func(t Tile) bool {
+		return t.Passable() || t == TileDoorLocked
+	})
+}
+
+// flood returns a grid where only the tiles connected to (x, y) through
+// tiles that pass are floor.
+func (g *Grid) flood(x, y int32, pass func(Tile) bool) Grid {
+	reached := NewGrid(g.Width, g.Height)
+	stack := [][2]int32{{x, y}}
+	for len(stack) > 0 {
+		tile := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		tx, ty := tile[0], tile[1]
+		if !g.isValidPosition(tx, ty) || !pass(g.At(int(tx), int(ty))) || reached.At(int(tx), int(ty)) == 1 {
+			continue
+		}
+		reached.Set(int(tx), int(ty), 1)
+		stack = append(stack, [2]int32{tx + 1, ty}, [2]int32{tx - 1, ty}, [2]int32{tx, ty + 1}, [2]int32{tx, ty - 1})
+	}
+	return reached
+}
@@ -1,6 +1,8 @@
 package world
 
 import (
+	"crydes/defs"
+	"crydes/events"
 	"crydes/helpers"
 	"time"
 
@@ -16,6 +18,12 @@ const (
 	Key          ItemType = "key"
 	Coin         ItemType = "coin"
 	Poison       ItemType = "poison"
+
+	// Keys to locked doors, see LOCK_KEYS
+	SmallKey ItemType = "small_key"
+	RedKey   ItemType = "red_key"
+	BlueKey  ItemType = "blue_key"
+	GreenKey ItemType = "green_key"
 )
 
 // ItemEffect represents the effect an item has when collected
@@ -25,43 +33,35 @@ type ItemEffect struct {
 	Duration time.Duration // Duration of the effect in seconds (0 for instant effects)
 }
 
-// ItemEffectEvent represents the event triggered by collecting an item
-type ItemEffectEvent struct {
-	SourceID int         // ID of the item that caused the effect
-	Effect   *ItemEffect // The effect to apply
-}
-
 // CollectibleItem extends the base Prop type with item-specific properties
 type CollectibleItem struct {
-	*Prop                              // Embed the base Prop type
-	ItemType    ItemType               // Type of item
-	Effect      *ItemEffect            // Effect when collected
-	Collected   bool                   // Whether the item has been collected
-	HoverOffset float32                // Offset for hover animation
-	HoverSpeed  float32                // Speed of hover animation
-	Time        float32                // Time tracker for animations
-	EffectsChan chan<- ItemEffectEvent // Add this field
+	*Prop                   // Embed the base Prop type
+	ItemType    ItemType    // Type of item
+	Effect      *ItemEffect // Effect when collected
+	Collected   bool        // Whether the item has been collected
+	Stored      bool        // Kept in the hotbar instead of applied on pickup
+	HoverOffset float32     // Offset for hover animation
+	HoverSpeed  float32     // Speed of hover animation
+	Time        float32     // Time tracker for animations
+	bus         *events.Bus // Where the pickup is published
 	playerPos   *rl.Vector2
 }
 
-// NewCollectibleItem creates a new collectible item
-func NewCollectibleItem(id int, itemType ItemType, x, y float32, animation *helpers.Animation, effectsChan chan<- ItemEffectEvent) *CollectibleItem {
+// NewCollectibleItem creates a new collectible item, its effect comes from
+// the item's definition.
+func NewCollectibleItem(id int, itemType ItemType, x, y float32, animation *helpers.Animation, bus *events.Bus) *CollectibleItem {
 	var effect *ItemEffect
+	stored := false
 	scale := float32(1.0)
 	size := rl.NewVector2(16, 16)
 
-	// Configure effect based on item type
-	switch itemType {
-	case HealthPotion:
-		effect = &ItemEffect{Type: "heal", Value: 2, Duration: 0}
-	case SpeedPotion:
-		effect = &ItemEffect{Type: "speed", Value: 2, Duration: time.Second * 30}
-	case Poison:
-		effect = &ItemEffect{Type: "poison", Value: 2, Duration: time.Second * 1}
-	case Key:
-		effect = &ItemEffect{Type: "key", Value: 1, Duration: 0}
-	case Coin:
-		effect = &ItemEffect{Type: "coin", Value: 1, Duration: 0}
+	if d, exists := defs.Get().Items[string(itemType)]; exists {
+		effect = &ItemEffect{
+			Type:     d.Effect.Type,
+			Value:    d.Effect.Value,
+			Duration: time.Duration(d.Effect.Duration * float32(time.Second)),
+		}
+		stored = d.Stored
 	}
 
 	baseProp := NewProp(
@@ -74,6 +74,9 @@ func NewCollectibleItem(id int, itemType ItemType, x, y float32, animation *help
 		animation,
 		true,
 	)
+	if lock, isKey := LockOf(itemType); isKey {
+		baseProp.Color = lock.Color()
+	}
 
 	return &CollectibleItem{
 		Prop:        baseProp,
@@ -82,7 +85,8 @@ func NewCollectibleItem(id int, itemType ItemType, x, y float32, animation *help
 		HoverOffset: 0,
 		HoverSpeed:  4.0,
 		Collected:   false,
-		EffectsChan: effectsChan,
+		Stored:      stored,
+		bus:         bus,
 		playerPos:   &rl.Vector2{},
 	}
 }
@@ -144,54 +148,40 @@ func (ci *CollectibleItem) Collect() {
 	}
 	ci.Collected = true
 
-	// Send the effect through the channel
-	if ci.Effect != nil && ci.EffectsChan != nil {
-		ci.EffectsChan <- ItemEffectEvent{
-			SourceID: ci.ID,
-			Effect:   ci.Effect,
-		}
+	// Publish the pickup, the player applies the effect
+	if ci.Effect != nil {
+		ci.bus.Publish(events.ItemCollected{
+			ItemID:   ci.ID,
+			Item:     string(ci.ItemType),
+			Effect:   ci.Effect.Type,
+			Value:    ci.Effect.Value,
+			Duration: ci.Effect.Duration,
+			Stored:   ci.Stored,
+		})
+	}
+}
+
+// ItemEvent returns what picking up an item of the given type publishes,
+// for items handed over without lying on the floor.
+func ItemEvent(id int, itemType ItemType) events.ItemCollected {
+	item := events.ItemCollected{ItemID: id, Item: string(itemType)}
+	if d, exists := defs.Get().Items[string(itemType)]; exists {
+		item.Effect = d.Effect.Type
+		item.Value = d.Effect.Value
+		item.Duration = time.Duration(d.Effect.Duration * float32(time.Second))
+		item.Stored = d.Stored
 	}
+	return item
 }
 
-// LoadItemAnimation loads the appropriate animation for an item type
-func LoadItemAnimation(itemType ItemType) *helpers.Animation {
-	switch itemType {
-	case HealthPotion:
-		return helpers.LoadAnimation("health_potion",
-			"assets/health_potion/1.png",
-			"assets/health_potion/2.png",
-			"assets/health_potion/3.png",
-			"assets/health_potion/4.png",
-		)
-	case SpeedPotion:
-		return helpers.LoadAnimation("speed_potion",
-			"assets/speed_potion/9.png",
-			"assets/speed_potion/10.png",
-			"assets/speed_potion/11.png",
-			"assets/speed_potion/12.png",
-		)
-	case Key:
-		return helpers.LoadAnimation("key",
-			"assets/key/1.png",
-			"assets/key/2.png",
-			"assets/key/3.png",
-		)
-	case Poison:
-		return helpers.LoadAnimation("key",
-			"assets/speed_potion/9.png",
-			"assets/speed_potion/10.png",
-			"assets/speed_potion/11.png",
-			"assets/speed_potion/12.png",
-		)
-	case Coin:
-		return helpers.LoadAnimation("coin",
-			"assets/items/coin/1.png",
-			"assets/items/coin/2.png",
-			"assets/items/coin/3.png",
-		)
-	default:
+// LoadItemAnimation loads the animation of an item type, nil when the type
+// isn't defined
+func LoadItemAnimation(itemType ItemType, headless bool) *helpers.Animation {
+	d, exists := defs.Get().Items[string(itemType)]
+	if !exists {
 		return nil
 	}
+	return d.Animation.Load(string(itemType), headless)
 }
 
 // Add method to update player position
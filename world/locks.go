@@ -0,0 +1,256 @@
+package world
+
+import (
+	"math/rand"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Lock is what it takes to open a locked door.
+type Lock int
+
+const (
+	LockPlain Lock = iota // Opens with any small key, which the door uses up
+	LockRed               // Colored locks open with the key of their color, which is kept
+	LockBlue
+	LockGreen
+)
+
+// MAX_LOCKS is the most locks a floor can have, there is never more than
+// one of each.
+const MAX_LOCKS = 3
+
+// LOCK_KEYS are the items that open each lock.
+var LOCK_KEYS = [...]ItemType{
+	LockPlain: SmallKey,
+	LockRed:   RedKey,
+	LockBlue:  BlueKey,
+	LockGreen: GreenKey,
+}
+
+// LockKey is a key the layout needs scattered, Room being the index of the
+// room it lies in.
+type LockKey struct {
+	Lock Lock
+	Room int
+}
+
+// Key returns the item that opens the lock.
+func (l Lock) Key() ItemType {
+	return LOCK_KEYS[l]
+}
+
+// Reusable reports whether the key to the lock opens every door of the
+// lock instead of being used up by the first one.
+func (l Lock) Reusable() bool {
+	return l != LockPlain
+}
+
+// Color is how the lock and its key are tinted.
+func (l Lock) Color() rl.Color {
+	switch l {
+	case LockRed:
+		return rl.Red
+	case LockBlue:
+		return rl.SkyBlue
+	case LockGreen:
+		return rl.Lime
+	}
+	return rl.LightGray
+}
+
+// LockOf returns the lock the item opens, it reports false for items that
+// aren't keys to a locked door.
+func LockOf(item ItemType) (Lock, bool) {
+	for lock, key := range LOCK_KEYS {
+		if key == item {
+			return Lock(lock), true
+		}
+	}
+	return 0, false
+}
+
+// LockAt returns the lock of the locked door at (x, y), it reports false
+// when there is none.
+func (m *Map) LockAt(x, y int) (Lock, bool) {
+	if m.dungeon.At(x, y) != TileDoorLocked {
+		return 0, false
+	}
+	return m.locks[Point{X: x, Y: y}], true
+}
+
+// Unlock opens the locked door at (x, y).
+func (m *Map) Unlock(x, y int) {
+	if m.dungeon.At(x, y) != TileDoorLocked {
+		return
+	}
+	m.dungeon.Set(x, y, TileDoorOpen)
+	delete(m.locks, Point{X: x, Y: y})
+}
+
+// Locks returns the doors still locked.
+func (m *Map) Locks() int {
+	return len(m.locks)
+}
+
+// LockKeys returns the keys the layout needs scattered, in the order the
+// doors were locked.
+func (m *Map) LockKeys() []LockKey {
+	return m.lockKeys
+}
+
+// Unlockable reports whether every locked door can be opened walking from
+// pos, picking up on the way the keys lying at keys.
+func (m *Map) Unlockable(pos rl.Vector2, keys map[Lock]rl.Vector2) bool {
+	grid := m.dungeon.Clone()
+	x, y := tileOf(pos)
+
+	for opened := true; opened; {
+		opened = false
+		reach := grid.reachable(int32(x), int32(y))
+		for door, lock := range m.locks {
+			key, exists := keys[lock]
+			if !exists || grid.At(door.X, door.Y) != TileDoorLocked || reach.At(tileOf(key)) != 1 {
+				continue
+			}
+			for _, dir := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				if reach.At(door.X+dir[0], door.Y+dir[1]) == 1 {
+					grid.Set(door.X, door.Y, TileDoorOpen)
+					opened = true
+					break
+				}
+			}
+		}
+	}
+
+	for door := range m.locks {
+		if grid.At(door.X, door.Y) == TileDoorLocked {
+			return false
+		}
+	}
+	return true
+}
+
+// placeLocks locks doors that seal rooms off from the first one, a single
+// door for a plain lock, or a room with a door fitted in every opening for
+// a colored one. The key to every lock is left in a room that can be
+// reached with all the doors locked so far shut, so the keys can always be
+// found in the reverse order the doors were locked in.
+func placeLocks(grid *Grid, rooms []*Room, rng *rand.Rand) (map[Point]Lock, []LockKey) {
+	locks := map[Point]Lock{}
+	var keys []LockKey
+	if len(rooms) < 3 {
+		return locks, keys
+	}
+
+	start := rooms[0]
+	sx, sy := start.X+start.Width/2, start.Y+start.Height/2
+	order := rng.Perm(len(LOCK_KEYS))[:1+rng.Intn(MAX_LOCKS)]
+
+	for _, l := range order {
+		lock := Lock(l)
+		reach := grid.reachable(sx, sy)
+
+		var gate []Point
+		var open []int
+		if lock.Reusable() {
+			for _, i := range rng.Perm(len(rooms) - 1) {
+				saved := grid.Clone()
+				placeDoors(grid, rooms, rooms[i+1], 1, rng)
+				gate = doorsAround(grid, rooms[i+1])
+				if open = sealGate(grid, rooms, &reach, gate); open != nil {
+					break
+				}
+				*grid = saved
+			}
+		} else {
+			var doors []Point
+			for x := 0; x < grid.Width; x++ {
+				for y := 0; y < grid.Height; y++ {
+					if grid.At(x, y) == TileDoorClosed && reach.At(x, y) == 1 {
+						doors = append(doors, Point{X: x, Y: y})
+					}
+				}
+			}
+			rng.Shuffle(len(doors), func(i, j int) { doors[i], doors[j] = doors[j], doors[i] })
+			for _, door := range doors {
+				gate = []Point{door}
+				if open = sealGate(grid, rooms, &reach, gate); open != nil {
+					break
+				}
+			}
+		}
+
+		if open == nil {
+			continue
+		}
+		for _, door := range gate {
+			locks[door] = lock
+		}
+		keys = append(keys, LockKey{Lock: lock, Room: open[rng.Intn(len(open))]})
+	}
+
+	return locks, keys
+}
+
+// sealGate locks the doors of the gate and returns the rooms other than the
+// first still reachable, where its key can be left. It leaves the doors
+// closed and returns nil unless they seal a room off and a room is left.
+func sealGate(grid *Grid, rooms []*Room, reach *Grid, gate []Point) []int {
+	if len(gate) == 0 {
+		return nil
+	}
+	for _, door := range gate {
+		grid.Set(door.X, door.Y, TileDoorLocked)
+	}
+
+	start := rooms[0]
+	sealed := grid.reachable(start.X+start.Width/2, start.Y+start.Height/2)
+	var open []int
+	cut := false
+	for i, room := range rooms {
+		switch {
+		case roomReached(&sealed, room):
+			if i != 0 {
+				open = append(open, i)
+			}
+		case roomReached(reach, room):
+			cut = true
+		}
+	}
+
+	if !cut || len(open) == 0 {
+		for _, door := range gate {
+			grid.Set(door.X, door.Y, TileDoorClosed)
+		}
+		return nil
+	}
+	return open
+}
+
+// doorsAround returns the closed doors on the ring of tiles around the room.
+func doorsAround(grid *Grid, room *Room) []Point {
+	var doors []Point
+	for x := int(room.X) - 1; x <= int(room.X+room.Width); x++ {
+		for y := int(room.Y) - 1; y <= int(room.Y+room.Height); y++ {
+			if !inside(room, x, y) && grid.At(x, y) == TileDoorClosed {
+				doors = append(doors, Point{X: x, Y: y})
+			}
+		}
+	}
+	return doors
+}
+
+// lockStates returns the locked doors sorted by position.
+func lockStates(locks map[Point]Lock) []LockState {
+	var states []LockState
+	for door, lock := range locks {
+		states = append(states, LockState{X: door.X, Y: door.Y, Lock: lock})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		a, b := states[i], states[j]
+		return a.X < b.X || (a.X == b.X && a.Y < b.Y)
+	})
+	return states
+}
@@ -1,10 +1,9 @@
 package world
 
 import (
-	"math"
 	"math/rand"
-	"sort"
 
+	"crydes/defs"
 	helpers "crydes/helpers"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
@@ -42,10 +41,28 @@ type Textures struct {
 
 // 0 means not walkable, 1 means walkable
 type Map struct {
-	dungeon [helpers.MAP_WIDTH][helpers.MAP_HEIGHT]int
+	dungeon Grid
 	rooms   []*Room
 	// corridors [][]rl.Vector2
 
+	config   Config // How every layout of the run is made
+	headless bool   // Loads no textures, for simulations without a window
+
+	seed   int64          // Seed the current layout was generated from
+	rng    *rand.Rand     // Private source for everything derived from the layout
+	source *countedSource // Under rng, counts what was drawn for saves
+	depth  int            // Floor of the dungeon, 1 being the top one
+
+	generator string // Name of the generator the layout was made with
+
+	trapClock float32           // Seconds the spike traps have been running
+	plates    map[Point]float32 // Pressed plates and the seconds they hold spikes up
+
+	locks    map[Point]Lock // Locked doors and what opens them
+	lockKeys []LockKey      // Keys to the locked doors, scattered with the items
+
+	shop *Shop // Merchant room, nil when the layout has none
+
 	Textures
 }
 
@@ -62,41 +79,67 @@ type Room struct {
 	Size RoomSize
 }
 
-func NewMap() *Map {
+// NewMap generates the top floor of a dungeon from seed, laid out as the
+// config says. A headless map loads no textures.
+func NewMap(seed int64, config Config, headless bool) *Map {
 	m := &Map{
-		rooms:   []*Room{},
-		dungeon: [helpers.MAP_WIDTH][helpers.MAP_HEIGHT]int{},
+		config:   config,
+		headless: headless,
+		seed:     seed,
+		depth:    1,
+		rooms:    []*Room{},
+		dungeon:  Grid{},
 		Textures: Textures{
 			cornersTexture: make(map[string]rl.Texture2D),
 			wallTextures:   make(map[string]rl.Texture2D),
 		},
 	}
 
+	m.reseed(seed, 0)
 	m.loadTextures()
 	m.generateDungeon()
 
 	return m
 }
 
-// Initialize the dungeon with walls.
+// Initialize the dungeon with walls, sized for the map's floor.
 func (m *Map) initDungeon() {
-	for x := 0; x < helpers.MAP_WIDTH; x++ {
-		for y := 0; y < helpers.MAP_HEIGHT; y++ {
-			m.dungeon[x][y] = 0 // 1 means wall
-		}
-	}
+	m.dungeon = NewGrid(floorSize(m.config, m.depth))
+	m.trapClock = 0
+	m.plates = map[Point]float32{}
+	m.locks = map[Point]Lock{}
+	m.lockKeys = nil
+	m.shop = nil
+}
+
+// floorSize returns the tiles across and down a floor at depth, deeper
+// floors are larger.
+func floorSize(config Config, depth int) (int, int) {
+	growth := helpers.MAP_GROWTH * (depth - 1)
+	return minInt(config.Width+growth, helpers.MAX_MAP_SIZE), minInt(config.Height+growth, helpers.MAX_MAP_SIZE)
+}
+
+// Width returns the tiles across the current layout.
+func (m *Map) Width() int {
+	return m.dungeon.Width
+}
+
+// Height returns the tiles down the current layout.
+func (m *Map) Height() int {
+	return m.dungeon.Height
 }
 
+// FirstRoomPosition returns where the player arrives on the layout, the
+// middle of the first room.
 func (m *Map) FirstRoomPosition() (float32, float32) {
-	// Choose a random room that's not the last room
-	roomIndex := rand.Intn(len(m.rooms) - 1)
-	room := m.rooms[roomIndex]
+	return m.rooms[0].Center()
+}
 
-	// Move the chosen room to the front of the slice
+// pickFirstRoom moves a random room other than the last one, which holds
+// the key, to the front of the rooms. The player arrives there.
+func (m *Map) pickFirstRoom() {
+	roomIndex := m.rng.Intn(len(m.rooms) - 1)
 	m.rooms[0], m.rooms[roomIndex] = m.rooms[roomIndex], m.rooms[0]
-
-	return float32((room.X + room.Width/2) * helpers.TILE_SIZE),
-		float32((room.Y + room.Height/2) * helpers.TILE_SIZE)
 }
 
 func (m *Map) GetRoomsRects() []helpers.Rectangle {
@@ -111,323 +154,81 @@ func (m *Map) GetRooms() *[]*Room {
 	return &m.rooms
 }
 
-func (m *Map) SwitchMap() (float32, float32) {
-	m.initDungeon()
-	m.rooms = []*Room{}
-	m.generateDungeon()
-	return m.FirstRoomPosition()
-}
-
-// Generate the dungeon using BSP.
-func (m *Map) generateDungeon() {
-	m.initDungeon()
-	for len(m.rooms) < 3 {
-		m.rooms = []*Room{}
-		m.bspSplit(helpers.Rectangle{X: 1, Y: 1, Width: helpers.MAP_WIDTH - 2, Height: helpers.MAP_HEIGHT - 2}, 0)
-	}
-
-	for _, room := range m.rooms {
-		m.carveRoom(room.Rectangle)
-	}
-	m.connectRooms()
-}
-
-func (m *Map) GetRoomsBySize(size int) []Room {
-	var result []Room
-	for _, room := range m.rooms {
-		if room.Size == RoomSize(size) {
-			result = append(result, *room)
-		}
-	}
-	return result
-}
-
-// Split the map into rooms using Binary Space Partitioning.
-func (m *Map) bspSplit(area helpers.Rectangle, depth int) {
-	if depth >= helpers.MAX_DEPTH {
-		roomSize := m.chooseRoomSize(depth)
-		roomWidth, roomHeight := m.getRoomDimensions(roomSize)
-
-		if int(area.Width)-int(roomWidth) < 3 || int(area.Height)-int(roomHeight) < 3 {
-			return
-		}
-
-		maxAttempts := 100
-		for attempt := 0; attempt < maxAttempts; attempt++ {
-			roomX := int(area.X) + (int(area.Width)-int(roomWidth))/2 + rand.Intn(3) - 1
-			roomY := int(area.Y) + (int(area.Height)-int(roomHeight))/2 + rand.Intn(3) - 1
-
-			newRoom := Room{
-				Rectangle: helpers.Rectangle{X: int32(roomX), Y: int32(roomY), Width: roomWidth, Height: roomHeight},
-				Size:      roomSize,
-			}
-
-			if m.isValidRoomPlacement(newRoom.Rectangle) {
-				m.rooms = append(m.rooms, &newRoom)
-				return
-			}
-		}
-		return
-	}
-
-	splitRatio := 0.4 + rand.Float64()*0.2
-
-	if rand.Intn(2) == 0 && area.Width > helpers.MIN_ROOM_SIZE*2 {
-		split := int(float64(area.Width) * splitRatio)
-		m.bspSplit(helpers.Rectangle{area.X, area.Y, int32(split), area.Height}, depth+1)
-		m.bspSplit(helpers.Rectangle{area.X + int32(split), area.Y, area.Width - int32(split), area.Height}, depth+1)
-	} else if area.Height > helpers.MIN_ROOM_SIZE*2 {
-		split := int(float64(area.Height) * splitRatio)
-		m.bspSplit(helpers.Rectangle{area.X, area.Y, area.Width, int32(split)}, depth+1)
-		m.bspSplit(helpers.Rectangle{area.X, area.Y + int32(split), area.Width, area.Height - int32(split)}, depth+1)
-	}
+// Seed returns the seed the current layout was generated from.
+func (m *Map) Seed() int64 {
+	return m.seed
 }
 
-func (m *Map) chooseRoomSize(depth int) RoomSize {
-	if depth > 5 {
-		return RoomSize(rand.Intn(3))
-	}
-	if rand.Float32() < 0.6 {
-		return SmallRoom
-	}
-	return MediumRoom
+// Headless reports whether the map and what's on it load no textures.
+func (m *Map) Headless() bool {
+	return m.headless
 }
 
-func (m *Map) getRoomDimensions(size RoomSize) (width, height int32) {
-	switch size {
-	case SmallRoom:
-		width = int32(rand.Intn(4) + 3)  // 3-5
-		height = int32(rand.Intn(4) + 3) // 3-5
-	case MediumRoom:
-		width = int32(rand.Intn(4) + 6)  // 6-8
-		height = int32(rand.Intn(4) + 6) // 6-8
-	case LargeRoom:
-		width = int32(rand.Intn(7) + 9)  // 9-13
-		height = int32(rand.Intn(7) + 9) // 9-13
-	}
-
-	width += 3
-	height += 3
-
-	return
+// Depth returns the floor the map is, 1 being the top one. Deeper floors
+// hold more enemies and rarer items.
+func (m *Map) Depth() int {
+	return m.depth
 }
 
-func (m *Map) isValidRoomPlacement(newRoom helpers.Rectangle) bool {
-	for _, room := range m.rooms {
-		if room.Intersects(newRoom) {
-			return false
-		}
-	}
-	return true
+// SetDepth moves the map to another floor, it takes effect on the next
+// layout generated.
+func (m *Map) SetDepth(depth int) {
+	m.depth = depth
 }
 
-// Carve out a room in the dungeon by setting its tiles to 1.
-func (m *Map) carveRoom(room helpers.Rectangle) {
-	for x := room.X; x < room.X+room.Width; x++ {
-		for y := room.Y; y < room.Y+room.Height; y++ {
-			m.dungeon[x][y] = 1
-		}
-	}
+// Rand returns the map's private random source. Everything spawned on the
+// map (props, collectibles, enemies) draws from it so a seed always yields
+// the same dungeon.
+func (m *Map) Rand() *rand.Rand {
+	return m.rng
 }
 
-// Connect rooms with corridors.
-func (m *Map) connectRooms() {
-	numRooms := len(m.rooms)
-	if numRooms == 0 {
-		return
-	}
-
-	// Create a list of all possible connections
-	type connection struct {
-		room1, room2 int
-		distance     float64
-	}
-
-	var connections []connection
-
-	// Generate all possible connections between rooms
-	for i := 0; i < numRooms; i++ {
-		for j := i + 1; j < numRooms; j++ {
-			dist := distanceBetweenRooms(m.rooms[i], m.rooms[j])
-			connections = append(connections, connection{i, j, dist})
-		}
-	}
-
-	// Sort connections by distance
-	sort.Slice(connections, func(i, j int) bool {
-		return connections[i].distance < connections[j].distance
-	})
-
-	// Union-Find data structure for detecting cycles
-	parent := make([]int, numRooms)
-	for i := range parent {
-		parent[i] = i
-	}
-
-	// Find with path compression
-	var find func(int) int
-	find = func(x int) int {
-		if parent[x] != x {
-			parent[x] = find(parent[x])
-		}
-		return parent[x]
-	}
-
-	// Union by rank
-	union := func(x, y int) {
-		parent[find(x)] = find(y)
-	}
-
-	// Create minimum spanning tree
-	connected := make(map[int]map[int]bool)
-	for i := 0; i < numRooms; i++ {
-		connected[i] = make(map[int]bool)
-	}
-
-	// Connect rooms using Kruskal's algorithm
-	for _, conn := range connections {
-		if find(conn.room1) != find(conn.room2) {
-			union(conn.room1, conn.room2)
-			connected[conn.room1][conn.room2] = true
-			connected[conn.room2][conn.room1] = true
-			m.createCorridor(m.rooms[conn.room1], m.rooms[conn.room2])
-		}
-	}
-
-	// Add a few extra connections for loops (optional)
-	for _, conn := range connections {
-		if !connected[conn.room1][conn.room2] && rand.Float64() < 0.2 { // 20% chance for extra connections
-			m.createCorridor(m.rooms[conn.room1], m.rooms[conn.room2])
-			connected[conn.room1][conn.room2] = true
-			connected[conn.room2][conn.room1] = true
-		}
-	}
-}
-
-func (m *Map) createCorridor(room1, room2 *Room) {
-	// Get room centers
-	start := rl.Vector2{
-		X: float32(room1.X + room1.Width/2),
-		Y: float32(room1.Y + room1.Height/2),
-	}
-	end := rl.Vector2{
-		X: float32(room2.X + room2.Width/2),
-		Y: float32(room2.Y + room2.Height/2),
-	}
-
-	// Create 2-3 control points for more organic paths
-	numPoints := rand.Intn(2) + 2
-	controlPoints := make([]rl.Vector2, numPoints)
-	controlPoints[0] = start
-	controlPoints[numPoints-1] = end
-
-	// Generate intermediate control points
-	for i := 1; i < numPoints-1; i++ {
-		controlPoints[i] = rl.Vector2{
-			X: start.X + (end.X-start.X)*float32(i)/float32(numPoints-1) + float32(rand.Intn(5)-2),
-			Y: start.Y + (end.Y-start.Y)*float32(i)/float32(numPoints-1) + float32(rand.Intn(5)-2),
-		}
-	}
-
-	// Carve paths through all control points
-	for i := 0; i < len(controlPoints)-1; i++ {
-		m.carvePath(controlPoints[i], controlPoints[i+1])
-	}
+func (m *Map) SwitchMap(seed int64) (float32, float32) {
+	m.seed = seed
+	m.reseed(seed, 0)
+	m.initDungeon()
+	m.rooms = []*Room{}
+	m.generateDungeon()
+	return m.FirstRoomPosition()
 }
 
-func (m *Map) carvePath(start, end rl.Vector2) {
-	x := int32(start.X)
-	y := int32(start.Y)
-
-	// Make the corridor wider at the start
-	m.carveArea(x, y, 3)
-
-	for x != int32(end.X) || y != int32(end.Y) {
-		if x < int32(end.X) {
-			x++
-		} else if x > int32(end.X) {
-			x--
-		}
-
-		if y < int32(end.Y) {
-			y++
-		} else if y > int32(end.Y) {
-			y--
-		}
-
-		// Always carve a wider path (minimum width)
-		m.carveArea(x, y, 2)
-
-		// Randomly make even wider corridors at some points
-		if rand.Float32() < 0.3 {
-			m.carveArea(x, y, 3)
+// generateDungeon lays out a new dungeon with the generator picked for
+// it, a layout always has at least 3 rooms all linked together. Doors,
+// water and traps are placed over its floor once it's done, then a few
+// doors are locked from the room the player arrives in and a merchant may
+// set up shop.
+func (m *Map) generateDungeon() {
+	m.generator = pickGenerator(m.config, m.depth, m.rng)
+
+	// Layouts are made connected, the odd one that isn't is made again
+	for attempt := 1; ; attempt++ {
+		m.initDungeon()
+		m.rooms = GENERATORS[m.generator].Generate(&m.dungeon, m.rng)
+		if m.connected() || attempt == LAYOUT_ATTEMPTS {
+			break
 		}
 	}
 
-	// Make the corridor wider at the end
-	m.carveArea(int32(end.X), int32(end.Y), 3)
+	m.pickFirstRoom()
+	placeFeatures(&m.dungeon, m.rooms, m.rng)
+	m.locks, m.lockKeys = placeLocks(&m.dungeon, m.rooms, m.rng)
+	m.shop = placeShop(&m.dungeon, m.rooms, m.rng)
 }
 
-func (m *Map) carveArea(x, y int32, radius int32) {
-	// Cap the radius to a maximum value (e.g., 3)
-	maxRadius := int32(2)
-	if radius > maxRadius {
-		radius = maxRadius
-	}
-
-	// First pass: carve the main area
-	for dx := -radius; dx <= radius; dx++ {
-		for dy := -radius; dy <= radius; dy++ {
-			newX := x + dx
-			newY := y + dy
-			if m.isValidPosition(newX, newY) {
-				m.dungeon[newX][newY] = 1
-			}
-		}
-	}
-
-	// Second pass: smooth out corners to prevent 1-tile gaps
-	for dx := -radius - 1; dx <= radius+1; dx++ {
-		for dy := -radius - 1; dy <= radius+1; dy++ {
-			newX := x + dx
-			newY := y + dy
-			if m.isValidPosition(newX, newY) {
-				// If surrounded by walkable tiles, make this tile walkable too
-				if m.countAdjacentWalkable(newX, newY) >= 5 {
-					m.dungeon[newX][newY] = 1
-				}
-			}
-		}
-	}
+// Generator returns the name of the generator the current layout was made
+// with.
+func (m *Map) Generator() string {
+	return m.generator
 }
 
-func (m *Map) countAdjacentWalkable(x, y int32) int {
-	count := 0
-	for dx := -1; dx <= 1; dx++ {
-		for dy := -1; dy <= 1; dy++ {
-			newX := x + int32(dx)
-			newY := y + int32(dy)
-			if m.isValidPosition(newX, newY) && m.dungeon[newX][newY] == 1 {
-				count++
-			}
+func (m *Map) GetRoomsBySize(size int) []Room {
+	var result []Room
+	for _, room := range m.rooms {
+		if room.Size == RoomSize(size) {
+			result = append(result, *room)
 		}
 	}
-	return count
-}
-
-func (m *Map) isValidPosition(x, y int32) bool {
-	return x > 0 && x < helpers.MAP_WIDTH-1 && y > 0 && y < helpers.MAP_HEIGHT-1
-}
-
-func distanceBetweenRooms(r1, r2 *Room) float64 {
-	c1x := float64(r1.X) + float64(r1.Width)/2
-	c1y := float64(r1.Y) + float64(r1.Height)/2
-	c2x := float64(r2.X) + float64(r2.Width)/2
-	c2y := float64(r2.Y) + float64(r2.Height)/2
-
-	dx := c1x - c2x
-	dy := c1y - c2y
-	return math.Sqrt(dx*dx + dy*dy)
+	return result
 }
 
 func min(a, b int32) int32 {
@@ -445,10 +246,11 @@ func max(a, b int32) int32 {
 }
 
 func (m *Map) Render() {
-	for x := 0; x < helpers.MAP_WIDTH; x++ {
-		for y := 0; y < helpers.MAP_HEIGHT; y++ {
-			if m.dungeon[x][y] == 1 {
+	for x := 0; x < m.dungeon.Width; x++ {
+		for y := 0; y < m.dungeon.Height; y++ {
+			if tile := m.dungeon.At(x, y); tile != TileWall {
 				rl.DrawTexture(m.floorTexture, int32(x*helpers.TILE_SIZE), int32(y*helpers.TILE_SIZE), rl.White)
+				m.renderTile(x, y, tile)
 			} else {
 				if valid, corner := m.isDungeonCorner(x, y); valid {
 					switch corner {
@@ -492,22 +294,22 @@ func (m *Map) Render() {
 
 // Load textures and other resources.
 func (m *Map) loadTextures() {
-	m.floorTexture = rl.LoadTexture("assets/ground/88.png")
+	m.floorTexture = helpers.LoadTexture(m.headless, "assets/ground/88.png")
 
-	m.cornersTexture["BR"] = rl.LoadTexture("assets/walls/6.png")
-	m.cornersTexture["TL"] = rl.LoadTexture("assets/walls/8.png")
-	m.cornersTexture["TR"] = rl.LoadTexture("assets/walls/11.png")
-	m.cornersTexture["BL"] = rl.LoadTexture("assets/walls/3.png")
+	m.cornersTexture["BR"] = helpers.LoadTexture(m.headless, "assets/walls/6.png")
+	m.cornersTexture["TL"] = helpers.LoadTexture(m.headless, "assets/walls/8.png")
+	m.cornersTexture["TR"] = helpers.LoadTexture(m.headless, "assets/walls/11.png")
+	m.cornersTexture["BL"] = helpers.LoadTexture(m.headless, "assets/walls/3.png")
 
-	m.cornersTexture["BRI"] = rl.LoadTexture("assets/walls/16inner.png")
-	m.cornersTexture["TLI"] = rl.LoadTexture("assets/walls/14inner.png")
-	m.cornersTexture["TRI"] = rl.LoadTexture("assets/walls/1inner.png")
-	m.cornersTexture["BLI"] = rl.LoadTexture("assets/walls/9inner.png")
+	m.cornersTexture["BRI"] = helpers.LoadTexture(m.headless, "assets/walls/16inner.png")
+	m.cornersTexture["TLI"] = helpers.LoadTexture(m.headless, "assets/walls/14inner.png")
+	m.cornersTexture["TRI"] = helpers.LoadTexture(m.headless, "assets/walls/1inner.png")
+	m.cornersTexture["BLI"] = helpers.LoadTexture(m.headless, "assets/walls/9inner.png")
 
-	m.wallTextures["B"] = rl.LoadTexture("assets/walls/4.png")
-	m.wallTextures["T"] = rl.LoadTexture("assets/walls/10.png")
-	m.wallTextures["R"] = rl.LoadTexture("assets/walls/12.png")
-	m.wallTextures["L"] = rl.LoadTexture("assets/walls/2.png")
+	m.wallTextures["B"] = helpers.LoadTexture(m.headless, "assets/walls/4.png")
+	m.wallTextures["T"] = helpers.LoadTexture(m.headless, "assets/walls/10.png")
+	m.wallTextures["R"] = helpers.LoadTexture(m.headless, "assets/walls/12.png")
+	m.wallTextures["L"] = helpers.LoadTexture(m.headless, "assets/walls/2.png")
 }
 
 // Unload textures to free up memory.
@@ -523,42 +325,42 @@ func (m *Map) unloadTextures() {
 
 func (m *Map) isDungeonCorner(x, y int) (bool, cornerType) {
 	// Ensure we're checking within valid dungeon bounds
-	if x <= 0 || x >= helpers.MAP_WIDTH || y <= 0 || y >= helpers.MAP_HEIGHT {
+	if x <= 0 || x >= m.dungeon.Width || y <= 0 || y >= m.dungeon.Height {
 		return false, noCorner
 	}
 
 	// Check for corner conditions
 	// Top-left corner
-	if x > 0 && y > 0 && m.dungeon[x-1][y-1] == 1 && m.dungeon[x-1][y] == 0 && m.dungeon[x][y-1] == 0 {
+	if x > 0 && y > 0 && m.open(x-1, y-1) && !m.open(x-1, y) && !m.open(x, y-1) {
 		return true, cornerBR
 	}
 	// Top-right corner
-	if x < helpers.MAP_WIDTH-1 && y > 0 && m.dungeon[x+1][y-1] == 1 && m.dungeon[x+1][y] == 0 && m.dungeon[x][y-1] == 0 {
+	if x < m.dungeon.Width-1 && y > 0 && m.open(x+1, y-1) && !m.open(x+1, y) && !m.open(x, y-1) {
 		return true, cornerBL
 	}
 	// Bottom-left corner
-	if x > 0 && y < helpers.MAP_HEIGHT-1 && m.dungeon[x-1][y+1] == 1 && m.dungeon[x-1][y] == 0 && m.dungeon[x][y+1] == 0 {
+	if x > 0 && y < m.dungeon.Height-1 && m.open(x-1, y+1) && !m.open(x-1, y) && !m.open(x, y+1) {
 		return true, cornerTR
 	}
 	// Bottom-right corner
-	if x < helpers.MAP_WIDTH-1 && y < helpers.MAP_HEIGHT-1 && m.dungeon[x+1][y+1] == 1 && m.dungeon[x+1][y] == 0 && m.dungeon[x][y+1] == 0 {
+	if x < m.dungeon.Width-1 && y < m.dungeon.Height-1 && m.open(x+1, y+1) && !m.open(x+1, y) && !m.open(x, y+1) {
 		return true, cornerTL
 	}
 
 	// or 3 sides are 1s // INNER ONES
-	if x > 0 && y > 0 && m.dungeon[x-1][y-1] == 1 && m.dungeon[x-1][y] == 1 && m.dungeon[x][y-1] == 1 {
+	if x > 0 && y > 0 && m.open(x-1, y-1) && m.open(x-1, y) && m.open(x, y-1) {
 		return true, innerCornerTL
 	}
 
-	if x < helpers.MAP_WIDTH-1 && y > 0 && m.dungeon[x+1][y-1] == 1 && m.dungeon[x+1][y] == 1 && m.dungeon[x][y-1] == 1 {
+	if x < m.dungeon.Width-1 && y > 0 && m.open(x+1, y-1) && m.open(x+1, y) && m.open(x, y-1) {
 		return true, innerCornerTR
 	}
 
-	if x > 0 && y < helpers.MAP_HEIGHT-1 && m.dungeon[x-1][y+1] == 1 && m.dungeon[x-1][y] == 1 && m.dungeon[x][y+1] == 1 {
+	if x > 0 && y < m.dungeon.Height-1 && m.open(x-1, y+1) && m.open(x-1, y) && m.open(x, y+1) {
 		return true, innerCornerBL
 	}
 
-	if x < helpers.MAP_WIDTH-1 && y < helpers.MAP_HEIGHT-1 && m.dungeon[x+1][y+1] == 1 && m.dungeon[x+1][y] == 1 && m.dungeon[x][y+1] == 1 {
+	if x < m.dungeon.Width-1 && y < m.dungeon.Height-1 && m.open(x+1, y+1) && m.open(x+1, y) && m.open(x, y+1) {
 		return true, innerCornerBR
 	}
 
@@ -568,7 +370,7 @@ func (m *Map) isDungeonCorner(x, y int) (bool, cornerType) {
 
 func (m *Map) isDungeonWall(x, y int) (bool, wallDirection) {
 	// Ensure we're checking within valid dungeon bounds
-	if x <= 0 || x >= helpers.MAP_WIDTH-1 || y <= 0 || y >= helpers.MAP_HEIGHT-1 {
+	if x <= 0 || x >= m.dungeon.Width-1 || y <= 0 || y >= m.dungeon.Height-1 {
 		return false, noWall
 	}
 
@@ -576,19 +378,19 @@ func (m *Map) isDungeonWall(x, y int) (bool, wallDirection) {
 
 	// Check for wall conditions
 
-	if x > 0 && m.dungeon[x-1][y] == 1 && m.dungeon[x+1][y] == 0 {
+	if x > 0 && m.open(x-1, y) && !m.open(x+1, y) {
 		return true, wallLeft
 	}
 
-	if x < helpers.MAP_WIDTH-1 && m.dungeon[x+1][y] == 1 && m.dungeon[x-1][y] == 0 {
+	if x < m.dungeon.Width-1 && m.open(x+1, y) && !m.open(x-1, y) {
 		return true, wallRight
 	}
 
-	if y > 0 && m.dungeon[x][y-1] == 1 && m.dungeon[x][y+1] == 0 {
+	if y > 0 && m.open(x, y-1) && !m.open(x, y+1) {
 		return true, wallBottom
 	}
 
-	if y < helpers.MAP_HEIGHT-1 && m.dungeon[x][y+1] == 1 && m.dungeon[x][y-1] == 0 {
+	if y < m.dungeon.Height-1 && m.open(x, y+1) && !m.open(x, y-1) {
 		return true, wallTop
 	}
 
@@ -599,11 +401,16 @@ func (m *Map) isDungeonWall(x, y int) (bool, wallDirection) {
 // IsWalkable checks if a map tile is walkable.
 func (m *Map) IsWalkable(x, y int) bool {
 	// Check boundaries first
-	if x < 0 || x >= helpers.MAP_WIDTH || y < 0 || y >= helpers.MAP_HEIGHT {
+	if x < 0 || x >= m.dungeon.Width || y < 0 || y >= m.dungeon.Height {
 		return false
 	}
-	// Check if the tile is walkable (assuming 0 is not walkable)
-	return m.dungeon[x][y] != 0
+	// Walls and shut doors block the way
+	return m.dungeon.At(x, y).Walkable()
+}
+
+// open reports whether the tile isn't a wall, doors included.
+func (m *Map) open(x, y int) bool {
+	return m.dungeon.At(x, y) != TileWall
 }
 
 // IsWalkable checks if a map tile is walkable.
@@ -612,6 +419,21 @@ func (m *Map) IsWalkableFloat(x, y float32) bool {
 	return m.IsWalkable(tileX, tileY)
 }
 
+// HasLineOfSight reports whether the straight line between two points
+// stays on walkable tiles.
+func (m *Map) HasLineOfSight(from, to rl.Vector2) bool {
+	distance := rl.Vector2Distance(from, to)
+	steps := int(distance/(helpers.TILE_SIZE/4)) + 1
+
+	for i := 0; i <= steps; i++ {
+		point := rl.Vector2Lerp(from, to, float32(i)/float32(steps))
+		if !m.IsWalkableFloat(point.X, point.Y) {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *Map) CurrentRoomIndex(p rl.Vector2) int {
 
 	for i, room := range m.rooms {
@@ -677,7 +499,7 @@ func (mp *Map) GetRandomRoomCenterBySize(size int) (float32, float32) {
 		return 0, 0
 	}
 
-	room := rooms[rand.Intn(len(rooms))]
+	room := rooms[mp.rng.Intn(len(rooms))]
 
 	centerX := room.X*helpers.TILE_SIZE + room.Width*helpers.TILE_SIZE/2
 	centerY := room.Y*helpers.TILE_SIZE + room.Height*helpers.TILE_SIZE/2
@@ -685,17 +507,19 @@ func (mp *Map) GetRandomRoomCenterBySize(size int) (float32, float32) {
 	return float32(centerX), float32(centerY)
 }
 
-func (r *Room) ProperRoomLightning() (scale float32, radius float32) {
+// ProperRoomLightning scales a light prop to the room, large rooms get
+// the prop as defined and smaller ones a dimmer version.
+func (r *Room) ProperRoomLightning(light *defs.PropDef) (scale float32, radius float32) {
+	factor := float32(0)
 	switch r.Size {
 	case SmallRoom:
-		return 0.6, 30 + 10
+		factor = 0.6
 	case MediumRoom:
-		return 0.8, 40 + 10
+		factor = 0.8
 	case LargeRoom:
-		return 1, 50 + 10
+		factor = 1
 	}
-	return 0, 0
-
+	return light.Scale * factor, light.LightRadius * factor
 }
 
 func (m *Map) GetRoomByRect(rect helpers.Rectangle) *Room {
@@ -713,9 +537,9 @@ func (m *Map) GetCorridorTiles() []rl.Vector2 {
 	var corridorTiles []rl.Vector2
 
 	// Check each tile in the map
-	for x := 0; x < helpers.MAP_WIDTH; x++ {
-		for y := 0; y < helpers.MAP_HEIGHT; y++ {
-			if m.dungeon[x][y] == 1 { // If it's a walkable tile
+	for x := 0; x < m.dungeon.Width; x++ {
+		for y := 0; y < m.dungeon.Height; y++ {
+			if m.dungeon.At(x, y) == TileFloor { // If it's a bare floor tile
 				isInRoom := false
 				// Check if this tile is in any room
 				for _, room := range m.rooms {
@@ -10,91 +10,214 @@ import (
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Point is a tile coordinate on the map grid
+type Point struct {
+	X, Y int
+}
+
 // Define the Pathfinder struct which will handle pathfinding
 type Pathfinder struct {
-	grid           [helpers.MAP_WIDTH][helpers.MAP_HEIGHT]int // Copy of the dungeon map
-	open           []Node                                     // List of nodes to be evaluated
-	closed         []Node                                     // List of nodes already evaluated
-	path           []Node                                     // The resulting path
-	rooms          []*Room                                    // List of rooms in the map
-	currentStarPos rl.Vector2                                 // Add this new field
+	grid           Grid       // Dungeon of the map when it was made
+	path           []Point    // Path of the last Update, followed by Render
+	rooms          []*Room    // List of rooms in the map
+	currentStarPos rl.Vector2 // Add this new field
+
+	// Search buffers, reused by every query so FindPath doesn't allocate
+	// anything but the returned path
+	open   nodeHeap
+	costG  []float64
+	parent []int32
+	closed bitset // Cells already expanded
+	seen   bitset // Cells whose costG and parent belong to this query
+}
+
+// bitset marks grid cells by index
+type bitset []uint64
+
+func newBitset(cells int) bitset { return make(bitset, (cells+63)/64) }
+
+func (b bitset) has(i int32) bool { return b[i/64]&(1<<(uint(i)%64)) != 0 }
+func (b bitset) set(i int32)      { b[i/64] |= 1 << (uint(i) % 64) }
+func (b bitset) clear() {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// heapNode is an open cell and its total cost (G + H)
+type heapNode struct {
+	index int32
+	cost  float64
+}
+
+// nodeHeap is a min-heap of open cells. It's typed rather than built on
+// container/heap, whose interface{} values allocate on every push.
+type nodeHeap []heapNode
+
+func (h nodeHeap) Len() int { return len(h) }
+
+func (h *nodeHeap) push(n heapNode) {
+	*h = append(*h, n)
+	q := *h
+	for i := len(q) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if q[parent].cost <= q[i].cost {
+			break
+		}
+		q[i], q[parent] = q[parent], q[i]
+		i = parent
+	}
 }
 
-// Define the Node struct which represents a point in the grid
-type Node struct {
-	x, y   int     // Coordinates of the node
-	costG  float64 // Cost from start to current node
-	costH  float64 // Heuristic cost from current node to end
-	parent *Node   // Pointer to the parent node (for path reconstruction)
+func (h *nodeHeap) pop() heapNode {
+	q := *h
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q = q[:last]
+	for i := 0; ; {
+		smallest, left, right := i, 2*i+1, 2*i+2
+		if left < len(q) && q[left].cost < q[smallest].cost {
+			smallest = left
+		}
+		if right < len(q) && q[right].cost < q[smallest].cost {
+			smallest = right
+		}
+		if smallest == i {
+			break
+		}
+		q[i], q[smallest] = q[smallest], q[i]
+		i = smallest
+	}
+	*h = q
+	return top
 }
 
-// Heuristic function to estimate the cost from the current node to the target node
+// Octile distance, the exact cost between two cells on an open 8-way grid
 func heuristic(x1, y1, x2, y2 int) float64 {
-	return math.Abs(float64(x2-x1)) + math.Abs(float64(y2-y1))
+	dx := math.Abs(float64(x2 - x1))
+	dy := math.Abs(float64(y2 - y1))
+	return dx + dy + (math.Sqrt2-2)*math.Min(dx, dy)
 }
 
-// Returns the total cost (G + H) for the node
-func (n Node) TotalCost() float64 {
-	return n.costG + n.costH
+func (pf *Pathfinder) cellIndex(x, y int) int32 {
+	return int32(x*pf.grid.Height + y)
 }
 
-// Initialize Pathfinder with the map grid
+func (pf *Pathfinder) cellPoint(i int32) Point {
+	return Point{X: int(i) / pf.grid.Height, Y: int(i) % pf.grid.Height}
+}
+
+// Initialize Pathfinder with the map grid, its buffers are sized for the
+// current layout.
 func NewPathfinder(m *Map) *Pathfinder {
+	cells := m.dungeon.Width * m.dungeon.Height
 	return &Pathfinder{
 		grid:           m.dungeon,
 		rooms:          m.rooms,
 		currentStarPos: rl.Vector2{X: 0, Y: 0},
+		costG:          make([]float64, cells),
+		parent:         make([]int32, cells),
+		closed:         newBitset(cells),
+		seen:           newBitset(cells),
 	}
 }
 
-// Update method runs the A* algorithm to find a path from (x1, y1) to (x2, y2)
+// Update finds a path from (x1, y1) to (x2, y2) and keeps it for Render and
+// CreateSmoothPath. The path is empty when there is none.
 func (pf *Pathfinder) Update(x1, y1, x2, y2 int) {
+	pf.path, _ = pf.FindPath(Point{X: x1, Y: y1}, Point{X: x2, Y: y2})
+}
 
-	// Reset pathfinding data
-	pf.open = make([]Node, 0)
-	pf.closed = make([]Node, 0)
-	pf.path = make([]Node, 0)
+// FindPath runs A* between two tiles and returns the tiles of the path,
+// both ends included. Moves go in 8 directions but diagonals never cut a
+// wall corner. It reports false when either end is a wall or no path
+// exists. A Pathfinder only runs one search at a time.
+func (pf *Pathfinder) FindPath(from, to Point) ([]Point, bool) {
+	if !pf.walkable(from.X, from.Y) || !pf.walkable(to.X, to.Y) {
+		return nil, false
+	}
 
-	startNode := Node{x: x1, y: y1, costG: 0, costH: heuristic(x1, y1, x2, y2)}
-	pf.open = append(pf.open, startNode)
+	pf.open = pf.open[:0]
+	pf.closed.clear()
+	pf.seen.clear()
 
-	for len(pf.open) > 0 {
-		// Find node in open list with the lowest cost
-		currentIndex := pf.getLowestCostIndex()
-		currentNode := pf.open[currentIndex]
+	start, goal := pf.cellIndex(from.X, from.Y), pf.cellIndex(to.X, to.Y)
+	pf.costG[start] = 0
+	pf.parent[start] = -1
+	pf.seen.set(start)
+	pf.open.push(heapNode{index: start, cost: heuristic(from.X, from.Y, to.X, to.Y)})
 
-		// Check if we have reached the goal
-		if currentNode.x == x2 && currentNode.y == y2 {
-			pf.path = pf.reconstructPath(currentNode)
-			return
+	for pf.open.Len() > 0 {
+		current := pf.open.pop().index
+		if pf.closed.has(current) {
+			continue // Stale entry, the cell was reached more cheaply already
 		}
+		if current == goal {
+			return pf.reconstructPath(goal), true
+		}
+		pf.closed.set(current)
 
-		// Remove current node from open and add it to closed
-		pf.open = append(pf.open[:currentIndex], pf.open[currentIndex+1:]...)
-		pf.closed = append(pf.closed, currentNode)
+		p := pf.cellPoint(current)
+		for _, dir := range directions {
+			x, y := p.X+dir[0], p.Y+dir[1]
+			if !pf.walkable(x, y) {
+				continue
+			}
 
-		// Explore neighbors
-		for _, neighbor := range pf.getNeighbors(currentNode) {
-			if pf.isInClosedList(neighbor) || pf.grid[neighbor.x][neighbor.y] == 0 {
-				continue // Skip if neighbor is in closed list or not walkable
+			stepCost := 1.0
+			if dir[0] != 0 && dir[1] != 0 {
+				// No squeezing diagonally past a wall corner
+				if !pf.walkable(p.X+dir[0], p.Y) || !pf.walkable(p.X, p.Y+dir[1]) {
+					continue
+				}
+				stepCost = math.Sqrt2
 			}
+			stepCost *= pf.grid.At(x, y).Cost()
 
-			// Calculate tentative G cost
-			tentativeG := currentNode.costG + 1
-
-			// If the neighbor is not in the open list, add it with its G cost
-			if !pf.isInOpenList(neighbor) {
-				neighbor.costG = tentativeG
-				neighbor.costH = heuristic(neighbor.x, neighbor.y, x2, y2)
-				neighbor.parent = &currentNode
-				pf.open = append(pf.open, neighbor)
-			} else if tentativeG < neighbor.costG {
-				// If we found a shorter path to the neighbor, update its cost and parent
-				neighbor.costG = tentativeG
-				neighbor.parent = &currentNode
+			neighbor := pf.cellIndex(x, y)
+			if pf.closed.has(neighbor) {
+				continue
 			}
+
+			tentativeG := pf.costG[current] + stepCost
+			if pf.seen.has(neighbor) && tentativeG >= pf.costG[neighbor] {
+				continue
+			}
+
+			pf.seen.set(neighbor)
+			pf.costG[neighbor] = tentativeG
+			pf.parent[neighbor] = current
+			pf.open.push(heapNode{index: neighbor, cost: tentativeG + heuristic(x, y, to.X, to.Y)})
 		}
 	}
+
+	return nil, false
+}
+
+// Up, Down, Left, Right and the four diagonals
+var directions = [8][2]int{
+	{0, -1}, {0, 1}, {-1, 0}, {1, 0},
+	{-1, -1}, {1, -1}, {-1, 1}, {1, 1},
+}
+
+func (pf *Pathfinder) walkable(x, y int) bool {
+	return pf.grid.At(x, y).Passable()
+}
+
+// Reconstruct the path by backtracking from the goal cell
+func (pf *Pathfinder) reconstructPath(goal int32) []Point {
+	length := 0
+	for i := goal; i != -1; i = pf.parent[i] {
+		length++
+	}
+
+	path := make([]Point, length)
+	for i := goal; i != -1; i = pf.parent[i] {
+		length--
+		path[length] = pf.cellPoint(i)
+	}
+	return path
 }
 
 func (pf *Pathfinder) Render(currentRoomIndex int) {
@@ -104,8 +227,8 @@ func (pf *Pathfinder) Render(currentRoomIndex int) {
 
 	// Get current player position (first node in path)
 	playerPos := rl.Vector2{
-		X: float32(pf.path[0].x*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
-		Y: float32(pf.path[0].y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+		X: float32(pf.path[0].X*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+		Y: float32(pf.path[0].Y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
 	}
 
 	// Get next waypoint (3-4 tiles ahead)
@@ -115,8 +238,8 @@ func (pf *Pathfinder) Render(currentRoomIndex int) {
 	}
 
 	nextPos := rl.Vector2{
-		X: float32(nextNode.x*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
-		Y: float32(nextNode.y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+		X: float32(nextNode.X*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+		Y: float32(nextNode.Y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
 	}
 
 	// Calculate target position for the star (farther from player)
@@ -139,12 +262,12 @@ func (pf *Pathfinder) getNextWaypoint() rl.Vector2 {
 	// Get the next point in the path that's at least 3 tiles away
 	currentIndex := 0
 	for i := 1; i < len(pf.path); i++ {
-		dist := heuristic(pf.path[currentIndex].x, pf.path[currentIndex].y,
-			pf.path[i].x, pf.path[i].y)
+		dist := heuristic(pf.path[currentIndex].X, pf.path[currentIndex].Y,
+			pf.path[i].X, pf.path[i].Y)
 		if dist >= 3 {
 			return rl.Vector2{
-				X: float32(pf.path[i].x*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
-				Y: float32(pf.path[i].y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+				X: float32(pf.path[i].X*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+				Y: float32(pf.path[i].Y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
 			}
 		}
 	}
@@ -152,8 +275,8 @@ func (pf *Pathfinder) getNextWaypoint() rl.Vector2 {
 	// If no point is far enough, return the last point
 	lastNode := pf.path[len(pf.path)-1]
 	return rl.Vector2{
-		X: float32(lastNode.x*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
-		Y: float32(lastNode.y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+		X: float32(lastNode.X*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
+		Y: float32(lastNode.Y*helpers.TILE_SIZE + helpers.TILE_SIZE/2),
 	}
 }
 
@@ -206,68 +329,12 @@ func minInt(a, b int) int {
 	return b
 }
 
-// Get the index of the node with the lowest total cost (G + H) in the open list
-func (pf *Pathfinder) getLowestCostIndex() int {
-	lowestIndex := 0
-	lowestCost := pf.open[0].TotalCost()
-	for i, node := range pf.open {
-		if node.TotalCost() < lowestCost {
-			lowestIndex = i
-			lowestCost = node.TotalCost()
-		}
-	}
-	return lowestIndex
-}
-
-// Reconstruct the path by backtracking from the goal node
-func (pf *Pathfinder) reconstructPath(goalNode Node) []Node {
-	var path []Node
-	for node := &goalNode; node != nil; node = node.parent {
-		path = append([]Node{*node}, path...)
-	}
-	return path
-}
-
-// Get valid neighbors of the current node (up, down, left, right)
-func (pf *Pathfinder) getNeighbors(node Node) []Node {
-	var neighbors []Node
-	directions := [][2]int{
-		{0, -1}, {0, 1}, {-1, 0}, {1, 0}, // Up, Down, Left, Right
-	}
-	for _, dir := range directions {
-		x, y := node.x+dir[0], node.y+dir[1]
-		if x >= 0 && x < helpers.MAP_WIDTH && y >= 0 && y < helpers.MAP_HEIGHT {
-			neighbors = append(neighbors, Node{x: x, y: y})
-		}
-	}
-	return neighbors
-}
-
-// Check if a node is in the closed list
-func (pf *Pathfinder) isInClosedList(node Node) bool {
-	for _, n := range pf.closed {
-		if n.x == node.x && n.y == node.y {
-			return true
-		}
-	}
-	return false
-}
-
-// Check if a node is in the open list
-func (pf *Pathfinder) isInOpenList(node Node) bool {
-	for _, n := range pf.open {
-		if n.x == node.x && n.y == node.y {
-			return true
-		}
-	}
-	return false
-}
 func (pf *Pathfinder) CreateSmoothPath() []rl.Vector2 {
 	smoothPath := make([]rl.Vector2, 0)
 
 	for i, node := range pf.path {
-		baseX := float32(node.x*helpers.TILE_SIZE + helpers.TILE_SIZE/2)
-		baseY := float32(node.y*helpers.TILE_SIZE + helpers.TILE_SIZE/2)
+		baseX := float32(node.X*helpers.TILE_SIZE + helpers.TILE_SIZE/2)
+		baseY := float32(node.Y*helpers.TILE_SIZE + helpers.TILE_SIZE/2)
 
 		// Add some randomness to the point position
 		jitterX := (rand.Float32() - 0.5) * float32(helpers.TILE_SIZE) * 0.5
@@ -0,0 +1,155 @@
+package world
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain runs the tests from the root of the repository, where the
+// definitions and their assets are.
+func TestMain(m *testing.M) {
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// gridMap returns a map laid out from rows of text, '#' for walls and '.'
+// for floor.
+func gridMap(rows ...string) *Map {
+	grid := NewGrid(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, c := range row {
+			if c == '.' {
+				grid.Set(x, y, TileFloor)
+			}
+		}
+	}
+	return &Map{dungeon: grid}
+}
+
+func TestFindPathNoCornerCutting(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []string
+		from, to Point
+		length   int // Tiles of the path, both ends included
+	}{
+		{
+			name:   "open diagonal",
+			rows:   []string{"...", "...", "..."},
+			from:   Point{0, 0},
+			to:     Point{2, 2},
+			length: 3,
+		},
+		{
+			name:   "wall on one side of the corner",
+			rows:   []string{".#.", "...", "..."},
+			from:   Point{0, 0},
+			to:     Point{1, 1},
+			length: 3,
+		},
+		{
+			name:   "walls on both sides of the corner",
+			rows:   []string{".#", "#."},
+			from:   Point{0, 0},
+			to:     Point{1, 1},
+			length: 0,
+		},
+		{
+			name:   "around a pillar",
+			rows:   []string{"...", ".#.", "..."},
+			from:   Point{0, 1},
+			to:     Point{2, 1},
+			length: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pf := NewPathfinder(gridMap(tt.rows...))
+			path, ok := pf.FindPath(tt.from, tt.to)
+			if ok != (tt.length > 0) || len(path) != tt.length {
+				t.Fatalf("got %v (found %v), want %d tiles", path, ok, tt.length)
+			}
+
+			for i := 1; i < len(path); i++ {
+				a, b := path[i-1], path[i]
+				if a.X != b.X && a.Y != b.Y && (!pf.walkable(b.X, a.Y) || !pf.walkable(a.X, b.Y)) {
+					t.Errorf("step %v -> %v cuts a wall corner", a, b)
+				}
+			}
+		})
+	}
+}
+
+func TestFindPathUnreachable(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []string
+		from, to Point
+	}{
+		{"walled off room", []string{"..#..", "..#..", "..#.."}, Point{0, 1}, Point{4, 1}},
+		{"target is a wall", []string{"...", ".#.", "..."}, Point{0, 0}, Point{1, 1}},
+		{"start is a wall", []string{"#..", "...", "..."}, Point{0, 0}, Point{2, 2}},
+		{"target off the grid", []string{"...", "...", "..."}, Point{0, 0}, Point{5, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pf := NewPathfinder(gridMap(tt.rows...))
+			if path, ok := pf.FindPath(tt.from, tt.to); ok || path != nil {
+				t.Errorf("found %v to an unreachable tile", path)
+			}
+		})
+	}
+}
+
+// BenchmarkFindPath times the longest path from the room the player arrives
+// in to another room it reaches, on layouts of every generator.
+func BenchmarkFindPath(b *testing.B) {
+	for _, generator := range GENERATOR_ORDER {
+		for _, seed := range []int64{1, 42, 1234} {
+			m := NewMap(seed, Config{Generator: generator}, true)
+			pf := NewPathfinder(m)
+			from, to := farthestRoom(pf, m)
+
+			b.Run(fmt.Sprintf("%s/%d", generator, seed), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					pf.FindPath(from, to)
+				}
+			})
+		}
+	}
+}
+
+// farthestRoom returns a floor tile of the room the player arrives in and
+// of the room at the end of the longest path from it. Locked doors may
+// keep some rooms out of reach.
+func farthestRoom(pf *Pathfinder, m *Map) (Point, Point) {
+	from := roomTile(pf, m.rooms[0])
+	to, longest := from, 0
+	for _, room := range m.rooms[1:] {
+		tile := roomTile(pf, room)
+		if path, ok := pf.FindPath(from, tile); ok && len(path) > longest {
+			to, longest = tile, len(path)
+		}
+	}
+	return from, to
+}
+
+// roomTile returns the passable tile of a room nearest its top left
+// corner.
+func roomTile(pf *Pathfinder, room *Room) Point {
+	for x := int(room.X); x < int(room.X+room.Width); x++ {
+		for y := int(room.Y); y < int(room.Y+room.Height); y++ {
+			if pf.walkable(x, y) {
+				return Point{X: x, Y: y}
+			}
+		}
+	}
+	return Point{X: int(room.X), Y: int(room.Y)}
+}
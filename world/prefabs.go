@@ -0,0 +1,100 @@
+package world
+
+import "math/rand"
+
+const (
+	PREFAB_ROOMS    = 10  // Rooms the stitcher tries to place
+	PREFAB_ATTEMPTS = 300 // Placements tried before settling for fewer rooms
+)
+
+// PREFABS are the hand-drawn rooms the prefab generator stitches together,
+// '.' is floor and anything else is wall. Their middle tile must be floor,
+// corridors start from there.
+var PREFABS = [][]string{
+	{ // Pillared hall
+		"..........",
+		"..........",
+		"..#....#..",
+		"..........",
+		"..........",
+		"..#....#..",
+		"..........",
+		"..........",
+	},
+	{ // Crossroads
+		"###.....###",
+		"###.....###",
+		"###.....###",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"###.....###",
+		"###.....###",
+		"###.....###",
+	},
+	{ // Rotunda
+		"##.....##",
+		"#.......#",
+		".........",
+		".........",
+		".........",
+		".........",
+		".........",
+		"#.......#",
+		"##.....##",
+	},
+	{ // Crypt with an altar
+		".........",
+		".........",
+		"...###...",
+		".........",
+		".........",
+		".........",
+		".........",
+	},
+	{ // Cell
+		"......",
+		"......",
+		"......",
+		"......",
+		"......",
+		"......",
+	},
+}
+
+// PrefabGenerator stitches hand-drawn rooms from PREFABS together with
+// Kruskal corridors.
+type PrefabGenerator struct{}
+
+func (PrefabGenerator) Generate(grid *Grid, rng *rand.Rand) []*Room {
+	var rooms []*Room
+	for attempt := 0; len(rooms) < PREFAB_ROOMS && (attempt < PREFAB_ATTEMPTS || len(rooms) < 3); attempt++ {
+		prefab := PREFABS[rng.Intn(len(PREFABS))]
+		width, height := int32(len(prefab[0])), int32(len(prefab))
+
+		rect := randomRect(grid, rng, width, height)
+		if overlapsAny(rooms, rect, 3) {
+			continue
+		}
+
+		stampPrefab(grid, prefab, rect.X, rect.Y)
+		rooms = append(rooms, &Room{Rectangle: rect, Size: roomSizeFor(width, height)})
+	}
+
+	connectRooms(grid, rooms, rng)
+	return rooms
+}
+
+// stampPrefab opens the floor tiles of prefab with its top left corner at
+// (x, y).
+func stampPrefab(grid *Grid, prefab []string, x, y int32) {
+	for dy, row := range prefab {
+		for dx, tile := range row {
+			if tile == '.' {
+				grid.Set(int(x)+dx, int(y)+dy, 1)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package world
 
 import (
+	"crydes/defs"
 	"crydes/helpers"
 	"math"
 	"math/rand"
@@ -47,6 +48,8 @@ func (pm *PropsManager) SetUpProps() {
 	pm.setupRoomProps()
 	// Then set up corridor props
 	pm.setupCorridorProps()
+	// Stairs last, lights don't have to make room for them
+	pm.setupStairs()
 }
 
 func (pm *PropsManager) setupRoomProps() {
@@ -54,7 +57,8 @@ func (pm *PropsManager) setupRoomProps() {
 
 	for _, room := range *pm.rooms {
 		lightPos := room.GetLightPositions()
-		scale, radius := room.ProperRoomLightning()
+		fireplace := defs.Get().Props["fireplace"]
+		scale, radius := room.ProperRoomLightning(fireplace)
 
 		// Filter positions that are too close to existing props
 		var validPositions []rl.Vector2
@@ -68,18 +72,13 @@ func (pm *PropsManager) setupRoomProps() {
 		for _, pos := range validPositions {
 			pm.props = append(pm.props, NewProp(
 				1,
-				"fire",
+				fireplace.Name,
 				pos.X,
 				pos.Y,
 				scale,
 				radius,
-				rl.NewVector2(16, 16),
-				helpers.LoadAnimation(
-					"assets/fireplace/1.png",
-					"assets/fireplace/2.png",
-					"assets/fireplace/3.png",
-					"assets/fireplace/4.png",
-				),
+				rl.NewVector2(fireplace.Size[0], fireplace.Size[1]),
+				fireplace.Animation.Load(fireplace.Name, pm.Map.Headless()),
 				true,
 			))
 		}
@@ -94,28 +93,26 @@ func (pm *PropsManager) setupCorridorProps() {
 	)
 
 	// Shuffle corridor tiles for random placement
-	rand.Shuffle(len(corridorTiles), func(i, j int) {
+	rng := pm.Map.Rand()
+	rng.Shuffle(len(corridorTiles), func(i, j int) {
 		corridorTiles[i], corridorTiles[j] = corridorTiles[j], corridorTiles[i]
 	})
 
+	torch := defs.Get().Props["torch"]
+
 	for _, pos := range corridorTiles {
-		if rand.Float32() < 0.4 { // 40% chance to try spawning
+		if rng.Float32() < 0.4 { // 40% chance to try spawning
 			if pm.isPositionValid(pos.X, pos.Y, minDistance) {
-				// Create a smaller light source for corridors
+				// Corridors get smaller torches
 				pm.props = append(pm.props, NewProp(
 					1,
-					"fire",
+					torch.Name,
 					pos.X,
 					pos.Y,
-					0.5, // Smaller scale
-					20,  // Smaller light radius
-					rl.NewVector2(16, 16),
-					helpers.LoadAnimation(
-						"assets/fireplace/1.png",
-						"assets/fireplace/2.png",
-						"assets/fireplace/3.png",
-						"assets/fireplace/4.png",
-					),
+					torch.Scale,
+					torch.LightRadius,
+					rl.NewVector2(torch.Size[0], torch.Size[1]),
+					torch.Animation.Load(torch.Name, pm.Map.Headless()),
 					true,
 				))
 			}
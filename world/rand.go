@@ -0,0 +1,39 @@
+package world
+
+import "math/rand"
+
+// countedSource is a random source that counts its draws, so a map
+// restored from a save can pick its stream up where it was.
+type countedSource struct {
+	src   rand.Source64
+	draws int
+}
+
+func newCountedSource(seed int64) *countedSource {
+	return &countedSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (c *countedSource) Int63() int64 {
+	c.draws++
+	return c.src.Int63()
+}
+
+func (c *countedSource) Uint64() uint64 {
+	c.draws++
+	return c.src.Uint64()
+}
+
+func (c *countedSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.draws = 0
+}
+
+// reseed restarts the map's random source from seed, skipping its first
+// draws values.
+func (m *Map) reseed(seed int64, draws int) {
+	m.source = newCountedSource(seed)
+	for i := 0; i < draws; i++ {
+		m.source.Uint64()
+	}
+	m.rng = rand.New(m.source)
+}
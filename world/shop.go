@@ -0,0 +1,123 @@
+package world
+
+import (
+	"crydes/defs"
+	"crydes/helpers"
+	"fmt"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	MERCHANT_ODDS = 0.35 // Odds a layout has a merchant room
+	SHOP_WARES    = 3    // Wares a merchant lays out
+	WARE_SPACING  = 2    // Tiles between two wares
+	WARE_RADIUS   = 10   // How close the player must walk to buy a ware
+)
+
+// Shop is a merchant room, its wares laid out in a row across the middle.
+type Shop struct {
+	Room  int // Index of the merchant room
+	Wares []*Ware
+}
+
+// Ware is something on sale in a shop.
+type Ware struct {
+	Item     ItemType
+	Price    int
+	Stock    int // Left on sale, the ware is gone once it reaches 0
+	Position rl.Vector2
+
+	icon *helpers.Animation // Loaded the first time the ware is drawn
+}
+
+// placeShop turns a room the player can reach without any key into a
+// merchant room now and then, stocked with wares picked by weight. It
+// returns nil when the layout gets no merchant.
+func placeShop(grid *Grid, rooms []*Room, rng *rand.Rand) *Shop {
+	if len(rooms) < 3 || rng.Float32() >= MERCHANT_ODDS {
+		return nil
+	}
+
+	// Neither the first room nor the last, which hold stairs
+	start := rooms[0]
+	reach := grid.reachable(start.X+start.Width/2, start.Y+start.Height/2)
+	var candidates []int
+	for i := 1; i < len(rooms)-1; i++ {
+		if roomReached(&reach, rooms[i]) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	shop := &Shop{Room: candidates[rng.Intn(len(candidates))]}
+	cx, cy := rooms[shop.Room].Center()
+	picked := map[string]bool{}
+	for i := 0; i < SHOP_WARES; i++ {
+		def := defs.Get().RandomWare(rng)
+		if def == nil || picked[def.Name] {
+			continue
+		}
+		picked[def.Name] = true
+		shop.Wares = append(shop.Wares, &Ware{
+			Item:  ItemType(def.Item),
+			Price: def.Price,
+			Stock: def.Stock,
+		})
+	}
+
+	for i, ware := range shop.Wares {
+		offset := float32(i*2-len(shop.Wares)+1) / 2 * WARE_SPACING * helpers.TILE_SIZE
+		ware.Position = rl.NewVector2(cx+offset, cy)
+	}
+	return shop
+}
+
+// Shop returns the merchant room of the layout, nil when it has none.
+func (m *Map) Shop() *Shop {
+	return m.shop
+}
+
+// WareAt returns the ware on sale the given position stands on, nil if
+// none.
+func (s *Shop) WareAt(pos rl.Vector2) *Ware {
+	if s == nil {
+		return nil
+	}
+	for _, ware := range s.Wares {
+		if ware.Stock > 0 && helpers.Distance(ware.Position, pos) <= WARE_RADIUS {
+			return ware
+		}
+	}
+	return nil
+}
+
+// Render draws the wares still on sale on a rug, with their price.
+func (s *Shop) Render() {
+	if s == nil {
+		return
+	}
+
+	for _, ware := range s.Wares {
+		if ware.Stock <= 0 {
+			continue
+		}
+		x, y := int32(ware.Position.X), int32(ware.Position.Y)
+		const size = helpers.TILE_SIZE
+		rl.DrawRectangle(x-size/2, y-size/2, size*2, size*2, rl.ColorAlpha(rl.Maroon, 0.6))
+		rl.DrawRectangleLines(x-size/2, y-size/2, size*2, size*2, rl.Gold)
+
+		if ware.icon == nil {
+			ware.icon = LoadItemAnimation(ware.Item, false) // Only rendered with a window
+		}
+		if ware.icon != nil && len(ware.icon.Frames) > 0 {
+			frame := ware.icon.Frames[0]
+			scale := float32(size) / float32(frame.Width)
+			rl.DrawTextureEx(frame, rl.NewVector2(float32(x), float32(y)), 0, scale, rl.White)
+		}
+		rl.DrawText(fmt.Sprint(ware.Price), x+2, y+size+2, 8, rl.Gold)
+	}
+}
@@ -0,0 +1,268 @@
+package world
+
+import (
+	"crydes/defs"
+	"crydes/helpers"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// MapState is the serializable form of a generated dungeon.
+type MapState struct {
+	Seed      int64       `json:"seed"`
+	Depth     int         `json:"depth"`
+	Generator string      `json:"generator"`
+	Dungeon   [][]Tile    `json:"dungeon"` // Indexed [x][y]
+	Rooms     []RoomState `json:"rooms"`
+
+	TrapClock float32      `json:"trap_clock"`
+	Plates    []PlateState `json:"plates,omitempty"` // Plates still holding spikes up
+	Locks     []LockState  `json:"locks,omitempty"`  // Doors still locked
+	Shop      *ShopState   `json:"shop,omitempty"`   // Merchant room, if any
+
+	Draws int         `json:"draws,omitempty"` // Values drawn from the map's random source
+	Props []PropState `json:"props,omitempty"` // Saves without them place the props again
+}
+
+// PropState is the serializable form of a prop, rebuilt from the prop
+// definition of its type.
+type PropState struct {
+	ID     int     `json:"id"`
+	Type   string  `json:"type"`
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Scale  float32 `json:"scale"`
+	Radius float32 `json:"radius"`
+}
+
+// ShopState is the serializable form of a merchant room.
+type ShopState struct {
+	Room  int         `json:"room"`
+	Wares []WareState `json:"wares"`
+}
+
+// WareState is the serializable form of a ware, sold out ones included.
+type WareState struct {
+	Item  ItemType `json:"item"`
+	Price int      `json:"price"`
+	Stock int      `json:"stock"`
+	X     float32  `json:"x"`
+	Y     float32  `json:"y"`
+}
+
+// LockState is the serializable form of a locked door.
+type LockState struct {
+	X    int  `json:"x"`
+	Y    int  `json:"y"`
+	Lock Lock `json:"lock"`
+}
+
+// PlateState is the serializable form of a pressed plate.
+type PlateState struct {
+	X     int     `json:"x"`
+	Y     int     `json:"y"`
+	Timer float32 `json:"timer"`
+}
+
+// RoomState is the serializable form of a Room.
+type RoomState struct {
+	X      int32    `json:"x"`
+	Y      int32    `json:"y"`
+	Width  int32    `json:"width"`
+	Height int32    `json:"height"`
+	Size   RoomSize `json:"size"`
+}
+
+// ItemState is the serializable form of an uncollected item.
+type ItemState struct {
+	ID   int      `json:"id"`
+	Type ItemType `json:"type"`
+	X    float32  `json:"x"`
+	Y    float32  `json:"y"`
+}
+
+// Snapshot captures the dungeon grid and rooms.
+func (m *Map) Snapshot() MapState {
+	state := MapState{
+		Seed:      m.seed,
+		Depth:     m.depth,
+		Generator: m.generator,
+		Dungeon:   make([][]Tile, m.dungeon.Width),
+		Rooms:     make([]RoomState, len(m.rooms)),
+		Draws:     m.source.draws,
+	}
+
+	for x := range state.Dungeon {
+		state.Dungeon[x] = make([]Tile, m.dungeon.Height)
+		for y := range state.Dungeon[x] {
+			state.Dungeon[x][y] = m.dungeon.At(x, y)
+		}
+	}
+
+	for i, room := range m.rooms {
+		state.Rooms[i] = RoomState{
+			X:      room.X,
+			Y:      room.Y,
+			Width:  room.Width,
+			Height: room.Height,
+			Size:   room.Size,
+		}
+	}
+
+	state.TrapClock = m.trapClock
+	for tile, timer := range m.plates {
+		state.Plates = append(state.Plates, PlateState{X: tile.X, Y: tile.Y, Timer: timer})
+	}
+	sort.Slice(state.Plates, func(i, j int) bool {
+		a, b := state.Plates[i], state.Plates[j]
+		return a.X < b.X || (a.X == b.X && a.Y < b.Y)
+	})
+	state.Locks = lockStates(m.locks)
+
+	if m.shop != nil {
+		state.Shop = &ShopState{Room: m.shop.Room}
+		for _, ware := range m.shop.Wares {
+			state.Shop.Wares = append(state.Shop.Wares, WareState{
+				Item:  ware.Item,
+				Price: ware.Price,
+				Stock: ware.Stock,
+				X:     ware.Position.X,
+				Y:     ware.Position.Y,
+			})
+		}
+	}
+
+	return state
+}
+
+// Restore replaces the dungeon with a saved one. The random source is
+// reseeded from the saved seed and skips what was already drawn, so it
+// goes on as if the run never stopped.
+func (m *Map) Restore(state MapState) {
+	m.seed = state.Seed
+	m.depth = state.Depth
+	m.generator = state.Generator
+	m.reseed(state.Seed, state.Draws)
+
+	height := 0
+	if len(state.Dungeon) > 0 {
+		height = len(state.Dungeon[0])
+	}
+	m.dungeon = NewGrid(len(state.Dungeon), height)
+	for x := range state.Dungeon {
+		for y := 0; y < height && y < len(state.Dungeon[x]); y++ {
+			m.dungeon.Set(x, y, state.Dungeon[x][y])
+		}
+	}
+
+	m.rooms = make([]*Room, len(state.Rooms))
+	for i, room := range state.Rooms {
+		m.rooms[i] = &Room{
+			Rectangle: helpers.Rectangle{X: room.X, Y: room.Y, Width: room.Width, Height: room.Height},
+			Size:      room.Size,
+		}
+	}
+
+	m.trapClock = state.TrapClock
+	m.plates = map[Point]float32{}
+	for _, plate := range state.Plates {
+		m.plates[Point{X: plate.X, Y: plate.Y}] = plate.Timer
+	}
+
+	// The keys were scattered long ago, the items hold them now
+	m.locks = map[Point]Lock{}
+	m.lockKeys = nil
+	for _, lock := range state.Locks {
+		m.locks[Point{X: lock.X, Y: lock.Y}] = lock.Lock
+	}
+
+	m.shop = nil
+	if state.Shop != nil {
+		m.shop = &Shop{Room: state.Shop.Room}
+		for _, ware := range state.Shop.Wares {
+			m.shop.Wares = append(m.shop.Wares, &Ware{
+				Item:     ware.Item,
+				Price:    ware.Price,
+				Stock:    ware.Stock,
+				Position: rl.NewVector2(ware.X, ware.Y),
+			})
+		}
+	}
+}
+
+// Snapshot captures the dungeon and the props placed in it.
+func (w *World) Snapshot() MapState {
+	state := w.Map.Snapshot()
+	for _, prop := range w.PropsManager.props {
+		state.Props = append(state.Props, PropState{
+			ID:     prop.ID,
+			Type:   prop.Type,
+			X:      prop.Position.X,
+			Y:      prop.Position.Y,
+			Scale:  prop.Scale,
+			Radius: prop.LTRadius,
+		})
+	}
+	return state
+}
+
+// Restore loads a saved dungeon and rebuilds everything derived from it.
+// The props are put back where they were.
+func (w *World) Restore(state MapState) {
+	w.Map.Restore(state)
+
+	w.Pathfinder = NewPathfinder(w.Map)
+
+	w.PropsManager = newPropsManager(w.Map.GetRooms(), w.Map)
+	if state.Props == nil {
+		w.PropsManager.SetUpProps()
+		return
+	}
+	for _, saved := range state.Props {
+		def, exists := defs.Get().Props[saved.Type]
+		if !exists {
+			continue
+		}
+		w.PropsManager.props = append(w.PropsManager.props, NewProp(
+			saved.ID,
+			def.Name,
+			saved.X,
+			saved.Y,
+			saved.Scale,
+			saved.Radius,
+			rl.NewVector2(def.Size[0], def.Size[1]),
+			def.Animation.Load(def.Name, w.Map.Headless()),
+			true,
+		))
+	}
+}
+
+// Snapshot captures every item that hasn't been collected yet.
+func (cm *CollectibleManager) Snapshot() []ItemState {
+	var items []ItemState
+	for id, item := range cm.items {
+		if item.Collected {
+			continue
+		}
+		items = append(items, ItemState{
+			ID:   id,
+			Type: item.ItemType,
+			X:    item.Position.X,
+			Y:    item.Position.Y,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// Restore replaces the items with saved ones.
+func (cm *CollectibleManager) Restore(items []ItemState) {
+	cm.items = make(map[int]*CollectibleItem)
+	for _, item := range items {
+		cm.AddItem(item.ID, item.Type, item.X, item.Y)
+	}
+}
@@ -0,0 +1,79 @@
+package world
+
+import (
+	"crydes/defs"
+	"crydes/helpers"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Stairs are props named after the definition they are drawn from.
+const (
+	STAIRS_DOWN = "stairs_down"
+	STAIRS_UP   = "stairs_up"
+
+	STAIRS_RADIUS = 10 // How close the player must walk to take the stairs
+)
+
+// setupStairs places stairs down in the middle of the last room, the one
+// holding the key, and below the first floor stairs up in the middle of
+// the first room where the player arrives. A boss arena has no way out
+// but through the boss.
+func (pm *PropsManager) setupStairs() {
+	rooms := *pm.rooms
+	if len(rooms) == 0 || pm.Map.IsArena() {
+		return
+	}
+
+	pm.addStairs(STAIRS_DOWN, rooms[len(rooms)-1])
+	if pm.Map.Depth() > 1 {
+		pm.addStairs(STAIRS_UP, rooms[0])
+	}
+}
+
+func (pm *PropsManager) addStairs(kind string, room *Room) {
+	def := defs.Get().Props[kind]
+	x, y := room.Center()
+	pm.props = append(pm.props, NewProp(
+		1,
+		def.Name,
+		x,
+		y,
+		def.Scale,
+		def.LightRadius,
+		rl.NewVector2(def.Size[0], def.Size[1]),
+		def.Animation.Load(def.Name, pm.Map.Headless()),
+		true,
+	))
+}
+
+// Center returns the position of the middle tile of the room, where the
+// player spawns.
+func (r *Room) Center() (float32, float32) {
+	return float32((r.X + r.Width/2) * helpers.TILE_SIZE),
+		float32((r.Y + r.Height/2) * helpers.TILE_SIZE)
+}
+
+// Stairs returns the stairs of the given kind on the current floor, nil if
+// it has none.
+func (w *World) Stairs(kind string) *Prop {
+	for _, prop := range w.PropsManager.props {
+		if prop.Type == kind {
+			return prop
+		}
+	}
+	return nil
+}
+
+// StairsAt returns the stairs the given position stands on, nil if none.
+func (w *World) StairsAt(pos rl.Vector2) *Prop {
+	for _, prop := range w.PropsManager.props {
+		if prop.Type != STAIRS_DOWN && prop.Type != STAIRS_UP {
+			continue
+		}
+		if helpers.Distance(prop.Position, pos) <= STAIRS_RADIUS {
+			return prop
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package world
+
+import (
+	"crydes/helpers"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Tile is what covers a square of the map. Generators only lay out walls
+// and floor, the rest is placed over the floor once the layout is done.
+type Tile int
+
+const (
+	TileWall Tile = iota
+	TileFloor
+	TileDoorOpen
+	TileDoorClosed // Opens when the player walks up to it
+	TileDoorLocked // Opens when the player walks up to it with its key
+	TileWater      // Slows down whoever wades through it
+	TilePit        // Hurts and throws the player back where they came from
+	TileSpikes     // Hurts while its spikes are up
+	TilePlate      // Raises the spikes around it when stepped on
+)
+
+const (
+	WATER_SPEED = 0.5 // Speed multiplier while wading
+
+	PIT_DAMAGE    = 1
+	SPIKES_DAMAGE = 1
+
+	SPIKES_PERIOD  = 3.0 // Seconds between two rises of a spike trap
+	SPIKES_UP_TIME = 1.0 // Seconds the spikes stay up on every rise
+
+	PLATE_RADIUS  = 4   // Tiles around a pressure plate whose spikes it raises
+	PLATE_UP_TIME = 2.0 // Seconds a pressed plate keeps them up
+)
+
+// Walkable reports whether something can stand on the tile, doors must be
+// opened first.
+func (t Tile) Walkable() bool {
+	return t != TileWall && t != TileDoorClosed && t != TileDoorLocked
+}
+
+// Passable reports whether the player can get through the tile, opening
+// doors on the way but never crossing a pit.
+func (t Tile) Passable() bool {
+	return t != TileWall && t != TileDoorLocked && t != TilePit
+}
+
+// Hazard reports whether standing on the tile can hurt.
+func (t Tile) Hazard() bool {
+	return t == TilePit || t == TileSpikes
+}
+
+// Cost is what walking onto the tile costs the pathfinder, it only
+// crosses tiles that are Passable.
+func (t Tile) Cost() float64 {
+	switch t {
+	case TileDoorClosed:
+		return 2
+	case TileWater:
+		return 1 / WATER_SPEED
+	case TileSpikes:
+		return 4
+	case TilePlate:
+		return 1.5
+	}
+	return 1
+}
+
+// Color is how the tile shows on the minimap.
+func (t Tile) Color() rl.Color {
+	switch t {
+	case TileDoorOpen, TileDoorClosed:
+		return rl.Brown
+	case TileDoorLocked:
+		return rl.Gold
+	case TileWater:
+		return rl.SkyBlue
+	case TilePit:
+		return rl.DarkGray
+	case TileSpikes, TilePlate:
+		return rl.Maroon
+	}
+	return rl.Gray
+}
+
+// TileAt returns the tile at (x, y), walls outside the map.
+func (m *Map) TileAt(x, y int) Tile {
+	return m.dungeon.At(x, y)
+}
+
+// SpeedAt returns the speed multiplier of the tile under pos.
+func (m *Map) SpeedAt(pos rl.Vector2) float32 {
+	if m.TileAt(tileOf(pos)) == TileWater {
+		return WATER_SPEED
+	}
+	return 1
+}
+
+// OpenDoor opens the closed door at (x, y), it reports false when there is
+// none.
+func (m *Map) OpenDoor(x, y int) bool {
+	if m.dungeon.At(x, y) != TileDoorClosed {
+		return false
+	}
+	m.dungeon.Set(x, y, TileDoorOpen)
+	return true
+}
+
+// UpdateTraps runs the spike traps' clock and the pressed plates' timers.
+func (m *Map) UpdateTraps(deltaTime float32) {
+	m.trapClock += deltaTime
+	for tile, timer := range m.plates {
+		if timer -= deltaTime; timer <= 0 {
+			delete(m.plates, tile)
+		} else {
+			m.plates[tile] = timer
+		}
+	}
+}
+
+// PressPlate raises the spikes around the plate at (x, y), it reports false
+// when there is no plate there.
+func (m *Map) PressPlate(x, y int) bool {
+	if m.dungeon.At(x, y) != TilePlate {
+		return false
+	}
+	m.plates[Point{X: x, Y: y}] = PLATE_UP_TIME
+	return true
+}
+
+// SpikesUp reports whether the spike trap at (x, y) is up. Traps rise in
+// turn so a room of them can be crossed with good timing, and all at once
+// around a pressed plate.
+func (m *Map) SpikesUp(x, y int) bool {
+	if m.dungeon.At(x, y) != TileSpikes {
+		return false
+	}
+
+	for plate := range m.plates {
+		if absInt(plate.X-x) <= PLATE_RADIUS && absInt(plate.Y-y) <= PLATE_RADIUS {
+			return true
+		}
+	}
+
+	phase := float32((x*7+y*3)%10) / 10 * SPIKES_PERIOD
+	clock := m.trapClock + phase
+	return clock-float32(int(clock/SPIKES_PERIOD))*SPIKES_PERIOD < SPIKES_UP_TIME
+}
+
+// renderTile draws what covers a floor tile.
+func (m *Map) renderTile(x, y int, tile Tile) {
+	px, py := int32(x*helpers.TILE_SIZE), int32(y*helpers.TILE_SIZE)
+	const size = helpers.TILE_SIZE
+
+	switch tile {
+	case TileDoorOpen:
+		rl.DrawRectangle(px, py, 3, size, rl.Brown)
+		rl.DrawRectangle(px+size-3, py, 3, size, rl.Brown)
+	case TileDoorClosed, TileDoorLocked:
+		rl.DrawRectangle(px+1, py, size-2, size, rl.Brown)
+		rl.DrawRectangleLines(px+1, py, size-2, size, rl.DarkBrown)
+		if tile == TileDoorLocked {
+			rl.DrawCircle(px+size/2, py+size/2, 2, m.locks[Point{X: x, Y: y}].Color())
+		}
+	case TileWater:
+		rl.DrawRectangle(px, py, size, size, rl.ColorAlpha(rl.SkyBlue, 0.5))
+	case TilePit:
+		rl.DrawRectangle(px, py, size, size, rl.Black)
+		rl.DrawRectangleLines(px, py, size, size, rl.DarkGray)
+	case TileSpikes:
+		if m.SpikesUp(x, y) {
+			for i := int32(0); i < 3; i++ {
+				base := px + 2 + i*4
+				rl.DrawTriangle(
+					rl.NewVector2(float32(base+2), float32(py+3)),
+					rl.NewVector2(float32(base), float32(py+size-3)),
+					rl.NewVector2(float32(base+4), float32(py+size-3)),
+					rl.LightGray,
+				)
+			}
+		} else {
+			for i := int32(0); i < 3; i++ {
+				rl.DrawCircle(px+4+i*4, py+size/2, 1, rl.DarkGray)
+			}
+		}
+	case TilePlate:
+		rl.DrawRectangle(px+3, py+3, size-6, size-6, rl.ColorAlpha(rl.DarkGray, 0.8))
+		rl.DrawRectangleLines(px+3, py+3, size-6, size-6, rl.Gray)
+	}
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package world
+
+import (
+	"crydes/helpers"
+	"math/rand"
+)
+
+const (
+	TUNNEL_STEPS      = 3000 // Steps the walker takes
+	TUNNEL_TURN_ODDS  = 0.25 // Odds the walker changes direction on a step
+	TUNNEL_ROOM_EVERY = 150  // Steps between two tries at opening a room
+)
+
+// TunnelGenerator digs winding tunnels with a drunkard's walk, opening a
+// room where the walker stops every now and then. Everything lies on the
+// walker's path so the whole layout is connected.
+type TunnelGenerator struct{}
+
+var tunnelDirections = [][2]int32{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+func (TunnelGenerator) Generate(grid *Grid, rng *rand.Rand) []*Room {
+	for {
+		rooms := digTunnels(grid, rng)
+		if len(rooms) >= 3 {
+			return rooms
+		}
+		*grid = NewGrid(grid.Width, grid.Height)
+	}
+}
+
+func digTunnels(grid *Grid, rng *rand.Rand) []*Room {
+	var rooms []*Room
+	x, y := int32(grid.Width/2), int32(grid.Height/2)
+	maxX, maxY := int32(grid.Width-4), int32(grid.Height-4)
+	dir := tunnelDirections[rng.Intn(len(tunnelDirections))]
+
+	for step := 0; step < TUNNEL_STEPS; step++ {
+		if rng.Float32() < TUNNEL_TURN_ODDS {
+			dir = tunnelDirections[rng.Intn(len(tunnelDirections))]
+		}
+
+		// Bounce off the outer wall
+		if x+dir[0] < 3 || x+dir[0] > maxX || y+dir[1] < 3 || y+dir[1] > maxY {
+			dir = [2]int32{-dir[0], -dir[1]}
+		}
+		x, y = x+dir[0], y+dir[1]
+		grid.carveArea(x, y, 1)
+
+		if step%TUNNEL_ROOM_EVERY != 0 {
+			continue
+		}
+
+		width, height := int32(5+rng.Intn(6)), int32(5+rng.Intn(6))
+		rect := helpers.Rectangle{X: x - width/2, Y: y - height/2, Width: width, Height: height}
+		inside := rect.X >= 2 && rect.Y >= 2 && rect.X+width <= maxX+2 && rect.Y+height <= maxY+2
+		if inside && !overlapsAny(rooms, rect, 2) {
+			grid.carveRoom(rect)
+			rooms = append(rooms, &Room{Rectangle: rect, Size: roomSizeFor(width, height)})
+		}
+	}
+
+	return rooms
+}
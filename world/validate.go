@@ -0,0 +1,165 @@
+package world
+
+import (
+	"crydes/helpers"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// LAYOUT_ATTEMPTS is how many layouts a floor is generated with before
+// settling for one with rooms cut off from the others.
+const LAYOUT_ATTEMPTS = 10
+
+// Metrics sum up the shape of a layout as seen from where the player
+// stands.
+type Metrics struct {
+	Rooms            int // Rooms in the layout
+	UnreachableRooms int // Rooms the player can't walk into
+	Walkable         int // Tiles that aren't walls
+	Reachable        int // Tiles the player can get to
+	CorridorTiles    int // Walkable tiles outside every room
+	DeadEnds         int // Walkable tiles with a single walkable neighbour
+	Loops            int // Walls the player can walk all the way around
+}
+
+// Reach floods the layout from the tile under pos, the returned grid marks
+// every tile that can be walked to from there once the locked doors are
+// open. Whether their keys can be found is up to Unlockable.
+func (m *Map) Reach(pos rl.Vector2) Grid {
+	x, y := tileOf(pos)
+	return m.dungeon.unlocked(int32(x), int32(y))
+}
+
+// Measure sums up the layout, reach being what the player can walk to.
+func (m *Map) Measure(reach *Grid) Metrics {
+	metrics := Metrics{Rooms: len(m.rooms)}
+	for _, room := range m.rooms {
+		if !roomReached(reach, room) {
+			metrics.UnreachableRooms++
+		}
+	}
+
+	// The walkable tiles form a planar graph whose faces are 2x2 blocks of
+	// floor or walls it runs around, Euler's formula counts the latter
+	edges, squares := 0, 0
+	for x := 0; x < m.dungeon.Width; x++ {
+		for y := 0; y < m.dungeon.Height; y++ {
+			if !m.open(x, y) {
+				continue
+			}
+			metrics.Walkable++
+			if reach.At(x, y) == 1 {
+				metrics.Reachable++
+			}
+			if m.inRoom(x, y) == nil {
+				metrics.CorridorTiles++
+			}
+
+			neighbours := 0
+			for _, dir := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				if m.open(x+dir[0], y+dir[1]) {
+					neighbours++
+				}
+			}
+			if neighbours == 1 {
+				metrics.DeadEnds++
+			}
+
+			right, down := m.open(x+1, y), m.open(x, y+1)
+			if right {
+				edges++
+			}
+			if down {
+				edges++
+			}
+			if right && down && m.open(x+1, y+1) {
+				squares++
+			}
+		}
+	}
+	metrics.Loops = edges - metrics.Walkable + m.components() - squares
+
+	return metrics
+}
+
+// Relocate returns pos when the player can reach its tile, or the nearest
+// tile they can reach otherwise. It reports false when nothing is reached.
+func (m *Map) Relocate(reach *Grid, pos rl.Vector2) (rl.Vector2, bool) {
+	x, y := tileOf(pos)
+	if reach.At(x, y) == 1 {
+		return pos, true
+	}
+
+	// Breadth-first through walls and all, so the nearest tile wins
+	x = maxInt(0, minInt(x, reach.Width-1))
+	y = maxInt(0, minInt(y, reach.Height-1))
+	seen := NewGrid(reach.Width, reach.Height)
+	seen.Set(x, y, 1)
+	queue := []Point{{X: x, Y: y}}
+	for head := 0; head < len(queue); head++ {
+		tile := queue[head]
+		if reach.At(tile.X, tile.Y) == 1 {
+			return rl.NewVector2(float32(tile.X*helpers.TILE_SIZE), float32(tile.Y*helpers.TILE_SIZE)), true
+		}
+		for _, dir := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := tile.X+dir[0], tile.Y+dir[1]
+			if reach.InBounds(nx, ny) && seen.At(nx, ny) == 0 {
+				seen.Set(nx, ny, 1)
+				queue = append(queue, Point{X: nx, Y: ny})
+			}
+		}
+	}
+	return pos, false
+}
+
+// connected reports whether every room can be walked to from the first.
+func (m *Map) connected() bool {
+	return roomsConnected(&m.dungeon, m.rooms)
+}
+
+// components counts the separate walkable areas of the layout.
+func (m *Map) components() int {
+	seen := NewGrid(m.dungeon.Width, m.dungeon.Height)
+	count := 0
+	for x := 0; x < m.dungeon.Width; x++ {
+		for y := 0; y < m.dungeon.Height; y++ {
+			if !m.open(x, y) || seen.At(x, y) == 1 {
+				continue
+			}
+			count++
+			area := m.dungeon.flood(int32(x), int32(y), func(t Tile) bool { return t != TileWall })
+			for i, tile := range area.tiles {
+				if tile == 1 {
+					seen.tiles[i] = 1
+				}
+			}
+		}
+	}
+	return count
+}
+
+// inRoom returns the room holding the tile, nil for corridors.
+func (m *Map) inRoom(x, y int) *Room {
+	for _, room := range m.rooms {
+		if inside(room, x, y) {
+			return room
+		}
+	}
+	return nil
+}
+
+// roomReached reports whether any tile of the room is reached.
+func roomReached(reach *Grid, room *Room) bool {
+	for x := room.X; x < room.X+room.Width; x++ {
+		for y := room.Y; y < room.Y+room.Height; y++ {
+			if reach.At(int(x), int(y)) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func tileOf(pos rl.Vector2) (int, int) {
+	return int(pos.X) / helpers.TILE_SIZE, int(pos.Y) / helpers.TILE_SIZE
+}
@@ -8,9 +8,10 @@ type World struct {
 	Pathfinder *Pathfinder
 }
 
-// NewWorld creates a new world instance
-func NewWorld() *World {
-	mp := NewMap()
+// NewWorld creates a new world instance generated from the given seed and
+// laid out as the config says. A headless world loads no textures.
+func NewWorld(seed int64, config Config, headless bool) *World {
+	mp := NewMap(seed, config, headless)
 	wrld := &World{
 		Map:          mp,
 		Pathfinder:   NewPathfinder(mp),
@@ -26,8 +27,9 @@ func (w *World) PlayerSpawn() (float32, float32) {
 	return w.Map.FirstRoomPosition()
 }
 
-func (w *World) SwitchMap() (float32, float32) {
-	x, y := w.Map.SwitchMap()
+// SwitchMap regenerates the dungeon from a new seed
+func (w *World) SwitchMap(seed int64) (float32, float32) {
+	x, y := w.Map.SwitchMap(seed)
 
 	// Reset pathfinder
 	w.Pathfinder = NewPathfinder(w.Map)
@@ -46,5 +48,6 @@ func (w *World) SwitchMap() (float32, float32) {
 // Render draws the world elements on the screen
 func (w *World) Render() {
 	w.Map.Render()
+	w.Map.Shop().Render()
 	w.PropsManager.Render()
 }